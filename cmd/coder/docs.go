@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/internal/project"
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+)
+
+const docsUsage = `Usage: coder docs <command> [flags]
+
+Commands:
+  check    report broken links, orphan documents and cycles
+  graph    print the graph of documents as text, Graphviz DOT or JSON
+  order    print the reading order of documents starting from the index
+`
+
+var errDocsCheck = errors.New("documentation has problems")
+
+func runDocs(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, docsUsage)
+
+		return fmt.Errorf("%w: docs command is required", errUsage)
+	}
+
+	switch args[0] {
+	case "check":
+		return runDocsCheck(ctx, args[1:])
+	case "graph":
+		return runDocsGraph(ctx, args[1:])
+	case "order":
+		return runDocsOrder(ctx, args[1:])
+	default:
+		fmt.Fprint(os.Stderr, docsUsage)
+
+		return fmt.Errorf("%w: unknown docs command %q", errUsage, args[0])
+	}
+}
+
+func runDocsCheck(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("docs check", flag.ContinueOnError)
+	flags := newDocsFlags(fset, "fetch and check links to remote documents")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	cfg := flags.config()
+	index := docsIndex(cfg)
+
+	docs, err := buildDocsGraph(ctx, cfg, *flags.dir, *flags.remote)
+	if err != nil {
+		return err
+	}
+
+	problems := 0
+
+	for _, broken := range doctree.BrokenLinks(docs) {
+		if !*flags.remote && !isLocal(broken.Link.URL) {
+			continue
+		}
+
+		fmt.Fprintln(os.Stdout, broken)
+
+		problems++
+	}
+
+	for _, orphan := range doctree.Orphans(docs, index) {
+		fmt.Fprintf(os.Stdout, "%s: not reachable from %s\n", orphan, index)
+
+		problems++
+	}
+
+	// Cycles are common in documentation, so they are reported but not counted as problems.
+	for _, cycle := range doctree.Cycles(docs) {
+		fmt.Fprintf(os.Stdout, "cycle: %v\n", cycle)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("%w: %d found", errDocsCheck, problems)
+	}
+
+	return nil
+}
+
+func runDocsGraph(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("docs graph", flag.ContinueOnError)
+	flags := newDocsFlags(fset, "fetch remote documents")
+	format := fset.String("format", "text", "output format: text, dot or json")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	docs, err := buildDocsGraph(ctx, flags.config(), *flags.dir, *flags.remote)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		fmt.Fprint(os.Stdout, doctree.FormatGraph(docs))
+	case "dot":
+		fmt.Fprint(os.Stdout, doctree.FormatDOT(docs))
+	case "json":
+		b, err := doctree.FormatJSON(docs)
+		if err != nil {
+			return fmt.Errorf("format graph: %w", err)
+		}
+
+		fmt.Fprintln(os.Stdout, string(b))
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+
+	return nil
+}
+
+func runDocsOrder(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("docs order", flag.ContinueOnError)
+	flags := newDocsFlags(fset, "fetch remote documents")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	docs, err := buildDocsGraph(ctx, flags.config(), *flags.dir, *flags.remote)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range doctree.ReadingOrder(docs, docsIndex(flags.config())) {
+		fmt.Fprintln(os.Stdout, url)
+	}
+
+	return nil
+}
+
+// docsFlags are the flags shared by the docs commands.
+type docsFlags struct {
+	root   *string
+	index  *string
+	dir    *string
+	remote *bool
+}
+
+func newDocsFlags(fset *flag.FlagSet, remoteUsage string) docsFlags {
+	return docsFlags{
+		root:   fset.String("root", ".", "project root directory"),
+		index:  fset.String("index", "README.md", "index document relative to the root"),
+		dir:    fset.String("dir", "docs", "documentation directory relative to the root, its documents must be reachable"),
+		remote: fset.Bool("remote", false, remoteUsage),
+	}
+}
+
+func (f docsFlags) config() project.Config {
+	return project.Config{
+		RootDir:       *f.root,
+		DocsIndexFile: *f.index,
+	}
+}
+
+// docsIndex returns the path of the index document of the project.
+func docsIndex(cfg project.Config) string {
+	return filepath.Join(cfg.RootDir, cfg.DocsIndexFile)
+}
+
+// buildDocsGraph builds the graph from the index document and every document of the documentation directory,
+// so documents not reachable from the index are in the graph too. Remote documents are fetched only if asked.
+func buildDocsGraph(
+	ctx context.Context, cfg project.Config, docsDir string, remote bool,
+) (map[string]*doctree.Document, error) {
+	var opts []doctree.Option
+
+	if !remote {
+		// No host is allowed, so remote links are never followed.
+		opts = append(opts, doctree.WithAllowedHosts(""))
+	}
+
+	files, err := listDocs(filepath.Join(cfg.RootDir, docsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := doctree.BuildGraph(ctx, append([]string{docsIndex(cfg)}, files...), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("build documents graph: %w", err)
+	}
+
+	return docs, nil
+}
+
+// listDocs returns files of the supported formats under the directory. Hidden directories are skipped.
+// A missing directory has no documents.
+func listDocs(dir string) ([]string, error) {
+	loaders := doctree.DefaultLoaders()
+
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir():
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+		case slices.ContainsFunc(loaders, func(l doctree.Loader) bool { return l.Match(path) }):
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("list documents: %w", err)
+	}
+
+	return files, nil
+}
+
+func isLocal(link string) bool {
+	u, err := url.Parse(link)
+
+	return err != nil || (u.Scheme != "http" && u.Scheme != "https")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/WinPooh32/go-coder/internal/agent/architector"
+	"github.com/WinPooh32/go-coder/pkg/llm/ollama"
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+	"gopkg.in/yaml.v3"
+)
+
+// builtinVariant names the variant of the builtin prompts.
+const builtinVariant = "builtin"
+
+func runEval(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("eval", flag.ContinueOnError)
+	casesFile := fset.String("cases", "", "YAML file with evaluation cases")
+	variants := fset.String("variants", builtinVariant,
+		"comma-separated prompt directories overriding the builtin prompts, \"builtin\" for none")
+	models := fset.String("models", "", "comma-separated models")
+	serverURL := fset.String("ollama", "http://localhost:11434", "ollama server url")
+	verbose := fset.Bool("v", false, "print failures of every run")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	if *casesFile == "" || *models == "" {
+		return fmt.Errorf("%w: -cases and -models are required", errUsage)
+	}
+
+	cases, err := readEvalCases(*casesFile)
+	if err != nil {
+		return err
+	}
+
+	evalVariants, err := evalVariants(strings.Split(*variants, ","))
+	if err != nil {
+		return err
+	}
+
+	evalModels, err := evalModels(*serverURL, strings.Split(*models, ","))
+	if err != nil {
+		return err
+	}
+
+	runs, err := architector.Evaluate(ctx, evalVariants, evalModels, cases)
+	if err != nil {
+		return fmt.Errorf("evaluate: %w", err)
+	}
+
+	if *verbose {
+		for _, run := range runs {
+			for _, failure := range run.Failures {
+				fmt.Fprintf(os.Stdout, "%s %s %s: %s\n", run.Variant, run.Model, run.Case, failure)
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd // Padding between columns.
+
+	fmt.Fprintln(w, "VARIANT\tMODEL\tPASSED\tRATE\tLATENCY\tTOKENS")
+
+	for _, s := range architector.Summarize(runs) {
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%.0f%%\t%s\t%d\n",
+			s.Variant, s.Model, s.Passed, s.Runs, s.PassRate()*100, s.Latency, s.Usage.Total()) //nolint:mnd // Percents.
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+func readEvalCases(name string) ([]architector.EvalCase, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("read evaluation cases: %w", err)
+	}
+
+	var cases []architector.EvalCase
+
+	if err := yaml.Unmarshal(b, &cases); err != nil {
+		return nil, fmt.Errorf("unmarshal evaluation cases: %w", err)
+	}
+
+	return cases, nil
+}
+
+// evalVariants layers every prompt directory over the builtin prompts.
+func evalVariants(dirs []string) ([]architector.EvalVariant, error) {
+	builtin, err := architector.Prompts()
+	if err != nil {
+		return nil, fmt.Errorf("builtin prompts: %w", err)
+	}
+
+	variants := make([]architector.EvalVariant, 0, len(dirs))
+
+	for _, dir := range dirs {
+		var fsys fs.FS = builtin
+
+		if dir != builtinVariant {
+			if _, err := os.Stat(dir); err != nil {
+				return nil, fmt.Errorf("prompt variant: %w", err)
+			}
+
+			fsys = prompt.NewLayeredFS(
+				prompt.Layer{Name: prompt.BuiltinLayer, Root: "", FS: builtin},
+				prompt.Layer{Name: dir, Root: dir, FS: os.DirFS(dir)},
+			)
+		}
+
+		variants = append(variants, architector.EvalVariant{Name: dir, Prompts: fsys})
+	}
+
+	return variants, nil
+}
+
+// evalModels makes ollama generators restricted to the analyze_task schema.
+func evalModels(serverURL string, names []string) ([]architector.EvalModel, error) {
+	envelope, err := architector.AnalyzeTaskFormat()
+	if err != nil {
+		return nil, fmt.Errorf("analyze task format: %w", err)
+	}
+
+	format, err := structured.ParseFormat(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("parse analyze task format: %w", err)
+	}
+
+	models := make([]architector.EvalModel, 0, len(names))
+
+	for _, name := range names {
+		gen, err := ollama.NewGenerator(serverURL, name, ollama.WithFormat(format.JSONSchema.Schema))
+		if err != nil {
+			return nil, fmt.Errorf("make generator of %q: %w", name, err)
+		}
+
+		models = append(models, architector.EvalModel{Name: name, Generator: gen})
+	}
+
+	return models, nil
+}
@@ -1,4 +1,56 @@
 package main
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+const usage = `Usage: coder <command> [arguments]
+
+Commands:
+  tasks    manage tasks of the task tracker
+  docs     check and explore the project documentation
+  prompts  inspect the prompt templates and their overrides
+  eval     evaluate prompt variants and models on fixture tasks
+  map      print the outline of the Go module for LLM context
+`
+
+var errUsage = errors.New("invalid usage")
+
 func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+
+		fmt.Fprintf(os.Stderr, "coder: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if len(args) == 0 {
+		return fmt.Errorf("%w: command is required", errUsage)
+	}
+
+	switch args[0] {
+	case "tasks":
+		return runTasks(ctx, args[1:])
+	case "docs":
+		return runDocs(ctx, args[1:])
+	case "prompts":
+		return runPrompts(args[1:])
+	case "eval":
+		return runEval(ctx, args[1:])
+	case "map":
+		return runMap(ctx, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/WinPooh32/go-coder/pkg/code/golang"
+	"github.com/WinPooh32/go-coder/pkg/code/repomap"
+	"github.com/WinPooh32/go-coder/pkg/llm/ollama"
+)
+
+func runMap(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("map", flag.ContinueOnError)
+	dir := fset.String("dir", ".", "root directory of the Go module")
+	tokens := fset.Int("tokens", 0, "size of the map in tokens, 2048 if not positive")
+	query := fset.String("query", "", "task to rank the files by, requires -embed")
+	embedModel := fset.String("embed", "", "embedding model ranking files by similarity to the query")
+	serverURL := fset.String("ollama", "http://localhost:11434", "ollama server url")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	opts := []repomap.Option{repomap.WithTokens(*tokens)}
+
+	if *embedModel != "" {
+		embedder, err := ollama.NewEmbedder(*serverURL, *embedModel)
+		if err != nil {
+			return fmt.Errorf("make embedder: %w", err)
+		}
+
+		opts = append(opts, repomap.WithEmbedder(embedder))
+	}
+
+	module, err := golang.Load(ctx, *dir)
+	if err != nil {
+		return fmt.Errorf("load module: %w", err)
+	}
+
+	m, err := repomap.New(module, opts...)
+	if err != nil {
+		return fmt.Errorf("collect repository map: %w", err)
+	}
+
+	outline, err := m.Render(ctx, *query)
+	if err != nil {
+		return fmt.Errorf("render repository map: %w", err)
+	}
+
+	fmt.Fprint(os.Stdout, outline)
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/WinPooh32/go-coder/internal/agent/architector"
+	"github.com/WinPooh32/go-coder/pkg/code/diff"
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+)
+
+const promptsUsage = `Usage: coder prompts <command> [flags] [name]
+
+Commands:
+  list    list the active prompt templates and the layers they come from
+  show    print the active prompt template
+  diff    print the difference between the builtin and the active prompt template
+`
+
+const templateExt = ".tpl"
+
+var errPromptNotFound = errors.New("prompt template not found")
+
+func runPrompts(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, promptsUsage)
+
+		return fmt.Errorf("%w: prompts command is required", errUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return runPromptsList(args[1:])
+	case "show":
+		return runPromptsShow(args[1:])
+	case "diff":
+		return runPromptsDiff(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, promptsUsage)
+
+		return fmt.Errorf("%w: unknown prompts command %q", errUsage, args[0])
+	}
+}
+
+func runPromptsList(args []string) error {
+	fset := flag.NewFlagSet("prompts list", flag.ContinueOnError)
+	dir := fset.String("dir", ".", "project root directory")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	layers, err := promptLayers(*dir)
+	if err != nil {
+		return err
+	}
+
+	files, err := promptFiles(layers)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd // Padding between columns.
+
+	for _, file := range files {
+		origins := layers.Origins(file)
+		active := origins[0]
+
+		fmt.Fprintf(w, "%s\t%s\t%s", strings.TrimSuffix(file, templateExt), active.Name, location(active, file))
+
+		if len(origins) > 1 {
+			fmt.Fprintf(w, "\toverrides %s", origins[1].Name)
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+func runPromptsShow(args []string) error {
+	fset := flag.NewFlagSet("prompts show", flag.ContinueOnError)
+	dir := fset.String("dir", ".", "project root directory")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	file, err := promptFile(fset)
+	if err != nil {
+		return err
+	}
+
+	layers, err := promptLayers(*dir)
+	if err != nil {
+		return err
+	}
+
+	b, err := layers.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", errPromptNotFound, fset.Arg(0))
+	}
+
+	if err != nil {
+		return fmt.Errorf("read prompt template: %w", err)
+	}
+
+	fmt.Fprint(os.Stdout, string(b))
+
+	return nil
+}
+
+func runPromptsDiff(args []string) error {
+	fset := flag.NewFlagSet("prompts diff", flag.ContinueOnError)
+	dir := fset.String("dir", ".", "project root directory")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	file, err := promptFile(fset)
+	if err != nil {
+		return err
+	}
+
+	layers, err := promptLayers(*dir)
+	if err != nil {
+		return err
+	}
+
+	origins := layers.Origins(file)
+	if len(origins) == 0 {
+		return fmt.Errorf("%w: %s", errPromptNotFound, fset.Arg(0))
+	}
+
+	active, builtin := origins[0], origins[len(origins)-1]
+
+	from, oldText := "/dev/null", ""
+
+	if builtin.Name == prompt.BuiltinLayer {
+		b, err := fs.ReadFile(builtin.FS, file)
+		if err != nil {
+			return fmt.Errorf("read builtin prompt template: %w", err)
+		}
+
+		from, oldText = location(builtin, file), string(b)
+	}
+
+	b, err := fs.ReadFile(active.FS, file)
+	if err != nil {
+		return fmt.Errorf("read prompt template: %w", err)
+	}
+
+	fmt.Fprint(os.Stdout, diff.Unified(from, location(active, file), oldText, string(b)))
+
+	return nil
+}
+
+// promptLayers stacks the builtin prompts with the user and project ones.
+func promptLayers(dir string) (*prompt.LayeredFS, error) {
+	builtin, err := architector.Prompts()
+	if err != nil {
+		return nil, fmt.Errorf("builtin prompts: %w", err)
+	}
+
+	layers, err := prompt.DefaultLayers(builtin, dir)
+	if err != nil {
+		return nil, fmt.Errorf("prompt layers: %w", err)
+	}
+
+	return layers, nil
+}
+
+// promptFiles lists the template files of all layers.
+func promptFiles(fsys fs.FS) ([]string, error) {
+	var files []string
+
+	for _, pattern := range []string{"*" + templateExt, path.Join(prompt.PartialsDir, "*"+templateExt)} {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("glob prompt templates: %w", err)
+		}
+
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
+
+// promptFile returns the file of the prompt named by the only argument,
+// e.g. "analyze_task_context" or "_partials/task".
+func promptFile(fset *flag.FlagSet) (string, error) {
+	if fset.NArg() != 1 {
+		return "", fmt.Errorf("%w: prompt name is required", errUsage)
+	}
+
+	name := fset.Arg(0)
+
+	if path.Ext(name) != templateExt {
+		name += templateExt
+	}
+
+	return name, nil
+}
+
+// location returns the path of the file inside the layer for humans.
+func location(layer prompt.Layer, file string) string {
+	if layer.Root == "" {
+		return layer.Name + ":" + file
+	}
+
+	return filepath.Join(layer.Root, filepath.FromSlash(file))
+}
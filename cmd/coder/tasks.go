@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/ollama"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/issues"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/justfiles"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/sqlite"
+)
+
+const tasksUsage = `Usage: coder tasks <command> [flags]
+
+Commands:
+  migrate    convert stored tasks to another format
+  reindex    re-embed all tasks by the embedding model
+  copy       copy tasks and comments between trackers
+  import     import tasks from a Markdown checklist or GitHub issues JSON
+  export     export tasks as a Markdown checklist or GitHub issues JSON
+  sync       synchronize tasks with GitHub or Gitea issues
+
+Trackers are set as <backend>:<path>, where backend is "justfiles" or "sqlite".
+`
+
+func runTasks(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, tasksUsage)
+
+		return fmt.Errorf("%w: tasks command is required", errUsage)
+	}
+
+	switch args[0] {
+	case "migrate":
+		return runTasksMigrate(ctx, args[1:])
+	case "reindex":
+		return runTasksReindex(ctx, args[1:])
+	case "copy":
+		return runTasksCopy(ctx, args[1:])
+	case "import":
+		return runTasksImport(ctx, args[1:])
+	case "export":
+		return runTasksExport(ctx, args[1:])
+	case "sync":
+		return runTasksSync(ctx, args[1:])
+	default:
+		fmt.Fprint(os.Stderr, tasksUsage)
+
+		return fmt.Errorf("%w: unknown tasks command %q", errUsage, args[0])
+	}
+}
+
+func runTasksMigrate(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("tasks migrate", flag.ContinueOnError)
+	dir := fset.String("dir", "tasks", "tasks directory")
+	format := fset.String("format", "md", "target format: yaml or md")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	f, err := justfiles.FormatFromString(*format)
+	if err != nil {
+		return fmt.Errorf("parse format: %w", err)
+	}
+
+	// Migration drops inlined vectors, they are recomputed on the next search, so the embedder is not needed.
+	tracker, err := justfiles.NewTaskTracker(*dir, nil, justfiles.WithFormat(f))
+	if err != nil {
+		return fmt.Errorf("new task tracker: %w", err)
+	}
+
+	n, err := tracker.Migrate(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate tasks: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "migrated %d tasks to %s\n", n, f)
+
+	return nil
+}
+
+func runTasksReindex(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("tasks reindex", flag.ContinueOnError)
+	spec := fset.String("tracker", "justfiles:tasks", "task tracker")
+	serverURL := fset.String("ollama", "http://localhost:11434", "ollama server url")
+	model := fset.String("model", "nomic-embed-text", "embedding model")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	embedder, err := ollama.NewEmbedder(*serverURL, *model)
+	if err != nil {
+		return fmt.Errorf("new embedder: %w", err)
+	}
+
+	tracker, closer, err := openTracker(ctx, *spec, embedder)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	reindexer, ok := tracker.(interface {
+		Reindex(ctx context.Context) error
+	})
+	if !ok {
+		return fmt.Errorf("tracker %q does not support reindexing", *spec)
+	}
+
+	if err := reindexer.Reindex(ctx); err != nil {
+		return fmt.Errorf("reindex tasks: %w", err)
+	}
+
+	return nil
+}
+
+func runTasksCopy(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("tasks copy", flag.ContinueOnError)
+	from := fset.String("from", "justfiles:tasks", "source task tracker")
+	to := fset.String("to", "sqlite:tasks.db", "destination task tracker")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	// Vectors are computed by the destination tracker on the next search.
+	src, srcCloser, err := openTracker(ctx, *from, nil)
+	if err != nil {
+		return err
+	}
+	defer srcCloser.Close()
+
+	dst, dstCloser, err := openTracker(ctx, *to, nil)
+	if err != nil {
+		return err
+	}
+	defer dstCloser.Close()
+
+	n, err := tasktracker.Copy(ctx, dst, src)
+	if err != nil {
+		return fmt.Errorf("copy tasks: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "copied %d tasks\n", n)
+
+	return nil
+}
+
+func runTasksImport(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("tasks import", flag.ContinueOnError)
+	spec := fset.String("tracker", "justfiles:tasks", "task tracker")
+	format := fset.String("format", "checklist", "input format: checklist or github")
+	idPrefix := fset.String("id-prefix", "", "prefix of IDs of imported GitHub issues")
+	input := fset.String("input", "-", "input file, \"-\" for stdin")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	r := io.Reader(os.Stdin)
+
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return fmt.Errorf("open input: %w", err)
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	var (
+		tasks []tasktracker.Task
+		err   error
+	)
+
+	switch *format {
+	case "checklist":
+		tasks, err = tasktracker.ParseChecklist(r)
+	case "github":
+		tasks, err = tasktracker.ParseGitHubIssues(r, *idPrefix)
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("parse tasks: %w", err)
+	}
+
+	tracker, closer, err := openTracker(ctx, *spec, nil)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	for _, task := range tasks {
+		if err := tracker.Set(ctx, task.ID, task); err != nil {
+			return fmt.Errorf("set task %q: %w", task.ID, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "imported %d tasks\n", len(tasks))
+
+	return nil
+}
+
+func runTasksExport(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("tasks export", flag.ContinueOnError)
+	spec := fset.String("tracker", "justfiles:tasks", "task tracker")
+	format := fset.String("format", "checklist", "output format: checklist or github")
+	idPrefix := fset.String("id-prefix", "", "prefix of task IDs stripped from GitHub issue numbers")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	tracker, closer, err := openTracker(ctx, *spec, nil)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	tasks, err := tracker.List(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("list tasks: %w", err)
+	}
+
+	switch *format {
+	case "checklist":
+		err = tasktracker.FormatChecklist(os.Stdout, tasks)
+	case "github":
+		err = tasktracker.FormatGitHubIssues(os.Stdout, tasks, *idPrefix)
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("format tasks: %w", err)
+	}
+
+	return nil
+}
+
+// runTasksSync synchronizes the local tasks directory with the issues of a GitHub or Gitea repository.
+func runTasksSync(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("tasks sync", flag.ContinueOnError)
+	dir := fset.String("dir", "tasks", "local cache directory")
+	baseURL := fset.String("url", "https://api.github.com", "issues API base url, e.g. https://gitea.example.com/api/v1")
+	repo := fset.String("repo", "", "repository as <owner>/<name>")
+	tokenEnv := fset.String("token-env", "CODER_ISSUES_TOKEN", "environment variable with the API token")
+
+	if err := fset.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	owner, name, ok := strings.Cut(*repo, "/")
+	if !ok {
+		return fmt.Errorf("%w: repository %q must be set as <owner>/<name>", errUsage, *repo)
+	}
+
+	// Sync only moves tasks, so the embedder is not needed.
+	cache, err := justfiles.NewTaskTracker(*dir, nil)
+	if err != nil {
+		return fmt.Errorf("new task tracker: %w", err)
+	}
+
+	tracker, err := issues.NewTracker(issues.Config{
+		BaseURL:   *baseURL,
+		Owner:     owner,
+		Repo:      name,
+		Token:     os.Getenv(*tokenEnv),
+		StateFile: filepath.Join(*dir, ".issues.json"),
+	}, cache)
+	if err != nil {
+		return fmt.Errorf("new issues tracker: %w", err)
+	}
+
+	if err := tracker.Sync(ctx); err != nil {
+		return fmt.Errorf("sync tasks: %w", err)
+	}
+
+	return nil
+}
+
+// openTracker opens the task tracker set as "<backend>:<path>".
+func openTracker(ctx context.Context, spec string, embedder llm.Embedder) (tasktracker.Tracker, io.Closer, error) {
+	backend, path, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: tracker %q must be set as <backend>:<path>", errUsage, spec)
+	}
+
+	switch backend {
+	case "justfiles":
+		tracker, err := justfiles.NewTaskTracker(path, embedder)
+		if err != nil {
+			return nil, nil, fmt.Errorf("new justfiles task tracker: %w", err)
+		}
+
+		return tracker, closerFunc(func() error { return nil }), nil
+	case "sqlite":
+		tracker, err := sqlite.NewTaskTracker(ctx, path, embedder)
+		if err != nil {
+			return nil, nil, fmt.Errorf("new sqlite task tracker: %w", err)
+		}
+
+		return tracker, tracker, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown tracker backend %q", errUsage, backend)
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,3 @@
+<json_schema>
+{{.JSONSchema}}
+</json_schema>
\ No newline at end of file
@@ -0,0 +1,4 @@
+<task>
+<title>{{.Title}}</title>
+<description>{{.Description}}</description>
+</task>
\ No newline at end of file
@@ -8,6 +8,7 @@ import (
 	"github.com/WinPooh32/go-coder/internal/developer"
 	"github.com/WinPooh32/go-coder/internal/project"
 	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
 	"github.com/WinPooh32/go-coder/pkg/prompt"
 	"github.com/WinPooh32/go-coder/pkg/tasktracker"
 )
@@ -32,29 +33,64 @@ type Architector struct {
 	project project.Config
 	tracker tasktracker.Tracker
 
-	llms    LLMs
-	prompts map[string]prompt.Prompt
+	llms              LLMs
+	analyzeTaskPrompt prompt.Typed[analyzeTaskData]
+	analyzeTaskSpec   structured.Spec[analyzeTaskResponse]
+	opts              options
 
 	analyzedTasks []developer.TaskAnalyze
 }
 
-func New(projcfg project.Config, tracker tasktracker.Tracker, llms LLMs) (*Architector, error) {
-	prompts, err := prompt.Load(analyzePrompts, "_assets/*.tpl")
+func New(projcfg project.Config, tracker tasktracker.Tracker, llms LLMs, opts ...Option) (*Architector, error) {
+	o := options{
+		docs:      nil,
+		docsLimit: 0,
+		prompts:   nil,
+		repoMap:   nil,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	fsys := o.prompts
+	if fsys == nil {
+		builtin, err := Prompts()
+		if err != nil {
+			return nil, err
+		}
+
+		fsys = builtin
+	}
+
+	prompts, err := prompt.Load(fsys, "*.tpl")
 	if err != nil {
 		return nil, fmt.Errorf("load prompt templates: %w", err)
 	}
 
-	llms.TaskAnalysisGenerators.withAnalyzeTaskFormat, err = llms.Formatter.WithJSONShema(analyzeTaskSchema)
+	analyzeTaskPrompt, err := prompt.Lookup[analyzeTaskData](prompts, analyzeTaskPromptName)
+	if err != nil {
+		return nil, fmt.Errorf("load analyze task prompt: %w", err)
+	}
+
+	analyzeTaskSpec, err := structured.NewSpec[analyzeTaskResponse](analyzeTaskFormatName)
+	if err != nil {
+		return nil, fmt.Errorf("make analyze task schema: %w", err)
+	}
+
+	llms.TaskAnalysisGenerators.withAnalyzeTaskFormat, err = llms.Formatter.WithJSONShema(analyzeTaskSpec.Format())
 	if err != nil {
 		return nil, fmt.Errorf("make generator with analyze task format: %w", err)
 	}
 
 	return &Architector{
-		project:       projcfg,
-		tracker:       tracker,
-		prompts:       prompts,
-		llms:          llms,
-		analyzedTasks: nil,
+		project:           projcfg,
+		tracker:           tracker,
+		llms:              llms,
+		analyzeTaskPrompt: analyzeTaskPrompt,
+		analyzeTaskSpec:   analyzeTaskSpec,
+		opts:              o,
+		analyzedTasks:     nil,
 	}, nil
 }
 
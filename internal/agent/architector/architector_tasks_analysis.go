@@ -2,22 +2,212 @@ package architector
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/WinPooh32/go-coder/internal/developer"
+	"github.com/WinPooh32/go-coder/pkg/docindex"
 	"github.com/WinPooh32/go-coder/pkg/tasktracker"
 )
 
-type spec struct {
+// recentCommentsLimit is the number of the latest task comments included into the analysis prompt.
+const recentCommentsLimit = 10
+
+const analyzeTaskPromptName = "analyze_task_context"
+
+// analyzeTaskData is the data of the analyze_task_context prompt.
+type analyzeTaskData struct {
+	Context     string
+	Docs        []docindex.Result
 	Title       string
 	Description string
+	Comments    []tasktracker.Comment
+	JSONSchema  string
+}
+
+const analyzeTaskFormatName = "analyze_task"
+
+type spec struct {
+	Title       string `json:"title"       description:"Short summary about subtask."`
+	Description string `json:"description" description:"Detailed task specification."`
+}
+
+//nolint:lll // Descriptions are instructions for the model.
+type analyzeTaskResponse struct {
+	Thoughts            string `json:"thoughts"             description:"Your step by step thoughts about user's query. For completing this well you need to find all objects and describe relations between them."`
+	Feedback            string `json:"feedback"             description:"Detailed feedback of the task."`
+	Subtasks            []spec `json:"subtasks"             description:"List of new subtasks can be solved independently. Together they solve root task. If root task is simple enough, set value as null."`
+	ClarificationNeeded bool   `json:"clarification_needed" description:"Set true if task can't be solved without additional clarification. Otherwise set false."`
 }
 
 func (arch *Architector) analyzeTasks(ctx context.Context, tasks []tasktracker.Task) ([]developer.TaskAnalyze, error) {
-	// prompt, ok := arch.prompts["analyze_task_schema"]
-	// if !ok {
-	// 	return nil, errors.New("not found for analyze_task_schema")
-	// }
-	panic("todo")
+	analyze := make([]developer.TaskAnalyze, 0, len(tasks))
+
+	for _, task := range tasks {
+		a, err := arch.analyzeTask(ctx, task)
+		if err != nil {
+			return nil, fmt.Errorf("analyze task %q: %w", task.ID, err)
+		}
+
+		analyze = append(analyze, a)
+	}
+
+	return analyze, nil
+}
+
+func (arch *Architector) analyzeTask(ctx context.Context, task tasktracker.Task) (developer.TaskAnalyze, error) {
+	comments, err := arch.recentComments(ctx, task.ID)
+	if err != nil {
+		return developer.TaskAnalyze{}, err
+	}
+
+	docs := arch.relevantDocs(ctx, task)
+
+	msgs, err := arch.analyzeTaskPrompt.Messages(analyzeTaskData{
+		Context:     arch.taskContext(ctx, task),
+		Docs:        docs,
+		Title:       task.Title,
+		Description: task.Description,
+		Comments:    comments,
+		JSONSchema:  string(arch.analyzeTaskSpec.Format()),
+	})
+	if err != nil {
+		return developer.TaskAnalyze{}, fmt.Errorf("execute prompt: %w", err)
+	}
+
+	resp, _, err := arch.analyzeTaskSpec.Generate(ctx, arch.llms.withAnalyzeTaskFormat, msgs)
+	if err != nil {
+		return developer.TaskAnalyze{}, fmt.Errorf("generate analysis: %w", err)
+	}
+
+	if resp.ClarificationNeeded {
+		if err := arch.askClarification(ctx, task.ID, comments, resp.Feedback); err != nil {
+			return developer.TaskAnalyze{}, err
+		}
+	}
+
+	return developer.TaskAnalyze{
+		Task: developer.Task{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+		},
+		Feedback:            resp.Feedback,
+		Subtasks:            subtasks(resp.Subtasks),
+		ClarificationNeeded: resp.ClarificationNeeded,
+		Done:                task.Done,
+	}, nil
+}
+
+// subtasks converts the proposed subtasks. They have no IDs until they are added to the tracker.
+func subtasks(specs []spec) []developer.Task {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	tasks := make([]developer.Task, 0, len(specs))
+
+	for _, s := range specs {
+		tasks = append(tasks, developer.Task{
+			ID:          "",
+			Title:       s.Title,
+			Description: s.Description,
+		})
+	}
+
+	return tasks
+}
+
+// recentComments returns the latest comments of the task.
+func (arch *Architector) recentComments(ctx context.Context, id string) ([]tasktracker.Comment, error) {
+	comments, err := arch.tracker.ListComments(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list task comments: %w", err)
+	}
+
+	if len(comments) > recentCommentsLimit {
+		comments = comments[len(comments)-recentCommentsLimit:]
+	}
+
+	return comments, nil
+}
+
+// relevantDocs returns the documentation excerpts relevant to the task.
+// Retrieval errors are not fatal: the task is analyzed without documentation.
+func (arch *Architector) relevantDocs(ctx context.Context, task tasktracker.Task) []docindex.Result {
+	if arch.opts.docs == nil {
+		return nil
+	}
+
+	docs, err := arch.opts.docs.Retrieve(ctx, task.Title+"\n\n"+task.Description, arch.opts.docsLimit)
+	if err != nil {
+		slog.Warn("retrieve task documentation",
+			slog.String("task", task.ID),
+			slog.String("error", err.Error()),
+		)
+
+		return nil
+	}
+
+	return docs
+}
+
+// taskContext returns the outline of the project relevant to the task.
+// Rendering errors are not fatal: the task is analyzed without the context.
+func (arch *Architector) taskContext(ctx context.Context, task tasktracker.Task) string {
+	if arch.opts.repoMap == nil {
+		return ""
+	}
+
+	outline, err := arch.opts.repoMap.Render(ctx, task.Title+"\n\n"+task.Description)
+	if err != nil {
+		slog.Warn("render task context",
+			slog.String("task", task.ID),
+			slog.String("error", err.Error()),
+		)
+
+		return ""
+	}
+
+	return outline
+}
+
+// askClarification leaves the question as a task comment, so a human can answer it.
+// The question is skipped when the previous one is still unanswered.
+func (arch *Architector) askClarification(
+	ctx context.Context, id string, comments []tasktracker.Comment, question string,
+) error {
+	if !questionAnswered(comments) {
+		return nil
+	}
+
+	if err := arch.tracker.AddComment(ctx, id, tasktracker.Comment{
+		Author:     tasktracker.AuthorArchitector,
+		CreatedAt:  time.Now(),
+		Body:       question,
+		Attachment: "",
+	}); err != nil {
+		return fmt.Errorf("add clarification comment: %w", err)
+	}
+
+	return nil
+}
+
+// questionAnswered reports whether a human commented after the last architector question.
+// Notes of other agents don't answer questions. It's true if there is no question.
+func questionAnswered(comments []tasktracker.Comment) bool {
+	for i := len(comments) - 1; i >= 0; i-- {
+		switch comments[i].Author {
+		case tasktracker.AuthorHuman:
+			return true
+		case tasktracker.AuthorArchitector:
+			return false
+		case tasktracker.AuthorCoder, tasktracker.AuthorDebugger, tasktracker.AuthorFixer, tasktracker.AuthorTester:
+		}
+	}
+
+	return true
 }
 
 func (arch *Architector) generateInitialTasks(ctx context.Context) error {
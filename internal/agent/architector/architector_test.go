@@ -0,0 +1,145 @@
+package architector_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/go-coder/internal/agent/architector"
+	"github.com/WinPooh32/go-coder/internal/developer"
+	"github.com/WinPooh32/go-coder/internal/project"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/justfiles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingGenerator records the last messages of the histories it answers.
+type recordingGenerator struct {
+	scriptedGenerator
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (g *recordingGenerator) Generate(
+	ctx context.Context, history []llm.Message, tools []llm.ToolFunction,
+) (llm.Message, error) {
+	g.mu.Lock()
+	g.prompts = append(g.prompts, history[len(history)-1].Content)
+	g.mu.Unlock()
+
+	return g.scriptedGenerator.Generate(ctx, history, tools)
+}
+
+// lastPrompt returns the last recorded message containing the text.
+func (g *recordingGenerator) lastPrompt(text string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, p := range slices.Backward(g.prompts) {
+		if strings.Contains(p, text) {
+			return p
+		}
+	}
+
+	return ""
+}
+
+// formatter ignores the schema and answers by the generator.
+type formatter struct {
+	gen llm.MessageGenerator
+}
+
+//nolint:ireturn // Implements the interface.
+func (f formatter) WithJSONShema(json.RawMessage) (llm.MessageGenerator, error) {
+	return f.gen, nil
+}
+
+func TestArchitector_AnalyzeTasks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tracker, err := justfiles.NewTaskTracker(t.TempDir(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.Set(ctx, "1", tasktracker.Task{Title: "Make it faster", Description: "The tool is slow."}))
+	require.NoError(t, tracker.Set(ctx, "2", tasktracker.Task{Title: "Import and export", Description: "Both formats."}))
+
+	gen := &recordingGenerator{scriptedGenerator: scriptedGenerator{answers: map[string]string{
+		"Make it faster": `{"thoughts":"t","feedback":"Which command?","subtasks":null,"clarification_needed":true}`,
+		"Import and export": `{"thoughts":"t","feedback":"f","subtasks":[` +
+			`{"title":"Import","description":"Read."},{"title":"Export","description":"Write."}],"clarification_needed":false}`,
+	}}}
+
+	arch, err := architector.New(project.Config{RootDir: t.TempDir(), DocsIndexFile: ""}, tracker, architector.LLMs{
+		TaskAnalysisGenerators: architector.TaskAnalysisGenerators{Chat: gen, Formatter: formatter{gen: gen}},
+	})
+	require.NoError(t, err)
+
+	analyze, err := arch.AnalyzeTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, analyze, 2)
+
+	assert.True(t, analyze[0].ClarificationNeeded)
+	assert.Nil(t, analyze[0].Subtasks)
+	assert.Equal(t, []developer.Task{
+		{ID: "", Title: "Import", Description: "Read."},
+		{ID: "", Title: "Export", Description: "Write."},
+	}, analyze[1].Subtasks)
+
+	comments, err := tracker.ListComments(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, tasktracker.AuthorArchitector, comments[0].Author)
+	assert.Equal(t, "Which command?", comments[0].Body)
+
+	// The question is unanswered, so it isn't asked again.
+	_, err = arch.AnalyzeTasks(ctx)
+	require.NoError(t, err)
+
+	comments, err = tracker.ListComments(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+
+	// Notes of other agents don't answer the question.
+	require.NoError(t, tracker.AddComment(ctx, "1", tasktracker.Comment{
+		Author:     tasktracker.AuthorCoder,
+		CreatedAt:  time.Now(),
+		Body:       "Profiled the startup.",
+		Attachment: "",
+	}))
+
+	_, err = arch.AnalyzeTasks(ctx)
+	require.NoError(t, err)
+
+	comments, err = tracker.ListComments(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, comments, 2, "the question must not be asked again after a coder note")
+
+	require.NoError(t, tracker.AddComment(ctx, "1", tasktracker.Comment{
+		Author:     tasktracker.AuthorHuman,
+		CreatedAt:  time.Now(),
+		Body:       "The build command.",
+		Attachment: "",
+	}))
+
+	_, err = arch.AnalyzeTasks(ctx)
+	require.NoError(t, err)
+
+	// The question and the answer are in the prompt of the next analysis.
+	taskPrompt := gen.lastPrompt("Make it faster")
+	assert.Contains(t, taskPrompt, "Which command?")
+	assert.Contains(t, taskPrompt, "The build command.")
+
+	comments, err = tracker.ListComments(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, comments, 4, "the question must be asked again after the answer")
+	assert.Equal(t, tasktracker.AuthorArchitector, comments[3].Author)
+}
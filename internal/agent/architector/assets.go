@@ -2,13 +2,20 @@ package architector
 
 import (
 	"embed"
-	"encoding/json"
+	"fmt"
+	"io/fs"
 )
 
-var (
-	//go:embed _assets/analyze*.tpl
-	analyzePrompts embed.FS
+//go:embed _assets/analyze*.tpl _assets/_partials/*.tpl
+var analyzePrompts embed.FS
 
-	//go:embed _assets/analyze_task_schema.json
-	analyzeTaskSchema json.RawMessage
-)
+// Prompts returns the builtin prompt templates of the architector.
+// They are the lowest layer of prompt.DefaultLayers.
+func Prompts() (fs.FS, error) {
+	sub, err := fs.Sub(analyzePrompts, "_assets")
+	if err != nil {
+		return nil, fmt.Errorf("sub assets: %w", err)
+	}
+
+	return sub, nil
+}
@@ -0,0 +1,243 @@
+package architector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+)
+
+// EvalCase is a task with the expected properties of its analysis.
+type EvalCase struct {
+	Name        string     `yaml:"name"`
+	Title       string     `yaml:"title"`
+	Description string     `yaml:"description"`
+	Expect      EvalExpect `yaml:"expect"`
+}
+
+// EvalExpect are the expected properties of a task analysis. Nil properties are not checked.
+// The analysis must always be valid against the analyze_task schema.
+type EvalExpect struct {
+	MinSubtasks         *int  `yaml:"min_subtasks"`
+	MaxSubtasks         *int  `yaml:"max_subtasks"`
+	ClarificationNeeded *bool `yaml:"clarification_needed"`
+}
+
+// EvalVariant is a named set of prompt templates, e.g. the builtin ones
+// with some of them overridden, see [Prompts].
+type EvalVariant struct {
+	Name    string
+	Prompts fs.FS
+}
+
+// EvalModel is a named generator of analyses. It must produce the JSON
+// of the analyze_task schema, see [AnalyzeTaskFormat].
+type EvalModel struct {
+	Name      string
+	Generator llm.MessageGenerator
+}
+
+// EvalRun is the result of analyzing a case by a model with a prompt variant.
+type EvalRun struct {
+	Variant string
+	Model   string
+	Case    string
+	Latency time.Duration
+	Usage   llm.Usage
+	// Failures describe the failed expectations, the run passed if there are none.
+	Failures []string
+}
+
+func (r EvalRun) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// EvalSummary aggregates the runs of a prompt variant with a model.
+type EvalSummary struct {
+	Variant string
+	Model   string
+	Runs    int
+	Passed  int
+	// Latency is the mean latency of generations.
+	Latency time.Duration
+	// Usage is the total token usage.
+	Usage llm.Usage
+}
+
+// PassRate returns the share of passed runs.
+func (s EvalSummary) PassRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+
+	return float64(s.Passed) / float64(s.Runs)
+}
+
+// AnalyzeTaskFormat returns the envelope of the analyze_task response schema.
+func AnalyzeTaskFormat() (json.RawMessage, error) {
+	spec, err := structured.NewSpec[analyzeTaskResponse](analyzeTaskFormatName)
+	if err != nil {
+		return nil, fmt.Errorf("make analyze task schema: %w", err)
+	}
+
+	return spec.Format(), nil
+}
+
+// Evaluate analyzes every case by every model with every prompt variant.
+// Failed generations are reported as failures of the runs, but an invalid
+// prompt variant or a canceled context stops the evaluation.
+func Evaluate(ctx context.Context, variants []EvalVariant, models []EvalModel, cases []EvalCase) ([]EvalRun, error) {
+	spec, err := structured.NewSpec[analyzeTaskResponse](analyzeTaskFormatName)
+	if err != nil {
+		return nil, fmt.Errorf("make analyze task schema: %w", err)
+	}
+
+	runs := make([]EvalRun, 0, len(variants)*len(models)*len(cases))
+
+	for _, variant := range variants {
+		prompts, err := prompt.Load(variant.Prompts, "*.tpl")
+		if err != nil {
+			return nil, fmt.Errorf("load prompts of variant %q: %w", variant.Name, err)
+		}
+
+		analyzePrompt, err := prompt.Lookup[analyzeTaskData](prompts, analyzeTaskPromptName)
+		if err != nil {
+			return nil, fmt.Errorf("load analyze task prompt of variant %q: %w", variant.Name, err)
+		}
+
+		for _, model := range models {
+			for _, c := range cases {
+				run, err := evaluateCase(ctx, analyzePrompt, spec, model, c)
+				if err != nil {
+					return nil, fmt.Errorf("variant %q, model %q, case %q: %w", variant.Name, model.Name, c.Name, err)
+				}
+
+				run.Variant = variant.Name
+				runs = append(runs, run)
+			}
+		}
+	}
+
+	return runs, nil
+}
+
+func evaluateCase(
+	ctx context.Context,
+	analyzePrompt prompt.Typed[analyzeTaskData],
+	spec structured.Spec[analyzeTaskResponse],
+	model EvalModel,
+	c EvalCase,
+) (EvalRun, error) {
+	run := EvalRun{
+		Variant:  "",
+		Model:    model.Name,
+		Case:     c.Name,
+		Latency:  0,
+		Usage:    llm.Usage{PromptTokens: 0, CompletionTokens: 0},
+		Failures: nil,
+	}
+
+	msgs, err := analyzePrompt.Messages(analyzeTaskData{
+		Context:     "",
+		Docs:        nil,
+		Title:       c.Title,
+		Description: c.Description,
+		Comments:    nil,
+		JSONSchema:  string(spec.Format()),
+	})
+	if err != nil {
+		return run, fmt.Errorf("render prompt: %w", err)
+	}
+
+	start := time.Now()
+	msg, err := model.Generator.Generate(ctx, msgs, nil)
+	run.Latency = time.Since(start)
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return run, fmt.Errorf("generate analysis: %w", err)
+		}
+
+		run.Failures = append(run.Failures, "generate: "+err.Error())
+
+		return run, nil
+	}
+
+	run.Usage = msg.Usage
+	run.Failures = checkAnalysis(spec, msg.Content, c.Expect)
+
+	return run, nil
+}
+
+func checkAnalysis(spec structured.Spec[analyzeTaskResponse], content string, expect EvalExpect) []string {
+	resp, err := spec.Decode([]byte(content))
+	if err != nil {
+		return []string{"schema: " + err.Error()}
+	}
+
+	var failures []string
+
+	if n := len(resp.Subtasks); expect.MinSubtasks != nil && n < *expect.MinSubtasks {
+		failures = append(failures, fmt.Sprintf("subtasks: got %d, want at least %d", n, *expect.MinSubtasks))
+	}
+
+	if n := len(resp.Subtasks); expect.MaxSubtasks != nil && n > *expect.MaxSubtasks {
+		failures = append(failures, fmt.Sprintf("subtasks: got %d, want at most %d", n, *expect.MaxSubtasks))
+	}
+
+	if want := expect.ClarificationNeeded; want != nil && resp.ClarificationNeeded != *want {
+		failures = append(failures, fmt.Sprintf("clarification_needed: got %t, want %t", resp.ClarificationNeeded, *want))
+	}
+
+	return failures
+}
+
+// Summarize aggregates the runs by prompt variants and models in order of their first runs.
+func Summarize(runs []EvalRun) []EvalSummary {
+	type key struct{ variant, model string }
+
+	var summaries []EvalSummary
+
+	index := map[key]int{}
+	latencies := map[key]time.Duration{}
+
+	for _, run := range runs {
+		k := key{variant: run.Variant, model: run.Model}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(summaries)
+			index[k] = i
+
+			summaries = append(summaries, EvalSummary{
+				Variant: run.Variant,
+				Model:   run.Model,
+				Runs:    0,
+				Passed:  0,
+				Latency: 0,
+				Usage:   llm.Usage{PromptTokens: 0, CompletionTokens: 0},
+			})
+		}
+
+		s := &summaries[i]
+		s.Runs++
+		s.Usage = s.Usage.Add(run.Usage)
+		latencies[k] += run.Latency
+
+		if run.Passed() {
+			s.Passed++
+		}
+	}
+
+	for k, i := range index {
+		summaries[i].Latency = latencies[k] / time.Duration(summaries[i].Runs)
+	}
+
+	return summaries
+}
@@ -0,0 +1,143 @@
+package architector_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/WinPooh32/go-coder/internal/agent/architector"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// scriptedGenerator answers with the response of the first case whose title
+// is in the last message.
+type scriptedGenerator struct {
+	answers map[string]string
+	err     error
+}
+
+func (g scriptedGenerator) Generate(
+	_ context.Context, history []llm.Message, _ []llm.ToolFunction,
+) (llm.Message, error) {
+	if g.err != nil {
+		return llm.Message{}, g.err
+	}
+
+	last := history[len(history)-1].Content
+
+	for title, answer := range g.answers {
+		if strings.Contains(last, title) {
+			return llm.Message{
+				Role:    llm.Assistant,
+				Content: answer,
+				Usage:   llm.Usage{PromptTokens: 100, CompletionTokens: 10},
+			}, nil
+		}
+	}
+
+	return llm.Message{Role: llm.Assistant, Content: "I don't know"}, nil
+}
+
+func loadCases(t *testing.T) []architector.EvalCase {
+	t.Helper()
+
+	b, err := os.ReadFile("testdata/eval_cases.yaml")
+	require.NoError(t, err)
+
+	var cases []architector.EvalCase
+
+	require.NoError(t, yaml.Unmarshal(b, &cases))
+
+	return cases
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	builtin, err := architector.Prompts()
+	require.NoError(t, err)
+
+	good := scriptedGenerator{answers: map[string]string{
+		"Print the version": `{"thoughts":"t","feedback":"f","subtasks":null,"clarification_needed":false}`,
+		"Make it faster":    `{"thoughts":"t","feedback":"Which command?","subtasks":null,"clarification_needed":true}`,
+		"Import and export": `{"thoughts":"t","feedback":"f","subtasks":[` +
+			`{"title":"Import","description":"d"},{"title":"Export","description":"d"}],"clarification_needed":false}`,
+	}}
+
+	sloppy := scriptedGenerator{answers: map[string]string{
+		"Print the version": `{"thoughts":"t","feedback":"f","subtasks":null,"clarification_needed":true}`,
+		"Make it faster":    `{"thoughts":"t","feedback":"f"}`,
+		"Import and export": `not json`,
+	}}
+
+	variants := []architector.EvalVariant{
+		{Name: "builtin", Prompts: builtin},
+		{Name: "terse", Prompts: prompt.NewLayeredFS(
+			prompt.Layer{Name: prompt.BuiltinLayer, FS: builtin},
+			prompt.Layer{Name: "terse", FS: fstest.MapFS{
+				"analyze_task_context.tpl": {Data: []byte(`{{message "system"}}Analyze.{{message "user"}}{{template "task" .}}`)},
+			}},
+		)},
+	}
+
+	models := []architector.EvalModel{
+		{Name: "good", Generator: good},
+		{Name: "sloppy", Generator: sloppy},
+		{Name: "down", Generator: scriptedGenerator{err: errors.New("connection refused")}},
+	}
+
+	runs, err := architector.Evaluate(context.Background(), variants, models, loadCases(t))
+	require.NoError(t, err)
+	require.Len(t, runs, 2*3*3)
+
+	failures := map[string][]string{}
+
+	for _, run := range runs {
+		if run.Variant == "builtin" {
+			failures[run.Model+"/"+run.Case] = run.Failures
+		}
+	}
+
+	assert.Empty(t, failures["good/version-flag"])
+	assert.Empty(t, failures["good/vague"])
+	assert.Empty(t, failures["good/import-export"])
+	assert.Equal(t, []string{"clarification_needed: got true, want false"}, failures["sloppy/version-flag"])
+	require.Len(t, failures["sloppy/vague"], 1)
+	assert.Contains(t, failures["sloppy/vague"][0], `missing required property "subtasks"`)
+	require.Len(t, failures["sloppy/import-export"], 1)
+	assert.Contains(t, failures["sloppy/import-export"][0], "invalid json")
+	require.Len(t, failures["down/vague"], 1)
+	assert.Contains(t, failures["down/vague"][0], "connection refused")
+
+	summaries := architector.Summarize(runs)
+	require.Len(t, summaries, 2*3)
+
+	assert.Equal(t, "builtin", summaries[0].Variant)
+	assert.Equal(t, "good", summaries[0].Model)
+	assert.Equal(t, 3, summaries[0].Runs)
+	assert.Equal(t, 3, summaries[0].Passed)
+	assert.InDelta(t, 1.0, summaries[0].PassRate(), 1e-9)
+	assert.Equal(t, 330, summaries[0].Usage.Total())
+
+	assert.Equal(t, 0, summaries[1].Passed, "sloppy model")
+	assert.Equal(t, 0, summaries[2].Passed, "model is down")
+	assert.Equal(t, 0, summaries[2].Usage.Total())
+}
+
+func TestEvaluate_InvalidVariant(t *testing.T) {
+	t.Parallel()
+
+	variants := []architector.EvalVariant{{Name: "typo", Prompts: fstest.MapFS{
+		"analyze_task_context.tpl": {Data: []byte(`{{.Tittle}}`)},
+	}}}
+
+	_, err := architector.Evaluate(context.Background(), variants, nil, nil)
+	require.ErrorIs(t, err, prompt.ErrUnknownField)
+}
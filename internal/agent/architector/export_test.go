@@ -0,0 +1,4 @@
+package architector
+
+// AnalyzeTaskData exposes the data of the analyze task prompt to tests.
+type AnalyzeTaskData = analyzeTaskData
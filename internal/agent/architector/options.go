@@ -0,0 +1,61 @@
+package architector
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/WinPooh32/go-coder/pkg/docindex"
+)
+
+// defaultDocsLimit is the number of documentation excerpts included into the analysis prompt.
+const defaultDocsLimit = 5
+
+// DocRetriever finds documentation excerpts relevant to the query.
+type DocRetriever interface {
+	Retrieve(ctx context.Context, query string, k int) ([]docindex.Result, error)
+}
+
+// RepoMapper renders the outline of the project relevant to the query.
+type RepoMapper interface {
+	Render(ctx context.Context, query string) (string, error)
+}
+
+type options struct {
+	docs      DocRetriever
+	docsLimit int
+	prompts   fs.FS
+	repoMap   RepoMapper
+}
+
+type Option func(*options)
+
+// WithDocs attaches up to limit most relevant documentation excerpts to every analyzed task.
+// Default: no documentation, the limit is 5 when it is not positive.
+func WithDocs(docs DocRetriever, limit int) Option {
+	return func(opts *options) {
+		opts.docs = docs
+		opts.docsLimit = limit
+
+		if limit <= 0 {
+			opts.docsLimit = defaultDocsLimit
+		}
+	}
+}
+
+// WithPrompts loads the prompt templates from the fsys, e.g. the layers
+// from prompt.DefaultLayers over [Prompts].
+// Default: the builtin templates.
+func WithPrompts(fsys fs.FS) Option {
+	return func(opts *options) {
+		opts.prompts = fsys
+	}
+}
+
+// WithRepoMap fills the context of every analyzed task with the outline of the project
+// ranked by relevance to the task, e.g. by a repomap.Map.
+// Default: no context.
+func WithRepoMap(repoMap RepoMapper) Option {
+	return func(opts *options) {
+		opts.repoMap = repoMap
+	}
+}
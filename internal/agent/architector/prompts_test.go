@@ -0,0 +1,59 @@
+package architector_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/go-coder/internal/agent/architector"
+	"github.com/WinPooh32/go-coder/pkg/docindex"
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+	"github.com/WinPooh32/go-coder/pkg/prompt/prompttest"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompts(t *testing.T) {
+	t.Parallel()
+
+	fsys, err := architector.Prompts()
+	require.NoError(t, err)
+
+	prompts, err := prompt.Load(fsys, "*.tpl")
+	require.NoError(t, err)
+
+	prompttest.RenderAll(t, prompts, map[string]any{
+		"analyze_task_context": architector.AnalyzeTaskData{
+			Context: "Go module github.com/example/app.",
+			Docs: []docindex.Result{{
+				URL: "README.md",
+				Chunk: doctree.Chunk{
+					ID:       "README.md#build",
+					Heading:  "Build",
+					Headings: []string{"App", "Build"},
+					Content:  "Run go build ./...",
+				},
+				Score: 0.9,
+			}},
+			Title:       "Print the version",
+			Description: "Add a -version flag.",
+			Comments: []tasktracker.Comment{{
+				Author:     tasktracker.AuthorArchitector,
+				CreatedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+				Body:       "Which version format?",
+				Attachment: "",
+			}},
+			JSONSchema: "{}",
+		},
+	})
+}
+
+func TestAnalyzeTaskFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := architector.AnalyzeTaskFormat()
+	require.NoError(t, err)
+
+	require.NoError(t, structured.LintFormat(format))
+}
@@ -9,7 +9,9 @@ type TaskExecute struct {
 
 type TaskAnalyze struct {
 	Task
-	Feedback            string
+	Feedback string
+	// Subtasks are proposed by the analysis, they aren't added to the tracker yet.
+	Subtasks            []Task
 	ClarificationNeeded bool
 	Done                bool
 }
@@ -0,0 +1,40 @@
+// Package fsutil contains file system helpers shared by caches and stores.
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to a temporary file and renames it to filename,
+// so concurrent readers never see partially written files.
+func WriteFileAtomic(filename string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+
+	tmp := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+
+		return fmt.Errorf("write temp file %q: %w", tmp, err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+
+		return fmt.Errorf("close temp file %q: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+
+		return fmt.Errorf("rename temp file to %q: %w", filename, err)
+	}
+
+	return nil
+}
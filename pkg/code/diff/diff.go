@@ -0,0 +1,230 @@
+// Package diff compares texts line by line, renders the differences
+// in the unified format and applies unified diffs.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines around every change.
+const DefaultContext = 3
+
+const noNewline = "\\ No newline at end of file\n"
+
+type kind int
+
+const (
+	equal kind = iota
+	del
+	ins
+)
+
+// edit transforms a line of the old text to the new one.
+// The a and b are indexes of the line in the old and the new text.
+type edit struct {
+	kind kind
+	a, b int
+}
+
+// Unified returns the unified diff of the old and new texts with
+// [DefaultContext] lines of context, or an empty string when they are equal.
+// The from and to are names of the texts printed in the header.
+func Unified(from, to, oldText, newText string) string {
+	a, b := SplitLines(oldText), SplitLines(newText)
+	edits := myers(a, b)
+
+	sb := strings.Builder{}
+
+	for _, h := range hunks(edits, DefaultContext) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", from, to)
+		}
+
+		writeHunk(&sb, a, b, h)
+	}
+
+	return sb.String()
+}
+
+// SplitLines splits the s after every line feed. The last line has no line feed
+// when the s doesn't end with it.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// myers finds the shortest edit script with the Myers' algorithm.
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	trace := [][]int{}
+
+	for d := 0; d <= offset; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, offset, n, m)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrack(trace [][]int, offset, x, y int) []edit {
+	edits := []edit{}
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{kind: equal, a: x, b: y})
+		}
+
+		if d == 0 {
+			break
+		}
+
+		if x == prevX {
+			edits = append(edits, edit{kind: ins, a: x, b: prevY})
+		} else {
+			edits = append(edits, edit{kind: del, a: prevX, b: y})
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
+
+// hunks groups changes with up to context unchanged lines around them.
+func hunks(edits []edit, context int) [][]edit {
+	keep := make([]bool, len(edits))
+
+	for i, e := range edits {
+		if e.kind == equal {
+			continue
+		}
+
+		for j := max(0, i-context); j <= min(len(edits)-1, i+context); j++ {
+			keep[j] = true
+		}
+	}
+
+	var groups [][]edit
+
+	start := -1
+
+	for i := range edits {
+		switch {
+		case keep[i] && start < 0:
+			start = i
+		case !keep[i] && start >= 0:
+			groups = append(groups, edits[start:i])
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		groups = append(groups, edits[start:])
+	}
+
+	return groups
+}
+
+func writeHunk(sb *strings.Builder, a, b []string, h []edit) {
+	aStart, bStart := h[0].a, h[0].b
+	aLen, bLen := 0, 0
+
+	for _, e := range h {
+		switch e.kind {
+		case equal:
+			aLen++
+			bLen++
+		case del:
+			aLen++
+		case ins:
+			bLen++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+
+	for _, e := range h {
+		switch e.kind {
+		case equal:
+			writeLine(sb, ' ', a[e.a])
+		case del:
+			writeLine(sb, '-', a[e.a])
+		case ins:
+			writeLine(sb, '+', b[e.b])
+		}
+	}
+}
+
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, length)
+	}
+}
+
+func writeLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n" + noNewline)
+	}
+}
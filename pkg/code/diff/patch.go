@@ -0,0 +1,431 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxFuzz is the number of context lines at the hunk edges that may be ignored
+// when the hunk doesn't match otherwise.
+const MaxFuzz = 2
+
+const devNull = "/dev/null"
+
+var (
+	ErrMalformed = errors.New("malformed patch")
+	ErrConflict  = errors.New("patch doesn't apply")
+)
+
+// Op is the operation of a hunk line.
+type Op byte
+
+const (
+	Context Op = ' '
+	Delete  Op = '-'
+	Insert  Op = '+'
+)
+
+// Line is a line of a hunk. The Text ends with a line feed unless it is the last
+// line of the text.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Hunk is a group of changes. The OldStart and NewStart are 1-based line numbers.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+func (h Hunk) String() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// File is the patch of a file.
+type File struct {
+	OldName string
+	NewName string
+	Hunks   []Hunk
+}
+
+// Name returns the name of the patched file without the "a/" and "b/" prefixes of git.
+func (f File) Name() string {
+	if f.NewName == devNull || f.NewName == "" {
+		return strings.TrimPrefix(f.OldName, "a/")
+	}
+
+	return strings.TrimPrefix(f.NewName, "b/")
+}
+
+// Conflict is the hunk that doesn't match the text.
+type Conflict struct {
+	// Index is the 0-based index of the hunk in the file patch.
+	Index int
+	Hunk  Hunk
+}
+
+// ConflictError reports the hunks that were not applied.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	sb := strings.Builder{}
+
+	fmt.Fprintf(&sb, "%s: %d hunk(s) failed:", ErrConflict, len(e.Conflicts))
+
+	for _, c := range e.Conflicts {
+		fmt.Fprintf(&sb, "\nhunk #%d %s doesn't match:\n", c.Index+1, c.Hunk)
+
+		for _, l := range c.Hunk.Lines {
+			if l.Op != Insert {
+				writeLine(&sb, byte(l.Op), l.Text)
+			}
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// Parse parses the unified diff of one or more files. Lines outside of hunks,
+// like the git extended headers, are skipped.
+//
+// The line counts of hunk headers are not trusted since hand-written patches often
+// get them wrong: a hunk lasts until the next hunk or file header, or the first
+// line that isn't a hunk line, and the counts are recalculated. An empty line
+// inside a hunk is an empty context line.
+func Parse(patch string) ([]File, error) {
+	var files []File
+
+	ls := SplitLines(patch)
+
+	for i := 0; i < len(ls); i++ {
+		switch {
+		case isFileHeader(ls, i):
+			files = append(files, File{
+				OldName: headerName(ls[i]),
+				NewName: headerName(ls[i+1]),
+				Hunks:   nil,
+			})
+			i++
+		case strings.HasPrefix(ls[i], "@@"):
+			if len(files) == 0 {
+				return nil, fmt.Errorf("%w: line %d: hunk without file header", ErrMalformed, i+1)
+			}
+
+			h, n, err := parseHunk(ls[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrMalformed, i+1, err)
+			}
+
+			f := &files[len(files)-1]
+			f.Hunks = append(f.Hunks, h)
+			i += n - 1
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no file headers", ErrMalformed)
+	}
+
+	return files, nil
+}
+
+func isFileHeader(ls []string, i int) bool {
+	return strings.HasPrefix(ls[i], "--- ") && i+1 < len(ls) && strings.HasPrefix(ls[i+1], "+++ ")
+}
+
+func headerName(l string) string {
+	name := strings.TrimSpace(l[len("--- "):])
+
+	// Drop the timestamp of diff -u.
+	if before, _, ok := strings.Cut(name, "\t"); ok {
+		name = before
+	}
+
+	return name
+}
+
+// parseHunk parses the hunk at the beginning of ls and returns the number of lines it takes.
+func parseHunk(ls []string) (Hunk, int, error) {
+	h := Hunk{OldStart: 0, OldLines: 0, NewStart: 0, NewLines: 0, Lines: nil}
+
+	var err error
+
+	h.OldStart, h.NewStart, err = parseHunkHeader(ls[0])
+	if err != nil {
+		return h, 0, err
+	}
+
+	n := 1
+
+	for ; n < len(ls); n++ {
+		l := ls[n]
+
+		if strings.HasPrefix(l, "@@") || isFileHeader(ls, n) {
+			break
+		}
+
+		switch {
+		case l == "\n":
+			h.Lines = append(h.Lines, Line{Op: Context, Text: l})
+		case strings.HasPrefix(l, "\\"):
+			if len(h.Lines) > 0 {
+				last := &h.Lines[len(h.Lines)-1]
+				last.Text = strings.TrimSuffix(last.Text, "\n")
+			}
+		case l[0] == byte(Context) || l[0] == byte(Delete) || l[0] == byte(Insert):
+			h.Lines = append(h.Lines, Line{Op: Op(l[0]), Text: l[1:]})
+		default:
+			return finishHunk(h, n)
+		}
+	}
+
+	return finishHunk(h, n)
+}
+
+func finishHunk(h Hunk, n int) (Hunk, int, error) {
+	// Blank lines after the hunk are not its context.
+	for len(h.Lines) > 0 && h.Lines[len(h.Lines)-1] == (Line{Op: Context, Text: "\n"}) {
+		h.Lines = h.Lines[:len(h.Lines)-1]
+	}
+
+	if len(h.Lines) == 0 {
+		return h, n, errors.New("empty hunk")
+	}
+
+	for _, l := range h.Lines {
+		if l.Op != Insert {
+			h.OldLines++
+		}
+
+		if l.Op != Delete {
+			h.NewLines++
+		}
+	}
+
+	return h, n, nil
+}
+
+// parseHunkHeader parses the starts of the "@@ -l,s +l,s @@" header.
+func parseHunkHeader(l string) (oldStart, newStart int, err error) {
+	fields := strings.Fields(l)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, fmt.Errorf("invalid hunk header %q", strings.TrimSpace(l))
+	}
+
+	oldStart, err = parseRangeStart(fields[1][1:])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	newStart, err = parseRangeStart(fields[2][1:])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return oldStart, newStart, nil
+}
+
+func parseRangeStart(r string) (int, error) {
+	start, _, _ := strings.Cut(r, ",")
+
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hunk range %q: %w", r, err)
+	}
+
+	return n, nil
+}
+
+// Apply applies the file patch to the text. Every hunk is searched for near its
+// expected position, first exactly, then ignoring white space and finally
+// ignoring up to [MaxFuzz] context lines at its edges. The matched context
+// lines keep their content from the text.
+//
+// The hunks that don't match are skipped and reported by the [*ConflictError]
+// together with the text with the rest of hunks applied.
+func Apply(text string, f File) (string, error) {
+	ls := SplitLines(text)
+
+	var conflicts []Conflict
+
+	// The shift of line numbers by the applied hunks and the end of the last one.
+	shift, done := 0, 0
+
+	for i, h := range f.Hunks {
+		at, lines, ok := place(ls, h, h.OldStart-1+shift, done)
+		if !ok {
+			conflicts = append(conflicts, Conflict{Index: i, Hunk: h})
+
+			continue
+		}
+
+		repl, n := replacement(ls[at:], lines)
+		ls = append(ls[:at:at], append(repl, ls[at+n:]...)...)
+		shift += len(repl) - n
+		done = at + len(repl)
+	}
+
+	text = join(ls)
+
+	if len(conflicts) > 0 {
+		return text, &ConflictError{Conflicts: conflicts}
+	}
+
+	return text, nil
+}
+
+// place finds the position of the hunk lines in the text nearest to the expected
+// one, and returns the hunk lines without the ignored context.
+func place(ls []string, h Hunk, expected, from int) (int, []Line, bool) {
+	if h.OldLines == 0 {
+		// A pure insertion has no lines to match, the start is the line before it.
+		at := min(max(expected+1, from), len(ls))
+
+		return at, h.Lines, true
+	}
+
+	for fuzz := 0; fuzz <= MaxFuzz; fuzz++ {
+		lines, ok := trimContext(h.Lines, fuzz)
+		if !ok {
+			break
+		}
+
+		for _, eq := range []func(a, b string) bool{equalLines, equalFields} {
+			if at, ok := search(ls, lines, expected+fuzz, from, eq); ok {
+				return at, lines, true
+			}
+		}
+	}
+
+	return 0, nil, false
+}
+
+// trimContext drops up to fuzz leading and trailing context lines.
+func trimContext(lines []Line, fuzz int) ([]Line, bool) {
+	if fuzz == 0 {
+		return lines, true
+	}
+
+	start, end := 0, len(lines)
+
+	for start < fuzz && start < end && lines[start].Op == Context {
+		start++
+	}
+
+	for len(lines)-end < fuzz && end > start && lines[end-1].Op == Context {
+		end--
+	}
+
+	if start == 0 && end == len(lines) {
+		return nil, false
+	}
+
+	lines = lines[start:end]
+
+	for _, l := range lines {
+		if l.Op != Insert {
+			return lines, true
+		}
+	}
+
+	// Nothing is left to anchor the hunk.
+	return nil, false
+}
+
+// search looks for the old lines of the hunk outward from the expected position.
+func search(ls []string, lines []Line, expected, from int, eq func(a, b string) bool) (int, bool) {
+	n := 0
+
+	for _, l := range lines {
+		if l.Op != Insert {
+			n++
+		}
+	}
+
+	last := len(ls) - n
+	expected = min(max(expected, from), last)
+
+	for d := 0; expected-d >= from || expected+d <= last; d++ {
+		if at := expected - d; at >= from && match(ls[at:], lines, eq) {
+			return at, true
+		}
+
+		if at := expected + d; d > 0 && at <= last && match(ls[at:], lines, eq) {
+			return at, true
+		}
+	}
+
+	return 0, false
+}
+
+func match(ls []string, lines []Line, eq func(a, b string) bool) bool {
+	i := 0
+
+	for _, l := range lines {
+		if l.Op == Insert {
+			continue
+		}
+
+		if !eq(ls[i], l.Text) {
+			return false
+		}
+
+		i++
+	}
+
+	return true
+}
+
+func equalLines(a, b string) bool {
+	return strings.TrimSuffix(a, "\n") == strings.TrimSuffix(b, "\n")
+}
+
+func equalFields(a, b string) bool {
+	return slices.Equal(strings.Fields(a), strings.Fields(b))
+}
+
+// replacement returns the new lines for the matched ones and the number of matched lines.
+func replacement(ls []string, lines []Line) ([]string, int) {
+	repl := []string{}
+	i := 0
+
+	for _, l := range lines {
+		switch l.Op {
+		case Context:
+			repl = append(repl, ls[i])
+			i++
+		case Delete:
+			i++
+		case Insert:
+			repl = append(repl, l.Text)
+		}
+	}
+
+	return repl, i
+}
+
+// join joins the lines adding line feeds lost in the middle of the text.
+func join(ls []string) string {
+	sb := strings.Builder{}
+
+	for i, l := range ls {
+		sb.WriteString(l)
+
+		if i < len(ls)-1 && !strings.HasSuffix(l, "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
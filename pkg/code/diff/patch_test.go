@@ -0,0 +1,209 @@
+package diff_test
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/code/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	patch := "diff --git a/main.go b/main.go\n" +
+		"index 83db48f..bf269f4 100644\n" +
+		"--- a/main.go\n" +
+		"+++ b/main.go\n" +
+		"@@ -1,3 +1,3 @@ package main\n" +
+		" a\n" +
+		"\n" +
+		"-b\n" +
+		"+B\n" +
+		"\n" +
+		"@@ -10,1 +10,1 @@\n" +
+		"-c\n" +
+		"\\ No newline at end of file\n" +
+		"+C\n" +
+		"--- /dev/null\n" +
+		"+++ b/new.go\n" +
+		"@@ -0,0 +1 @@\n" +
+		"+new\n"
+
+	files, err := diff.Parse(patch)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	assert.Equal(t, "main.go", files[0].Name())
+	assert.Equal(t, []diff.Hunk{
+		{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []diff.Line{
+			{Op: diff.Context, Text: "a\n"},
+			{Op: diff.Context, Text: "\n"},
+			{Op: diff.Delete, Text: "b\n"},
+			{Op: diff.Insert, Text: "B\n"},
+		}},
+		{OldStart: 10, OldLines: 1, NewStart: 10, NewLines: 1, Lines: []diff.Line{
+			{Op: diff.Delete, Text: "c"},
+			{Op: diff.Insert, Text: "C\n"},
+		}},
+	}, files[0].Hunks)
+
+	assert.Equal(t, "new.go", files[1].Name())
+	assert.Equal(t, []diff.Line{{Op: diff.Insert, Text: "new\n"}}, files[1].Hunks[0].Lines)
+}
+
+func TestParse_Malformed(t *testing.T) {
+	t.Parallel()
+
+	for _, patch := range []string{
+		"",
+		"@@ -1 +1 @@\n-a\n+b\n",
+		"--- a\n+++ b\n@@ -x +1 @@\n-a\n",
+		"--- a\n+++ b\n@@ -1 +1 @@\n",
+	} {
+		_, err := diff.Parse(patch)
+		require.ErrorIs(t, err, diff.ErrMalformed, patch)
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	const text = "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+
+	tests := []struct {
+		name  string
+		text  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "exact",
+			text:  text,
+			patch: "--- a\n+++ b\n@@ -4,3 +4,3 @@\n 4\n-5\n+five\n 6\n",
+			want:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+		},
+		{
+			name:  "shifted",
+			text:  "0\n0\n" + text,
+			patch: "--- a\n+++ b\n@@ -4,3 +4,3 @@\n 4\n-5\n+five\n 6\n",
+			want:  "0\n0\n1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+		},
+		{
+			name:  "wrong line numbers",
+			text:  text,
+			patch: "--- a\n+++ b\n@@ -100,3 +100,3 @@\n 7\n-8\n+eight\n 9\n",
+			want:  "1\n2\n3\n4\n5\n6\n7\neight\n9\n",
+		},
+		{
+			name:  "white space",
+			text:  "func f() {\n\treturn 1\n}\n",
+			patch: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n func f()  {\n-    return 1\n+\treturn 2\n }\n",
+			want:  "func f() {\n\treturn 2\n}\n",
+		},
+		{
+			name:  "fuzz",
+			text:  text,
+			patch: "--- a\n+++ b\n@@ -3,5 +3,5 @@\n three\n 4\n-5\n+five\n 6\n seven\n",
+			want:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+		},
+		{
+			name:  "pure insertion",
+			text:  text,
+			patch: "--- a\n+++ b\n@@ -2,0 +3 @@\n+2.5\n",
+			want:  "1\n2\n2.5\n3\n4\n5\n6\n7\n8\n9\n",
+		},
+		{
+			name:  "into empty",
+			text:  "",
+			patch: "--- /dev/null\n+++ b\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+			want:  "a\nb\n",
+		},
+		{
+			name:  "add newline at end of file",
+			text:  "a\nb",
+			patch: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+			want:  "a\nb\n",
+		},
+		{
+			name: "several hunks",
+			text: text,
+			patch: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n 1\n+1.5\n 2\n" +
+				"@@ -8,2 +9,1 @@\n 8\n-9\n",
+			want: "1\n1.5\n2\n3\n4\n5\n6\n7\n8\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, err := diff.Parse(tt.patch)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			got, err := diff.Apply(tt.text, files[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApply_Conflict(t *testing.T) {
+	t.Parallel()
+
+	files, err := diff.Parse("--- a\n+++ b\n" +
+		"@@ -1,2 +1,2 @@\n-x\n+X\n 2\n" +
+		"@@ -5,1 +5,1 @@\n-5\n+five\n")
+	require.NoError(t, err)
+
+	got, err := diff.Apply("1\n2\n3\n4\n5\n", files[0])
+
+	var conflictErr *diff.ConflictError
+
+	require.ErrorAs(t, err, &conflictErr)
+	require.ErrorIs(t, err, diff.ErrConflict)
+	assert.Equal(t, "1\n2\n3\n4\nfive\n", got)
+	require.Len(t, conflictErr.Conflicts, 1)
+	assert.Equal(t, 0, conflictErr.Conflicts[0].Index)
+	assert.Contains(t, err.Error(), "hunk #1 @@ -1,2 +1,2 @@ doesn't match:\n-x\n 2")
+}
+
+func TestApply_Unified(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // Reproducible test data.
+
+	randomText := func() string {
+		sb := strings.Builder{}
+
+		for range rnd.IntN(20) {
+			sb.WriteString(strconv.Itoa(rnd.IntN(5)) + "\n")
+		}
+
+		if sb.Len() > 0 && rnd.IntN(4) == 0 {
+			return strings.TrimSuffix(sb.String(), "\n")
+		}
+
+		return sb.String()
+	}
+
+	for range 500 {
+		oldText, newText := randomText(), randomText()
+
+		patch := diff.Unified("a", "b", oldText, newText)
+		if patch == "" {
+			continue
+		}
+
+		files, err := diff.Parse(patch)
+		require.NoError(t, err, patch)
+
+		got, err := diff.Apply(oldText, files[0])
+		require.NoError(t, err, patch)
+		assert.Equal(t, newText, got, patch)
+	}
+}
@@ -0,0 +1,343 @@
+// Package goedit edits Go source by symbols instead of line numbers.
+//
+// The edits find the positions to change in the syntax tree and splice the source
+// text there, so the comments stay where they are, and then gofmt the result.
+package goedit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/pkg/code/golang"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+var (
+	ErrInvalidSource = errors.New("invalid Go source")
+	ErrNotFound      = errors.New("not found")
+	ErrExists        = errors.New("already exists")
+	ErrNoTable       = errors.New("no table of test cases")
+)
+
+// ReplaceBody replaces the body of the function or method named "Type.Method".
+// The body is the list of statements, or the block with the braces.
+func ReplaceBody(src []byte, name, body string) ([]byte, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := findFunc(file, name)
+	if fn == nil || fn.Body == nil {
+		return nil, fmt.Errorf("function with body %s: %w", name, ErrNotFound)
+	}
+
+	block := strings.TrimSpace(body)
+	if !strings.HasPrefix(block, "{") || !strings.HasSuffix(block, "}") {
+		block = "{\n" + body + "\n}"
+	}
+
+	return gofmt(splice(src, offset(fset, fn.Body.Lbrace), offset(fset, fn.Body.Rbrace)+1, block))
+}
+
+// AddMethod adds the method after the last method of its receiver type, or after the type.
+// The method is the source of the method declaration with its doc comment.
+func AddMethod(src []byte, method string) ([]byte, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	decl, err := parseMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	typeName := golang.ReceiverName(decl.Recv.List[0].Type)
+
+	spec, gen := findType(file, typeName)
+	if spec == nil {
+		return nil, fmt.Errorf("type %s: %w", typeName, ErrNotFound)
+	}
+
+	if findFunc(file, typeName+"."+decl.Name.Name) != nil || hasField(spec, decl.Name.Name) {
+		return nil, fmt.Errorf("%s.%s: %w", typeName, decl.Name.Name, ErrExists)
+	}
+
+	end := gen.End()
+
+	for _, d := range file.Decls {
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv != nil && golang.ReceiverName(fn.Recv.List[0].Type) == typeName {
+			end = max(end, fn.End())
+		}
+	}
+
+	at := offset(fset, end)
+
+	return gofmt(splice(src, at, at, "\n\n"+strings.TrimSpace(method)+"\n"))
+}
+
+// AddField adds the field declarations, like "Name string `json:\"name\"`", to the end
+// of the struct type.
+func AddField(src []byte, typeName, field string) ([]byte, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, _ := findType(file, typeName)
+	if spec == nil {
+		return nil, fmt.Errorf("type %s: %w", typeName, ErrNotFound)
+	}
+
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("struct type %s: %w", typeName, ErrNotFound)
+	}
+
+	fields, err := parseFields(field)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		for _, name := range f.Names {
+			if hasField(spec, name.Name) {
+				return nil, fmt.Errorf("field %s.%s: %w", typeName, name.Name, ErrExists)
+			}
+		}
+	}
+
+	return gofmt(insertLine(src, offset(fset, st.Fields.Closing), strings.TrimSpace(field)))
+}
+
+// AddImport adds the import of the path with the name, which is empty for the default one.
+// The source is returned unchanged if the path is imported already.
+func AddImport(src []byte, path, name string) ([]byte, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if !astutil.AddNamedImport(fset, file, name, path) {
+		return src, nil
+	}
+
+	buf := bytes.Buffer{}
+
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSource, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// AddTestCase appends the test case, the composite literal of an element, to the first
+// slice or map literal of the test function, which is its table of test cases.
+func AddTestCase(src []byte, testName, testCase string) ([]byte, error) {
+	fset, file, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := findFunc(file, testName)
+	if fn == nil || fn.Body == nil {
+		return nil, fmt.Errorf("test %s: %w", testName, ErrNotFound)
+	}
+
+	table := findTable(fn.Body)
+	if table == nil {
+		return nil, fmt.Errorf("%s: %w", testName, ErrNoTable)
+	}
+
+	testCase = strings.TrimSuffix(strings.TrimSpace(testCase), ",") + ","
+
+	if _, err := parser.ParseExpr("[]T{\n" + testCase + "\n}"); err != nil {
+		return nil, fmt.Errorf("%w: test case: %w", ErrInvalidSource, err)
+	}
+
+	closing := offset(fset, table.Rbrace)
+
+	// The last element on the line of the closing brace has no comma.
+	if n := len(table.Elts); n > 0 {
+		last := offset(fset, table.Elts[n-1].End())
+
+		if !bytes.Contains(src[last:closing], []byte(",")) {
+			src = splice(src, last, last, ",")
+			closing++
+		}
+	}
+
+	return gofmt(insertLine(src, closing, testCase))
+}
+
+// findTable returns the first slice or map literal of composite literals.
+func findTable(body *ast.BlockStmt) *ast.CompositeLit {
+	var table *ast.CompositeLit
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || table != nil {
+			return table == nil
+		}
+
+		switch lit.Type.(type) {
+		case *ast.ArrayType, *ast.MapType:
+		default:
+			return true
+		}
+
+		for _, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				elt = kv.Value
+			}
+
+			if _, ok := elt.(*ast.CompositeLit); !ok {
+				return true
+			}
+		}
+
+		table = lit
+
+		return false
+	})
+
+	return table
+}
+
+func parse(src []byte) (*token.FileSet, *ast.File, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidSource, err)
+	}
+
+	return fset, file, nil
+}
+
+func parseMethod(method string) (*ast.FuncDecl, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+method, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("%w: method: %w", ErrInvalidSource, err)
+	}
+
+	if len(file.Decls) != 1 {
+		return nil, fmt.Errorf("%w: method: want one declaration, got %d", ErrInvalidSource, len(file.Decls))
+	}
+
+	decl, ok := file.Decls[0].(*ast.FuncDecl)
+	if !ok || decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return nil, fmt.Errorf("%w: method: not a method declaration", ErrInvalidSource)
+	}
+
+	return decl, nil
+}
+
+func parseFields(field string) ([]*ast.Field, error) {
+	expr, err := parser.ParseExpr("struct {\n" + field + "\n}")
+	if err != nil {
+		return nil, fmt.Errorf("%w: field: %w", ErrInvalidSource, err)
+	}
+
+	st, ok := expr.(*ast.StructType)
+	if !ok || len(st.Fields.List) == 0 {
+		return nil, fmt.Errorf("%w: field: no fields", ErrInvalidSource)
+	}
+
+	return st.Fields.List, nil
+}
+
+// findFunc finds the function or the method named "Type.Method".
+func findFunc(file *ast.File, name string) *ast.FuncDecl {
+	typeName, funcName, isMethod := strings.Cut(name, ".")
+	if !isMethod {
+		typeName, funcName = "", name
+	}
+
+	for _, d := range file.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != funcName {
+			continue
+		}
+
+		hasRecv := fn.Recv != nil && len(fn.Recv.List) > 0
+
+		switch {
+		case isMethod && hasRecv && golang.ReceiverName(fn.Recv.List[0].Type) == typeName:
+			return fn
+		case !isMethod && !hasRecv:
+			return fn
+		}
+	}
+
+	return nil
+}
+
+func findType(file *ast.File, name string) (*ast.TypeSpec, *ast.GenDecl) {
+	for _, d := range file.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			if spec, ok := spec.(*ast.TypeSpec); ok && spec.Name.Name == name {
+				return spec, gen
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func hasField(spec *ast.TypeSpec, name string) bool {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return false
+	}
+
+	for _, f := range st.Fields.List {
+		for _, n := range f.Names {
+			if n.Name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Offset
+}
+
+func splice(src []byte, start, end int, text string) []byte {
+	return bytes.Join([][]byte{src[:start], []byte(text), src[end:]}, nil)
+}
+
+// insertLine inserts the text on its own line before the closing brace at the offset.
+func insertLine(src []byte, closing int, text string) []byte {
+	before := bytes.TrimRight(src[:closing], " \t")
+
+	if bytes.HasSuffix(before, []byte("\n")) {
+		return splice(src, len(before), closing, text+"\n")
+	}
+
+	return splice(src, closing, closing, "\n"+text+"\n")
+}
+
+func gofmt(src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: result: %w", ErrInvalidSource, err)
+	}
+
+	return formatted, nil
+}
@@ -0,0 +1,190 @@
+package goedit_test
+
+import (
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/code/goedit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const src = `package geo
+
+import "math"
+
+// Point is a point on the plane.
+type Point struct {
+	X, Y float64 // Coordinates.
+}
+
+// Dist returns the distance to q.
+func (p Point) Dist(q Point) float64 {
+	// Pythagoras.
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+type Empty struct{}
+
+func Origin() Point {
+	return Point{}
+}
+`
+
+func TestReplaceBody(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fn      string
+		body    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "function",
+			fn:   "Origin",
+			body: "return Point{X: 0, Y: 0}",
+			want: "func Origin() Point {\n\treturn Point{X: 0, Y: 0}\n}\n",
+		},
+		{
+			name: "method with braces",
+			fn:   "Point.Dist",
+			body: "{\n// Manhattan.\nreturn math.Abs(p.X-q.X) + math.Abs(p.Y-q.Y)\n}",
+			want: "// Dist returns the distance to q.\nfunc (p Point) Dist(q Point) float64 {\n" +
+				"\t// Manhattan.\n\treturn math.Abs(p.X-q.X) + math.Abs(p.Y-q.Y)\n}\n",
+		},
+		{name: "method as function", fn: "Dist", body: "return 0", wantErr: goedit.ErrNotFound},
+		{name: "missing", fn: "Missing", body: "return", wantErr: goedit.ErrNotFound},
+		{name: "invalid body", fn: "Origin", body: "return {", wantErr: goedit.ErrInvalidSource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := goedit.ReplaceBody([]byte(src), tt.fn, tt.body)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, string(got), tt.want)
+			assert.Contains(t, string(got), "X, Y float64 // Coordinates.")
+		})
+	}
+}
+
+func TestAddMethod(t *testing.T) {
+	t.Parallel()
+
+	got, err := goedit.AddMethod([]byte(src), "// Scale multiplies the coordinates.\nfunc (p *Point) Scale(k float64) {\n"+
+		"p.X *= k\np.Y *= k\n}")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "return math.Hypot(p.X-q.X, p.Y-q.Y)\n}\n\n"+
+		"// Scale multiplies the coordinates.\nfunc (p *Point) Scale(k float64) {\n\tp.X *= k\n\tp.Y *= k\n}\n\n"+
+		"type Empty struct{}\n")
+
+	got, err = goedit.AddMethod([]byte(src), "func (Empty) String() string { return \"\" }")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "type Empty struct{}\n\nfunc (Empty) String() string { return \"\" }\n\nfunc Origin")
+
+	_, err = goedit.AddMethod([]byte(src), "func (p Point) Dist(q Point) float64 { return 0 }")
+	require.ErrorIs(t, err, goedit.ErrExists)
+
+	_, err = goedit.AddMethod([]byte(src), "func (p Point) X() float64 { return 0 }")
+	require.ErrorIs(t, err, goedit.ErrExists)
+
+	_, err = goedit.AddMethod([]byte(src), "func (l Line) Len() float64 { return 0 }")
+	require.ErrorIs(t, err, goedit.ErrNotFound)
+
+	_, err = goedit.AddMethod([]byte(src), "func Len() float64 { return 0 }")
+	require.ErrorIs(t, err, goedit.ErrInvalidSource)
+}
+
+func TestAddField(t *testing.T) {
+	t.Parallel()
+
+	got, err := goedit.AddField([]byte(src), "Point", "// Z is the height.\nZ float64 `json:\"z\"`")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "type Point struct {\n\tX, Y float64 // Coordinates.\n"+
+		"\t// Z is the height.\n\tZ float64 `json:\"z\"`\n}\n")
+
+	got, err = goedit.AddField([]byte(src), "Empty", "N int")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "type Empty struct {\n\tN int\n}\n")
+
+	_, err = goedit.AddField([]byte(src), "Point", "Y int")
+	require.ErrorIs(t, err, goedit.ErrExists)
+
+	_, err = goedit.AddField([]byte(src), "Line", "N int")
+	require.ErrorIs(t, err, goedit.ErrNotFound)
+
+	_, err = goedit.AddField([]byte(src), "Point", "func")
+	require.ErrorIs(t, err, goedit.ErrInvalidSource)
+}
+
+func TestAddImport(t *testing.T) {
+	t.Parallel()
+
+	got, err := goedit.AddImport([]byte(src), "fmt", "")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "import (\n\t\"fmt\"\n\t\"math\"\n)\n\n// Point is a point on the plane.\n")
+
+	got, err = goedit.AddImport([]byte(src), "math/rand/v2", "rand")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "\trand \"math/rand/v2\"\n")
+
+	got, err = goedit.AddImport([]byte(src), "math", "")
+	require.NoError(t, err)
+	assert.Equal(t, src, string(got))
+}
+
+func TestAddTestCase(t *testing.T) {
+	t.Parallel()
+
+	const test = `package geo
+
+func TestDist(t *testing.T) {
+	tests := []struct {
+		name string
+		p, q Point
+		want float64
+	}{
+		{"zero", Point{}, Point{}, 0},
+		{"x", Point{}, Point{X: 1}, 1}}
+
+	for _, tt := range tests {
+		_ = tt
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	tests := map[string]struct{ want Point }{
+		"zero": {want: Point{}},
+	}
+
+	_ = tests
+}
+
+func TestNoTable(t *testing.T) {
+	values := []int{1, 2}
+	_ = values
+}
+`
+
+	got, err := goedit.AddTestCase([]byte(test), "TestDist", `{"y", Point{}, Point{Y: 2}, 2}`)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "\t\t{\"x\", Point{}, Point{X: 1}, 1},\n\t\t{\"y\", Point{}, Point{Y: 2}, 2},\n\t}\n")
+
+	got, err = goedit.AddTestCase([]byte(test), "TestOrigin", `"one": {want: Point{X: 1}},`)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "\t\t\"zero\": {want: Point{}},\n\t\t\"one\":  {want: Point{X: 1}},\n\t}\n")
+
+	_, err = goedit.AddTestCase([]byte(test), "TestNoTable", "{3}")
+	require.ErrorIs(t, err, goedit.ErrNoTable)
+
+	_, err = goedit.AddTestCase([]byte(test), "TestDist", "{")
+	require.ErrorIs(t, err, goedit.ErrInvalidSource)
+}
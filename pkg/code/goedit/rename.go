@@ -0,0 +1,117 @@
+package goedit
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/pkg/code/golang"
+)
+
+var (
+	ErrInvalidName     = errors.New("invalid identifier")
+	ErrStale           = errors.New("module is outdated")
+	ErrInterfaceMethod = errors.New("method is required by an interface")
+)
+
+// Rename renames the symbol, e.g. the struct field "Type.Field", and all its uses in
+// the module. It returns the new sources of the changed files by their paths relative
+// to the module root, the files are not written.
+//
+// Methods required by interfaces of the module can't be renamed, since the interfaces
+// and their other implementations would have to be renamed too.
+func Rename(module *golang.Module, name, newName string) (map[string][]byte, error) {
+	if !token.IsIdentifier(newName) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, newName)
+	}
+
+	sym, err := module.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("lookup: %w", err)
+	}
+
+	if sym.Kind == golang.KindMethod {
+		ifaces, err := module.Interfaces(sym.QualifiedName())
+		if err != nil {
+			return nil, fmt.Errorf("interfaces: %w", err)
+		}
+
+		if len(ifaces) > 0 {
+			return nil, fmt.Errorf("%w: %s is required by %s",
+				ErrInterfaceMethod, sym.QualifiedName(), ifaces[0].QualifiedName())
+		}
+	}
+
+	prefix, oldName := "", sym.Name
+	if i := strings.LastIndex(sym.Name, "."); i >= 0 {
+		prefix, oldName = sym.Name[:i+1], sym.Name[i+1:]
+	}
+
+	target := sym.Package + "." + prefix + newName
+
+	for _, s := range module.Find(target) {
+		if s.QualifiedName() == target {
+			return nil, fmt.Errorf("%s: %w", target, ErrExists)
+		}
+	}
+
+	ids, err := module.Identifiers(sym.QualifiedName())
+	if err != nil {
+		return nil, fmt.Errorf("identifiers: %w", err)
+	}
+
+	byFile := map[string][]golang.Location{}
+
+	for _, id := range ids {
+		byFile[id.File] = append(byFile[id.File], id)
+	}
+
+	result := make(map[string][]byte, len(byFile))
+
+	for file, locs := range byFile {
+		src, err := os.ReadFile(filepath.Join(module.Root(), filepath.FromSlash(file)))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+
+		lineStarts := lineOffsets(src)
+
+		// The identifiers are sorted, so the later ones are replaced first.
+		for _, loc := range slices.Backward(locs) {
+			if loc.Line > len(lineStarts) {
+				return nil, fmt.Errorf("%w: %s is out of the file", ErrStale, loc)
+			}
+
+			at := lineStarts[loc.Line-1] + loc.Column - 1
+
+			if at > len(src) || !strings.HasPrefix(string(src[at:]), oldName) {
+				return nil, fmt.Errorf("%w: %s has no %s", ErrStale, loc, oldName)
+			}
+
+			src = splice(src, at, at+len(oldName), newName)
+		}
+
+		if result[file], err = gofmt(src); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return result, nil
+}
+
+// lineOffsets returns the offsets of the beginnings of lines.
+func lineOffsets(src []byte) []int {
+	offsets := []int{0}
+
+	for i, b := range src {
+		if b == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+
+	return offsets
+}
@@ -0,0 +1,12 @@
+// Package geo works with points.
+package geo
+
+// Point is a point on the plane.
+type Point struct {
+	X, Y int
+}
+
+// Add returns the sum of the points.
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
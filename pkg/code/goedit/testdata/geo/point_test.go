@@ -0,0 +1,19 @@
+package geo
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		p, q Point
+		want Point
+	}{
+		{"zero", Point{}, Point{}, Point{}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.p.Add(tt.q); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Package route moves along points.
+package route
+
+import "example.com/geo"
+
+// Mover moves a point.
+type Mover interface {
+	Add(q geo.Point) geo.Point
+}
+
+// Length returns the Manhattan length of the route.
+func Length(points ...geo.Point) int {
+	n := 0
+
+	for i := 1; i < len(points); i++ {
+		n += abs(points[i].X-points[i-1].X) + abs(points[i].Y-points[i-1].Y)
+	}
+
+	return n
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,438 @@
+package goedit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/WinPooh32/go-coder/pkg/code/diff"
+	"github.com/WinPooh32/go-coder/pkg/code/golang"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/tools"
+)
+
+var ErrOutsideModule = errors.New("file is outside of the module")
+
+// Workspace edits the Go files of the module at the root directory. It finds
+// the files by the names of symbols in the loaded module and loads it again after
+// it changes the files. Changes made outside of the workspace are not tracked.
+type Workspace struct {
+	root string
+	opts []golang.Option
+
+	mu     sync.Mutex
+	module *golang.Module
+}
+
+// NewWorkspace returns the workspace of the module at the root. The module is loaded
+// with its tests, so test functions can be edited too, and with type errors, which are
+// common in the middle of editing, unless the options say otherwise.
+func NewWorkspace(root string, opts ...golang.Option) *Workspace {
+	return &Workspace{
+		root:   root,
+		opts:   append([]golang.Option{golang.WithTests(true), golang.WithTypeErrors(true)}, opts...),
+		mu:     sync.Mutex{},
+		module: nil,
+	}
+}
+
+// Module returns the module loading it if the files were changed by the workspace since the last load.
+func (w *Workspace) Module(ctx context.Context) (*golang.Module, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.module != nil {
+		return w.module, nil
+	}
+
+	module, err := golang.Load(ctx, w.root, w.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load module: %w", err)
+	}
+
+	w.module = module
+
+	return module, nil
+}
+
+// ReplaceBody replaces the body of the function or method and returns the diff of the change.
+func (w *Workspace) ReplaceBody(ctx context.Context, name, body string) (string, error) {
+	return w.editSymbol(ctx, name, func(sym golang.Symbol, src []byte) ([]byte, error) {
+		return ReplaceBody(src, sym.Name, body)
+	}, golang.KindFunc, golang.KindMethod)
+}
+
+// AddMethod adds the method to the file of its receiver type and returns the diff of the change.
+func (w *Workspace) AddMethod(ctx context.Context, typeName, method string) (string, error) {
+	decl, err := parseMethod(method)
+	if err != nil {
+		return "", err
+	}
+
+	if recv := golang.ReceiverName(decl.Recv.List[0].Type); !strings.HasSuffix("."+typeName, "."+recv) {
+		return "", fmt.Errorf("%w: the receiver of the method is %s, not %s", ErrInvalidSource, recv, typeName)
+	}
+
+	return w.editSymbol(ctx, typeName, func(_ golang.Symbol, src []byte) ([]byte, error) {
+		return AddMethod(src, method)
+	}, golang.KindType)
+}
+
+// AddField adds the field to the struct type and returns the diff of the change.
+func (w *Workspace) AddField(ctx context.Context, typeName, field string) (string, error) {
+	return w.editSymbol(ctx, typeName, func(sym golang.Symbol, src []byte) ([]byte, error) {
+		return AddField(src, sym.Name, field)
+	}, golang.KindType)
+}
+
+// AddTestCase adds the test case to the table of the test and returns the diff of the change.
+func (w *Workspace) AddTestCase(ctx context.Context, testName, testCase string) (string, error) {
+	return w.editSymbol(ctx, testName, func(sym golang.Symbol, src []byte) ([]byte, error) {
+		return AddTestCase(src, sym.Name, testCase)
+	}, golang.KindFunc)
+}
+
+// AddImport adds the import to the file relative to the root and returns the diff of the change.
+func (w *Workspace) AddImport(_ context.Context, file, path, name string) (string, error) {
+	return w.editFiles(func(read func(file string) ([]byte, error)) (map[string][]byte, error) {
+		src, err := read(file)
+		if err != nil {
+			return nil, err
+		}
+
+		src, err = AddImport(src, path, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string][]byte{file: src}, nil
+	})
+}
+
+// Rename renames the symbol and its uses in the module and returns the diff of the change.
+func (w *Workspace) Rename(ctx context.Context, name, newName string) (string, error) {
+	module, err := w.Module(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return w.editFiles(func(func(file string) ([]byte, error)) (map[string][]byte, error) {
+		return Rename(module, name, newName)
+	})
+}
+
+// editSymbol applies the edit to the file of the only symbol of the kinds matching the name.
+func (w *Workspace) editSymbol(
+	ctx context.Context, name string, edit func(sym golang.Symbol, src []byte) ([]byte, error), kinds ...golang.Kind,
+) (string, error) {
+	module, err := w.Module(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sym, err := module.Lookup(name)
+	if err != nil {
+		return "", fmt.Errorf("lookup: %w", err)
+	}
+
+	if !slices.Contains(kinds, sym.Kind) {
+		return "", fmt.Errorf("%w: %s is a %s", ErrNotFound, sym.QualifiedName(), sym.Kind)
+	}
+
+	return w.editFiles(func(read func(file string) ([]byte, error)) (map[string][]byte, error) {
+		src, err := read(sym.Range.File)
+		if err != nil {
+			return nil, err
+		}
+
+		src, err = edit(sym, src)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string][]byte{sym.Range.File: src}, nil
+	})
+}
+
+// editFiles writes the changed files and returns the diff of the changes.
+func (w *Workspace) editFiles(
+	edit func(read func(file string) ([]byte, error)) (map[string][]byte, error),
+) (string, error) {
+	original := map[string][]byte{}
+
+	read := func(file string) ([]byte, error) {
+		if !filepath.IsLocal(filepath.FromSlash(file)) {
+			return nil, fmt.Errorf("%w: %s", ErrOutsideModule, file)
+		}
+
+		src, err := os.ReadFile(w.path(file))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+
+		original[file] = src
+
+		return src, nil
+	}
+
+	changed, err := edit(read)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	files := map[string][]byte{}
+
+	for _, file := range slices.Sorted(maps.Keys(changed)) {
+		old, ok := original[file]
+		if !ok {
+			if old, err = read(file); err != nil {
+				return "", err
+			}
+		}
+
+		if string(old) == string(changed[file]) {
+			continue
+		}
+
+		files[file] = changed[file]
+
+		sb.WriteString(diff.Unified("a/"+file, "b/"+file, string(old), string(changed[file])))
+	}
+
+	if sb.Len() == 0 {
+		return "no changes", nil
+	}
+
+	if err := w.write(files, original); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// write replaces the files with their new sources. The sources are written to temporary files first
+// and renamed over the files only if all of them are written. If a rename fails, the files already
+// replaced are restored from the original sources, so the files are changed all together or not at all.
+func (w *Workspace) write(files, original map[string][]byte) error {
+	w.mu.Lock()
+	w.module = nil
+	w.mu.Unlock()
+
+	temps := make(map[string]string, len(files))
+
+	defer func() {
+		for _, tmp := range temps {
+			os.Remove(tmp)
+		}
+	}()
+
+	for file, src := range files {
+		tmp, err := writeTemp(w.path(file), src)
+		if err != nil {
+			return fmt.Errorf("write %s: %w", file, err)
+		}
+
+		temps[file] = tmp
+	}
+
+	var renamed []string
+
+	for _, file := range slices.Sorted(maps.Keys(temps)) {
+		if err := os.Rename(temps[file], w.path(file)); err != nil {
+			return errors.Join(fmt.Errorf("write %s: %w", file, err), w.restore(renamed, original))
+		}
+
+		delete(temps, file)
+
+		renamed = append(renamed, file)
+	}
+
+	return nil
+}
+
+// restore writes the original sources of the files back.
+func (w *Workspace) restore(files []string, original map[string][]byte) error {
+	var errs []error
+
+	for _, file := range files {
+		tmp, err := writeTemp(w.path(file), original[file])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", file, err))
+
+			continue
+		}
+
+		if err := os.Rename(tmp, w.path(file)); err != nil {
+			os.Remove(tmp)
+
+			errs = append(errs, fmt.Errorf("restore %s: %w", file, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeTemp writes the source to a temporary file next to the file with the same permissions
+// and returns the name of the temporary file.
+func writeTemp(name string, src []byte) (string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return "", fmt.Errorf("stat: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+
+	tmp := f.Name()
+
+	_, err = f.Write(src)
+	if err == nil {
+		err = f.Chmod(info.Mode().Perm())
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+
+		return "", fmt.Errorf("write temp file %q: %w", tmp, err)
+	}
+
+	return tmp, nil
+}
+
+func (w *Workspace) path(file string) string {
+	return filepath.Join(w.root, filepath.FromSlash(file))
+}
+
+// Tools returns the tools editing the Go files of the workspace. They reply with
+// the diffs of the changes.
+func (w *Workspace) Tools() []tools.Tool {
+	return []tools.Tool{
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_replace_body",
+				Description: "Replace the body of a Go function or method.",
+				Parameters: parameters(
+					"name", golang.NameDescription,
+					"body", "New statements of the body.",
+				),
+			},
+			Handler: func(ctx context.Context, args tools.Args) (string, error) {
+				return call2(ctx, args, "name", "body", w.ReplaceBody)
+			},
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_add_method",
+				Description: "Add a method to a Go type, next to its other methods.",
+				Parameters: parameters(
+					"type", "Name of the type, like \"shapes.Square\".",
+					"method", "Source of the method declaration with its doc comment.",
+				),
+			},
+			Handler: func(ctx context.Context, args tools.Args) (string, error) {
+				return call2(ctx, args, "type", "method", w.AddMethod)
+			},
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_add_field",
+				Description: "Add a field to a Go struct type.",
+				Parameters: parameters(
+					"type", "Name of the struct type, like \"shapes.Square\".",
+					"field", "Field declaration with its doc comment and tag.",
+				),
+			},
+			Handler: func(ctx context.Context, args tools.Args) (string, error) {
+				return call2(ctx, args, "type", "field", w.AddField)
+			},
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_rename",
+				Description: "Rename a Go type, function, method or struct field and all its uses.",
+				Parameters: parameters(
+					"name", golang.NameDescription,
+					"new_name", "New name without the package and type.",
+				),
+			},
+			Handler: func(ctx context.Context, args tools.Args) (string, error) {
+				return call2(ctx, args, "name", "new_name", w.Rename)
+			},
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_add_import",
+				Description: "Add an import to a Go file.",
+				Parameters: parameters(
+					"file", "Path of the file relative to the project root.",
+					"path", "Import path.",
+				),
+			},
+			Handler: func(ctx context.Context, args tools.Args) (string, error) {
+				return call2(ctx, args, "file", "path", func(ctx context.Context, file, path string) (string, error) {
+					return w.AddImport(ctx, file, path, "")
+				})
+			},
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_add_test_case",
+				Description: "Add a test case to the table of a table-driven Go test.",
+				Parameters: parameters(
+					"test", "Name of the test function, like \"shapes.TestTotal\".",
+					"case", "Composite literal of the test case written like the other cases.",
+				),
+			},
+			Handler: func(ctx context.Context, args tools.Args) (string, error) {
+				return call2(ctx, args, "test", "case", w.AddTestCase)
+			},
+		},
+	}
+}
+
+// parameters returns the required string parameters from pairs of names and descriptions.
+func parameters(namesAndDescriptions ...string) map[string]llm.FunctionProperty {
+	params := map[string]llm.FunctionProperty{}
+
+	for i := 0; i+1 < len(namesAndDescriptions); i += 2 {
+		params[namesAndDescriptions[i]] = llm.FunctionProperty{
+			Type:          llm.String,
+			ArrayItemType: 0,
+			Description:   namesAndDescriptions[i+1],
+			Enum:          nil,
+			Required:      true,
+		}
+	}
+
+	return params
+}
+
+// call2 calls the method with two string arguments.
+func call2(
+	ctx context.Context, args tools.Args, a, b string, method func(ctx context.Context, a, b string) (string, error),
+) (string, error) {
+	first, err := args.String(a)
+	if err != nil {
+		return "", fmt.Errorf("parse arguments: %w", err)
+	}
+
+	second, err := args.String(b)
+	if err != nil {
+		return "", fmt.Errorf("parse arguments: %w", err)
+	}
+
+	return method(ctx, first, second)
+}
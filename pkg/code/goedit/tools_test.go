@@ -0,0 +1,114 @@
+package goedit_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/code/goedit"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspace_Tools(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, os.DirFS("testdata/geo")))
+
+	set, err := tools.NewSet(goedit.NewWorkspace(root).Tools()...)
+	require.NoError(t, err)
+
+	call := func(name string, args map[string]any) string {
+		t.Helper()
+
+		got, err := set.Call(ctx, llm.ToolCallFunction{Name: name, Arguments: args})
+		require.NoError(t, err)
+
+		return got
+	}
+
+	got := call("go_rename", map[string]any{"name": "geo.Point.X", "new_name": "Lat"})
+	assert.Contains(t, got, "--- a/point.go\n+++ b/point.go\n")
+	assert.Contains(t, got, "+\tLat, Y int\n")
+	assert.Contains(t, got, "+\treturn Point{Lat: p.Lat + q.Lat, Y: p.Y + q.Y}\n")
+	assert.Contains(t, got, "--- a/route/route.go\n+++ b/route/route.go\n")
+
+	// The module is loaded again, so the new name is found.
+	got = call("go_replace_body", map[string]any{"name": "Point.Add", "body": "return Point{Lat: p.Lat + q.Lat}"})
+	assert.Contains(t, got, "+\treturn Point{Lat: p.Lat + q.Lat}\n")
+
+	call("go_add_field", map[string]any{"type": "Point", "field": "// Z is the height.\nZ int"})
+	call("go_add_method", map[string]any{
+		"type":   "geo.Point",
+		"method": "func (p Point) Zero() bool {\nreturn p == Point{}\n}",
+	})
+	call("go_add_import", map[string]any{"file": "route/route.go", "path": "fmt"})
+	call("go_add_test_case", map[string]any{"test": "TestAdd", "case": `{"one", Point{Lat: 1}, Point{}, Point{Lat: 1}}`})
+
+	assert.Equal(t, "no changes", call("go_add_import", map[string]any{"file": "route/route.go", "path": "fmt"}))
+
+	_, err = set.Call(ctx, llm.ToolCallFunction{
+		Name:      "go_add_import",
+		Arguments: map[string]any{"file": "../outside.go", "path": "fmt"},
+	})
+	require.ErrorIs(t, err, goedit.ErrOutsideModule)
+
+	_, err = set.Call(ctx, llm.ToolCallFunction{
+		Name:      "go_add_field",
+		Arguments: map[string]any{"type": "Point.Add", "field": "N int"},
+	})
+	require.ErrorIs(t, err, goedit.ErrNotFound)
+
+	_, err = set.Call(ctx, llm.ToolCallFunction{
+		Name:      "go_add_method",
+		Arguments: map[string]any{"type": "route.Mover", "method": "func (m Mover) Stop() {}"},
+	})
+	require.ErrorIs(t, err, goedit.ErrNotFound)
+
+	_, err = set.Call(ctx, llm.ToolCallFunction{
+		Name:      "go_rename",
+		Arguments: map[string]any{"name": "Point.Add", "new_name": "Plus"},
+	})
+	require.ErrorIs(t, err, goedit.ErrInterfaceMethod)
+
+	point, err := os.ReadFile(filepath.Join(root, "point.go"))
+	require.NoError(t, err)
+	assert.Equal(t, `// Package geo works with points.
+package geo
+
+// Point is a point on the plane.
+type Point struct {
+	Lat, Y int
+	// Z is the height.
+	Z int
+}
+
+// Add returns the sum of the points.
+func (p Point) Add(q Point) Point {
+	return Point{Lat: p.Lat + q.Lat}
+}
+
+func (p Point) Zero() bool {
+	return p == Point{}
+}
+`, string(point))
+
+	for _, pattern := range []string{".*.go.*", "*/.*.go.*"} {
+		temps, err := filepath.Glob(filepath.Join(root, pattern))
+		require.NoError(t, err)
+		assert.Empty(t, temps, "temporary files must be removed")
+	}
+
+	// The route package doesn't use fmt, so only the tests are vetted.
+	cmd := exec.CommandContext(ctx, "go", "vet", ".")
+	cmd.Dir = root
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
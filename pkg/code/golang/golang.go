@@ -0,0 +1,427 @@
+// Package golang loads Go modules and answers questions about their code:
+// which packages and symbols they have, where the symbols are defined and used,
+// and which types implement an interface.
+package golang
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var (
+	ErrLoad            = errors.New("load packages")
+	ErrPackageNotFound = errors.New("package not found")
+	ErrSymbolNotFound  = errors.New("symbol not found")
+	ErrAmbiguousSymbol = errors.New("ambiguous symbol")
+)
+
+// loadMode type checks the dependencies from source too: it's slower than reading
+// their export data, but doesn't depend on the version of the Go toolchain.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+	packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule | packages.NeedForTest
+
+// Kind is the kind of a symbol.
+type Kind string
+
+const (
+	KindFunc      Kind = "func"
+	KindMethod    Kind = "method"
+	KindType      Kind = "type"
+	KindInterface Kind = "interface"
+	KindField     Kind = "field"
+)
+
+// Range is the lines of a file. The File is relative to the module root,
+// the lines are 1-based and inclusive.
+type Range struct {
+	File      string
+	StartLine int
+	EndLine   int
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("%s:%d-%d", r.File, r.StartLine, r.EndLine)
+}
+
+// Package is a loaded package.
+type Package struct {
+	Path string
+	Name string
+	// Dir is relative to the module root.
+	Dir   string
+	Doc   string
+	Files []string
+	// Imports are the sorted import paths of the package.
+	Imports []string
+}
+
+// Symbol is a type, function or method declared at the package level,
+// or a field of a struct type.
+type Symbol struct {
+	Package string
+	// Name is the name of the function or type, or "Type.Method" for methods
+	// and "Type.Field" for fields.
+	Name      string
+	Kind      Kind
+	Signature string
+	Doc       string
+	Range     Range
+
+	obj types.Object
+}
+
+// QualifiedName returns the name prefixed by the package path.
+func (s Symbol) QualifiedName() string {
+	return s.Package + "." + s.Name
+}
+
+// Module is the loaded Go module.
+type Module struct {
+	root     string
+	fset     *token.FileSet
+	packages []*packages.Package
+	symbols  []Symbol
+	fields   []Symbol
+}
+
+// Load loads all packages of the module at the root directory.
+func Load(ctx context.Context, root string, opts ...Option) (*Module, error) {
+	o := options{tests: false, typeErrors: false}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("absolute path of the root: %w", err)
+	}
+
+	fset := token.NewFileSet()
+
+	cfg := &packages.Config{ //nolint:exhaustruct // Defaults of go/packages.
+		Mode:    loadMode,
+		Context: ctx,
+		Dir:     root,
+		Fset:    fset,
+		Tests:   o.tests,
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoad, err)
+	}
+
+	var errs []error
+
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			if !o.typeErrors || e.Kind != packages.TypeError {
+				errs = append(errs, e)
+			}
+		}
+	})
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrLoad, errors.Join(errs...))
+	}
+
+	m := &Module{
+		root:     root,
+		fset:     fset,
+		packages: dedupe(pkgs),
+		symbols:  nil,
+		fields:   nil,
+	}
+
+	for _, pkg := range m.packages {
+		m.declarations(pkg)
+	}
+
+	return m, nil
+}
+
+// dedupe drops the test binaries and the packages replaced by their test variants.
+func dedupe(pkgs []*packages.Package) []*packages.Package {
+	tested := map[string]bool{}
+
+	for _, pkg := range pkgs {
+		if pkg.ForTest == pkg.PkgPath {
+			tested[pkg.PkgPath] = true
+		}
+	}
+
+	pkgs = slices.DeleteFunc(slices.Clone(pkgs), func(pkg *packages.Package) bool {
+		return strings.HasSuffix(pkg.ID, ".test") || (pkg.ForTest == "" && tested[pkg.PkgPath])
+	})
+
+	slices.SortFunc(pkgs, func(a, b *packages.Package) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return pkgs
+}
+
+// Root returns the absolute path of the module root directory.
+func (m *Module) Root() string {
+	return m.root
+}
+
+// Path returns the module path.
+func (m *Module) Path() string {
+	for _, pkg := range m.packages {
+		if pkg.Module != nil {
+			return pkg.Module.Path
+		}
+	}
+
+	return ""
+}
+
+// Packages returns the packages of the module sorted by path.
+func (m *Module) Packages() []Package {
+	result := make([]Package, 0, len(m.packages))
+
+	for _, pkg := range m.packages {
+		if pkg.ForTest != "" && pkg.ForTest != pkg.PkgPath {
+			// The external test package.
+			continue
+		}
+
+		p := Package{
+			Path:    pkg.PkgPath,
+			Name:    pkg.Name,
+			Dir:     "",
+			Doc:     "",
+			Files:   nil,
+			Imports: slices.Sorted(maps.Keys(pkg.Imports)),
+		}
+
+		for _, f := range pkg.Syntax {
+			if f.Doc != nil && p.Doc == "" {
+				p.Doc = f.Doc.Text()
+			}
+
+			p.Files = append(p.Files, m.rel(m.fset.Position(f.Package).Filename))
+		}
+
+		if len(p.Files) > 0 {
+			p.Dir = filepath.Dir(p.Files[0])
+		}
+
+		result = append(result, p)
+	}
+
+	slices.SortFunc(result, func(a, b Package) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return result
+}
+
+// Symbols returns the symbols of the package in the order of declaration, without fields.
+func (m *Module) Symbols(pkgPath string) ([]Symbol, error) {
+	var result []Symbol
+
+	found := false
+
+	for _, pkg := range m.packages {
+		found = found || pkg.PkgPath == pkgPath
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, pkgPath)
+	}
+
+	for _, s := range m.symbols {
+		if s.Package == pkgPath {
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+// Find returns the symbols matching the name. The name is either qualified by
+// the package path, like "example.com/shapes.Shape", or by a suffix of it,
+// like "shapes.Shape" or "Shape". Methods and fields are named "Type.Method"
+// and "Type.Field".
+func (m *Module) Find(name string) []Symbol {
+	var result []Symbol
+
+	for _, s := range slices.Concat(m.symbols, m.fields) {
+		qualified := s.QualifiedName()
+
+		if qualified == name || strings.HasSuffix(qualified, "/"+name) || strings.HasSuffix(qualified, "."+name) {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// Lookup finds the only symbol matching the name, see [Module.Find].
+func (m *Module) Lookup(name string) (Symbol, error) {
+	found := m.Find(name)
+
+	switch len(found) {
+	case 0:
+		return Symbol{}, fmt.Errorf("%w: %s", ErrSymbolNotFound, name)
+	case 1:
+		return found[0], nil
+	}
+
+	names := make([]string, 0, len(found))
+
+	for _, s := range found {
+		names = append(names, s.QualifiedName())
+	}
+
+	return Symbol{}, fmt.Errorf("%w: %s is one of %s", ErrAmbiguousSymbol, name, strings.Join(names, ", "))
+}
+
+// declarations collects the symbols and fields declared in the package.
+func (m *Module) declarations(pkg *packages.Package) {
+	for _, f := range pkg.Syntax {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				m.symbols = append(m.symbols, m.funcSymbol(pkg, decl))
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						m.symbols = append(m.symbols, m.typeSymbol(pkg, decl, spec))
+						m.fields = append(m.fields, m.fieldSymbols(pkg, spec)...)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (m *Module) funcSymbol(pkg *packages.Package, decl *ast.FuncDecl) Symbol {
+	s := Symbol{
+		Package:   pkg.PkgPath,
+		Name:      decl.Name.Name,
+		Kind:      KindFunc,
+		Signature: m.format(&ast.FuncDecl{Doc: nil, Recv: decl.Recv, Name: decl.Name, Type: decl.Type, Body: nil}),
+		Doc:       decl.Doc.Text(),
+		Range:     m.lines(decl),
+		obj:       pkg.TypesInfo.Defs[decl.Name],
+	}
+
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		s.Kind = KindMethod
+		s.Name = ReceiverName(decl.Recv.List[0].Type) + "." + s.Name
+	}
+
+	return s
+}
+
+func (m *Module) typeSymbol(pkg *packages.Package, decl *ast.GenDecl, spec *ast.TypeSpec) Symbol {
+	s := Symbol{
+		Package:   pkg.PkgPath,
+		Name:      spec.Name.Name,
+		Kind:      KindType,
+		Signature: "type " + m.format(spec),
+		Doc:       spec.Doc.Text(),
+		Range:     m.lines(spec),
+		obj:       pkg.TypesInfo.Defs[spec.Name],
+	}
+
+	if _, ok := spec.Type.(*ast.InterfaceType); ok {
+		s.Kind = KindInterface
+	}
+
+	// The doc comment of "type T struct{}" belongs to the declaration.
+	if len(decl.Specs) == 1 {
+		s.Doc = decl.Doc.Text()
+		s.Range = m.lines(decl)
+	}
+
+	return s
+}
+
+func (m *Module) fieldSymbols(pkg *packages.Package, spec *ast.TypeSpec) []Symbol {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+
+	var result []Symbol
+
+	// Embedded fields are skipped, they are named by their types.
+	for _, field := range st.Fields.List {
+		signature := m.format(field.Type)
+		if field.Tag != nil {
+			signature += " " + field.Tag.Value
+		}
+
+		for _, name := range field.Names {
+			result = append(result, Symbol{
+				Package:   pkg.PkgPath,
+				Name:      spec.Name.Name + "." + name.Name,
+				Kind:      KindField,
+				Signature: name.Name + " " + signature,
+				Doc:       field.Doc.Text(),
+				Range:     m.lines(field),
+				obj:       pkg.TypesInfo.Defs[name],
+			})
+		}
+	}
+
+	return result
+}
+
+// ReceiverName returns the name of the receiver type without the pointer and type parameters.
+func ReceiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return ReceiverName(expr.X)
+	case *ast.IndexExpr:
+		return ReceiverName(expr.X)
+	case *ast.IndexListExpr:
+		return ReceiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	default:
+		return ""
+	}
+}
+
+func (m *Module) format(node ast.Node) string {
+	buf := bytes.Buffer{}
+
+	if err := printer.Fprint(&buf, m.fset, node); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
+
+func (m *Module) lines(node ast.Node) Range {
+	start, end := m.fset.Position(node.Pos()), m.fset.Position(node.End())
+
+	return Range{File: m.rel(start.Filename), StartLine: start.Line, EndLine: end.Line}
+}
+
+// rel returns the file name relative to the module root.
+func (m *Module) rel(filename string) string {
+	if rel, err := filepath.Rel(m.root, filename); err == nil {
+		return filepath.ToSlash(rel)
+	}
+
+	return filename
+}
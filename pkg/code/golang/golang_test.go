@@ -0,0 +1,269 @@
+package golang_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/code/golang"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, opts ...golang.Option) *golang.Module {
+	t.Helper()
+
+	m, err := golang.Load(context.Background(), "testdata/shapes", opts...)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestLoad_TypeErrors(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, os.DirFS("testdata/shapes")))
+
+	broken := []byte("package shapes\n\nvar _ int = \"x\"\n")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "broken.go"), broken, 0o600))
+
+	_, err := golang.Load(context.Background(), root)
+	require.ErrorIs(t, err, golang.ErrLoad)
+
+	m, err := golang.Load(context.Background(), root, golang.WithTypeErrors(true))
+	require.NoError(t, err)
+	assert.Len(t, m.Find("Shape"), 1)
+}
+
+func names(symbols []golang.Symbol) []string {
+	result := make([]string, 0, len(symbols))
+
+	for _, s := range symbols {
+		result = append(result, s.QualifiedName())
+	}
+
+	return result
+}
+
+func TestModule_Packages(t *testing.T) {
+	t.Parallel()
+
+	m := load(t, golang.WithTests(true))
+
+	assert.Equal(t, []golang.Package{
+		{
+			Path:    "example.com/shapes",
+			Name:    "shapes",
+			Dir:     ".",
+			Doc:     "Package shapes measures shapes.\n",
+			Files:   []string{"shapes.go", "shapes_test.go"},
+			Imports: []string{"testing"},
+		},
+		{
+			Path:    "example.com/shapes/circle",
+			Name:    "circle",
+			Dir:     "circle",
+			Doc:     "Package circle adds the circle shape.\n",
+			Files:   []string{"circle/circle.go"},
+			Imports: []string{"example.com/shapes", "math"},
+		},
+	}, m.Packages())
+}
+
+func TestModule_Symbols(t *testing.T) {
+	t.Parallel()
+
+	m := load(t)
+
+	symbols, err := m.Symbols("example.com/shapes")
+	require.NoError(t, err)
+	require.Len(t, symbols, 5)
+
+	assert.Equal(t, []string{
+		"example.com/shapes.Shape",
+		"example.com/shapes.Square",
+		"example.com/shapes.Square.Area",
+		"example.com/shapes.Square.Name",
+		"example.com/shapes.Total",
+	}, names(symbols))
+
+	assert.Equal(t, golang.KindInterface, symbols[0].Kind)
+	assert.Equal(t, "Shape is a flat figure.\n", symbols[0].Doc)
+	assert.Equal(t, golang.Range{File: "shapes.go", StartLine: 5, EndLine: 9}, symbols[0].Range)
+	assert.Equal(t, "type Square struct {\n\tSide float64\n}", symbols[1].Signature)
+
+	assert.Equal(t, golang.KindMethod, symbols[2].Kind)
+	assert.Equal(t, "func (s Square) Area() float64", symbols[2].Signature)
+	assert.Equal(t, golang.Range{File: "shapes.go", StartLine: 17, EndLine: 19}, symbols[2].Range)
+
+	assert.Equal(t, golang.KindFunc, symbols[4].Kind)
+	assert.Equal(t, "func Total(shapes ...Shape) float64", symbols[4].Signature)
+
+	_, err = m.Symbols("example.com/missing")
+	require.ErrorIs(t, err, golang.ErrPackageNotFound)
+}
+
+func TestModule_Find(t *testing.T) {
+	t.Parallel()
+
+	m := load(t)
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Shape", []string{"example.com/shapes.Shape"}},
+		{"shapes.Total", []string{"example.com/shapes.Total"}},
+		{"example.com/shapes/circle.Total", []string{"example.com/shapes/circle.Total"}},
+		{"Total", []string{"example.com/shapes.Total", "example.com/shapes/circle.Total"}},
+		{"Circle.Area", []string{"example.com/shapes/circle.Circle.Area"}},
+		{"Missing", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, names(m.Find(tt.name)))
+		})
+	}
+}
+
+func TestModule_References(t *testing.T) {
+	t.Parallel()
+
+	m := load(t)
+
+	refs, err := m.References("shapes.Total")
+	require.NoError(t, err)
+	assert.Equal(t, []golang.Location{
+		{File: "circle/circle.go", Line: 37, Column: 16, Text: "return shapes.Total(all...)"},
+	}, refs)
+
+	refs, err = load(t, golang.WithTests(true)).References("shapes.Total")
+	require.NoError(t, err)
+	assert.Equal(t, []golang.Location{
+		{File: "circle/circle.go", Line: 37, Column: 16, Text: "return shapes.Total(all...)"},
+		{File: "shapes_test.go", Line: 6, Column: 5, Text: "if Total(Square{Side: 2}) != 4 {"},
+	}, refs)
+
+	_, err = m.References("Total")
+	require.ErrorIs(t, err, golang.ErrAmbiguousSymbol)
+
+	_, err = m.References("Missing")
+	require.ErrorIs(t, err, golang.ErrSymbolNotFound)
+}
+
+func TestModule_Identifiers(t *testing.T) {
+	t.Parallel()
+
+	m := load(t, golang.WithTests(true))
+
+	fields := m.Find("Square.Side")
+	require.Len(t, fields, 1)
+	assert.Equal(t, golang.KindField, fields[0].Kind)
+	assert.Equal(t, "Side float64", fields[0].Signature)
+
+	ids, err := m.Identifiers("Square.Side")
+	require.NoError(t, err)
+	assert.Equal(t, []golang.Location{
+		{File: "shapes.go", Line: 13, Column: 2, Text: "Side float64"},
+		{File: "shapes.go", Line: 18, Column: 11, Text: "return s.Side * s.Side"},
+		{File: "shapes.go", Line: 18, Column: 20, Text: "return s.Side * s.Side"},
+		{File: "shapes_test.go", Line: 6, Column: 18, Text: "if Total(Square{Side: 2}) != 4 {"},
+	}, ids)
+}
+
+func TestModule_FileReferences(t *testing.T) {
+	t.Parallel()
+
+	m := load(t, golang.WithTests(true))
+
+	assert.Equal(t, "example.com/shapes", m.Path())
+	assert.Equal(t, map[string]map[string]int{
+		"circle/circle.go": {"shapes.go": 4},
+		"shapes_test.go":   {"shapes.go": 2},
+	}, m.FileReferences())
+}
+
+func TestModule_Implementations(t *testing.T) {
+	t.Parallel()
+
+	m := load(t, golang.WithTests(true))
+
+	impls, err := m.Implementations("Shape")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/shapes.Square", "example.com/shapes/circle.Circle"}, names(impls))
+
+	_, err = m.Implementations("Square")
+	require.ErrorIs(t, err, golang.ErrNotInterface)
+}
+
+func TestModule_Interfaces(t *testing.T) {
+	t.Parallel()
+
+	m := load(t, golang.WithTests(true))
+
+	ifaces, err := m.Interfaces("Circle.Area")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/shapes.Shape"}, names(ifaces))
+
+	_, err = m.Interfaces("Square")
+	require.ErrorIs(t, err, golang.ErrNotMethod)
+}
+
+func TestModule_Tools(t *testing.T) {
+	t.Parallel()
+
+	set, err := tools.NewSet(load(t).Tools()...)
+	require.NoError(t, err)
+
+	tests := []struct {
+		call llm.ToolCallFunction
+		want string
+	}{
+		{
+			llm.ToolCallFunction{Name: "go_packages", Arguments: nil},
+			"example.com/shapes (.): Package shapes measures shapes.\n" +
+				"example.com/shapes/circle (circle): Package circle adds the circle shape.\n",
+		},
+		{
+			llm.ToolCallFunction{Name: "go_symbols", Arguments: map[string]any{"package": "example.com/shapes/circle"}},
+			"circle/circle.go:13-15: type Circle struct\n" +
+				"circle/circle.go:17-19: func (c *Circle) Area() float64\n" +
+				"circle/circle.go:21-23: func (c *Circle) Name() string\n" +
+				"circle/circle.go:26-38: func Total(circles []*Circle, squares ...shapes.Square) float64\n",
+		},
+		{
+			llm.ToolCallFunction{Name: "go_definition", Arguments: map[string]any{"name": "Square.Area"}},
+			"method example.com/shapes.Square.Area at shapes.go:17-19\n" +
+				"// Area returns the area of the square.\n" +
+				"func (s Square) Area() float64",
+		},
+		{
+			llm.ToolCallFunction{Name: "go_references", Arguments: map[string]any{"name": "Circle"}},
+			"circle/circle.go:10:24: var _ shapes.Shape = (*Circle)(nil)\n" +
+				"circle/circle.go:17:10: func (c *Circle) Area() float64 {\n" +
+				"circle/circle.go:21:10: func (c *Circle) Name() string {\n" +
+				"circle/circle.go:26:23: func Total(circles []*Circle, squares ...shapes.Square) float64 {\n",
+		},
+		{
+			llm.ToolCallFunction{Name: "go_implementations", Arguments: map[string]any{"name": "shapes.Shape"}},
+			"example.com/shapes.Square at shapes.go:12-14\nexample.com/shapes/circle.Circle at circle/circle.go:13-15\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.call.Name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := set.Call(context.Background(), tt.call)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
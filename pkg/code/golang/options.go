@@ -0,0 +1,25 @@
+package golang
+
+type options struct {
+	tests      bool
+	typeErrors bool
+}
+
+type Option func(*options)
+
+// WithTests loads test files and test packages too.
+// Default: false.
+func WithTests(tests bool) Option {
+	return func(opts *options) {
+		opts.tests = tests
+	}
+}
+
+// WithTypeErrors loads the packages with type errors, like unused imports in the middle
+// of editing, instead of failing. The types of such packages may be incomplete.
+// Default: false.
+func WithTypeErrors(allowed bool) Option {
+	return func(opts *options) {
+		opts.typeErrors = allowed
+	}
+}
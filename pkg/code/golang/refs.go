@@ -0,0 +1,260 @@
+package golang
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+var (
+	ErrNotInterface = errors.New("not an interface")
+	ErrNotMethod    = errors.New("not a method")
+)
+
+// Location is a position in a file relative to the module root.
+// The line and column are 1-based.
+type Location struct {
+	File   string
+	Line   int
+	Column int
+	// Text is the line at the location without leading and trailing white space.
+	Text string
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+}
+
+// References returns the locations where the symbol is used, sorted by file and position.
+func (m *Module) References(name string) ([]Location, error) {
+	sym, err := m.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.occurrences(sym, false), nil
+}
+
+// Identifiers returns the locations of the identifiers of the symbol: its declaration
+// and uses, sorted by file and position.
+func (m *Module) Identifiers(name string) ([]Location, error) {
+	sym, err := m.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.occurrences(sym, true), nil
+}
+
+func (m *Module) occurrences(sym Symbol, defs bool) []Location {
+	key := m.objectKey(sym.obj)
+	seen := map[Location]bool{}
+	sources := map[string][][]byte{}
+
+	var result []Location
+
+	add := func(ident *ast.Ident, obj types.Object) {
+		if obj == nil || m.objectKey(obj) != key {
+			return
+		}
+
+		pos := m.fset.Position(ident.Pos())
+		loc := Location{File: m.rel(pos.Filename), Line: pos.Line, Column: pos.Column, Text: ""}
+
+		if seen[loc] {
+			return
+		}
+
+		seen[loc] = true
+		loc.Text = sourceLine(sources, pos.Filename, pos.Line)
+		result = append(result, loc)
+	}
+
+	for _, pkg := range m.packages {
+		for ident, obj := range pkg.TypesInfo.Uses {
+			add(ident, obj)
+		}
+
+		if defs {
+			for ident, obj := range pkg.TypesInfo.Defs {
+				add(ident, obj)
+			}
+		}
+	}
+
+	slices.SortFunc(result, func(a, b Location) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+
+	return result
+}
+
+// FileReferences returns how many times every file of the module uses the symbols
+// declared in other files, by the file and then by the file of the declaration.
+func (m *Module) FileReferences() map[string]map[string]int {
+	declared := make(map[string]string, len(m.symbols))
+
+	for _, s := range m.symbols {
+		if s.obj != nil {
+			declared[m.objectKey(s.obj)] = s.Range.File
+		}
+	}
+
+	refs := map[string]map[string]int{}
+
+	for _, pkg := range m.packages {
+		for ident, obj := range pkg.TypesInfo.Uses {
+			to, ok := declared[m.objectKey(obj)]
+			from := m.rel(m.fset.Position(ident.Pos()).Filename)
+
+			if !ok || from == to {
+				continue
+			}
+
+			if refs[from] == nil {
+				refs[from] = map[string]int{}
+			}
+
+			refs[from][to]++
+		}
+	}
+
+	return refs
+}
+
+// Implementations returns the types of the module implementing the interface
+// by their values or pointers.
+func (m *Module) Implementations(name string) ([]Symbol, error) {
+	sym, err := m.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	iface, ok := sym.obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotInterface, sym.QualifiedName())
+	}
+
+	var result []Symbol
+
+	seen := map[string]bool{}
+
+	for _, s := range m.symbols {
+		if s.Kind != KindType || seen[s.QualifiedName()] {
+			continue
+		}
+
+		if named, ok := s.obj.Type().(*types.Named); ok && named.TypeParams().Len() == 0 && implements(named, iface) {
+			seen[s.QualifiedName()] = true
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+// Interfaces returns the interfaces of the module which the receiver type of the method
+// implements and which have a method of the same name, so the method can't be renamed alone.
+func (m *Module) Interfaces(method string) ([]Symbol, error) {
+	sym, err := m.Lookup(method)
+	if err != nil {
+		return nil, err
+	}
+
+	fn, ok := sym.obj.(*types.Func)
+	if !ok || sym.Kind != KindMethod {
+		return nil, fmt.Errorf("%w: %s", ErrNotMethod, sym.QualifiedName())
+	}
+
+	recv := fn.Signature().Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+
+	var result []Symbol
+
+	seen := map[string]bool{}
+
+	for _, s := range m.symbols {
+		if s.Kind != KindInterface || seen[s.QualifiedName()] {
+			continue
+		}
+
+		iface, ok := s.obj.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+
+		if hasMethod(iface, fn.Name()) && implements(recv, iface) {
+			seen[s.QualifiedName()] = true
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+func hasMethod(iface *types.Interface, name string) bool {
+	for i := range iface.NumMethods() {
+		if iface.Method(i).Name() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// implements compares the method signatures by their text instead of the identity of types,
+// since test variants of packages have their own types.
+func implements(typ types.Type, iface *types.Interface) bool {
+	if _, ok := typ.Underlying().(*types.Interface); ok || iface.NumMethods() == 0 {
+		return false
+	}
+
+	for i := range iface.NumMethods() {
+		want := iface.Method(i)
+
+		obj, _, _ := types.LookupFieldOrMethod(typ, true, want.Pkg(), want.Name())
+
+		got, ok := obj.(*types.Func)
+		if !ok || types.TypeString(got.Type(), qualifier) != types.TypeString(want.Type(), qualifier) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func qualifier(pkg *types.Package) string {
+	return pkg.Path()
+}
+
+// objectKey identifies the object by its declaration, so the objects of the test
+// variants of a package match the ones of the package.
+func (m *Module) objectKey(obj types.Object) string {
+	return m.fset.Position(obj.Pos()).String() + " " + obj.Name()
+}
+
+func sourceLine(sources map[string][][]byte, filename string, line int) string {
+	ls, ok := sources[filename]
+	if !ok {
+		b, err := os.ReadFile(filepath.Clean(filename))
+		if err == nil {
+			ls = bytes.Split(b, []byte("\n"))
+		}
+
+		sources[filename] = ls
+	}
+
+	if line < 1 || line > len(ls) {
+		return ""
+	}
+
+	return string(bytes.TrimSpace(ls[line-1]))
+}
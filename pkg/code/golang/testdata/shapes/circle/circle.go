@@ -0,0 +1,38 @@
+// Package circle adds the circle shape.
+package circle
+
+import (
+	"math"
+
+	"example.com/shapes"
+)
+
+var _ shapes.Shape = (*Circle)(nil)
+
+// Circle is a round shape.
+type Circle struct {
+	R float64
+}
+
+func (c *Circle) Area() float64 {
+	return math.Pi * c.R * c.R
+}
+
+func (c *Circle) Name() string {
+	return "circle"
+}
+
+// Total returns the total area of the circles and squares.
+func Total(circles []*Circle, squares ...shapes.Square) float64 {
+	all := []shapes.Shape{}
+
+	for _, c := range circles {
+		all = append(all, c)
+	}
+
+	for _, s := range squares {
+		all = append(all, s)
+	}
+
+	return shapes.Total(all...)
+}
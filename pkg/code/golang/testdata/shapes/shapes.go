@@ -0,0 +1,34 @@
+// Package shapes measures shapes.
+package shapes
+
+// Shape is a flat figure.
+type Shape interface {
+	// Area returns the area of the shape.
+	Area() float64
+	Name() string
+}
+
+// Square is a shape with equal sides.
+type Square struct {
+	Side float64
+}
+
+// Area returns the area of the square.
+func (s Square) Area() float64 {
+	return s.Side * s.Side
+}
+
+func (s Square) Name() string {
+	return "square"
+}
+
+// Total returns the total area of the shapes.
+func Total(shapes ...Shape) float64 {
+	total := 0.0
+
+	for _, s := range shapes {
+		total += s.Area()
+	}
+
+	return total
+}
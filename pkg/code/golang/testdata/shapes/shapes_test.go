@@ -0,0 +1,9 @@
+package shapes
+
+import "testing"
+
+func TestTotal(t *testing.T) {
+	if Total(Square{Side: 2}) != 4 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,204 @@
+package golang
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/tools"
+)
+
+// NameDescription describes the parameter of tools taking the name of a symbol, see [Module.Find].
+const NameDescription = `Name of the symbol qualified by the package name or path, like "shapes.Shape" ` +
+	`or "example.com/shapes.Shape", or just "Shape". Methods and fields are named "Type.Method" and "Type.Field".`
+
+// Tools returns the tools answering questions about the code of the module.
+func (m *Module) Tools() []tools.Tool {
+	return []tools.Tool{
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_packages",
+				Description: "List Go packages of the project with their directories and synopses.",
+				Parameters:  map[string]llm.FunctionProperty{},
+			},
+			Handler: m.packagesTool,
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_symbols",
+				Description: "List types, functions and methods of a Go package with their signatures and lines.",
+				Parameters: map[string]llm.FunctionProperty{
+					"package": {
+						Type:          llm.String,
+						ArrayItemType: 0,
+						Description:   "Import path of the package.",
+						Enum:          nil,
+						Required:      true,
+					},
+				},
+			},
+			Handler: m.symbolsTool,
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_definition",
+				Description: "Find where a Go type, function or method is defined: file, lines, signature and doc.",
+				Parameters:  nameParameter(NameDescription),
+			},
+			Handler: m.definitionTool,
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_references",
+				Description: "Find where a Go type, function or method is used.",
+				Parameters:  nameParameter(NameDescription),
+			},
+			Handler: m.referencesTool,
+		},
+		{
+			Function: llm.ToolFunction{
+				Name:        "go_implementations",
+				Description: "Find the types implementing a Go interface.",
+				Parameters:  nameParameter("Name of the interface, like \"shapes.Shape\"."),
+			},
+			Handler: m.implementationsTool,
+		},
+	}
+}
+
+func nameParameter(description string) map[string]llm.FunctionProperty {
+	return map[string]llm.FunctionProperty{
+		"name": {
+			Type:          llm.String,
+			ArrayItemType: 0,
+			Description:   description,
+			Enum:          nil,
+			Required:      true,
+		},
+	}
+}
+
+func (m *Module) packagesTool(_ context.Context, _ tools.Args) (string, error) {
+	sb := strings.Builder{}
+
+	for _, p := range m.Packages() {
+		synopsis, _, _ := strings.Cut(p.Doc, "\n")
+		fmt.Fprintf(&sb, "%s (%s): %s\n", p.Path, p.Dir, synopsis)
+	}
+
+	return sb.String(), nil
+}
+
+func (m *Module) symbolsTool(_ context.Context, args tools.Args) (string, error) {
+	pkgPath, err := args.String("package")
+	if err != nil {
+		return "", fmt.Errorf("parse arguments: %w", err)
+	}
+
+	symbols, err := m.Symbols(pkgPath)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+
+	for _, s := range symbols {
+		signature := s.Signature
+
+		// The fields and methods are shown by the definition.
+		if s.Kind == KindType || s.Kind == KindInterface {
+			signature, _, _ = strings.Cut(signature, "\n")
+			signature = strings.TrimSuffix(signature, " {")
+		}
+
+		fmt.Fprintf(&sb, "%s: %s\n", s.Range, signature)
+	}
+
+	return sb.String(), nil
+}
+
+func (m *Module) definitionTool(_ context.Context, args tools.Args) (string, error) {
+	name, err := args.String("name")
+	if err != nil {
+		return "", fmt.Errorf("parse arguments: %w", err)
+	}
+
+	symbols := m.Find(name)
+	if len(symbols) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrSymbolNotFound, name)
+	}
+
+	parts := make([]string, 0, len(symbols))
+
+	for _, s := range symbols {
+		parts = append(parts, fmt.Sprintf(
+			"%s %s at %s\n%s%s", s.Kind, s.QualifiedName(), s.Range, comment(s.Doc), s.Signature,
+		))
+	}
+
+	return strings.Join(parts, "\n\n"), nil
+}
+
+func (m *Module) referencesTool(_ context.Context, args tools.Args) (string, error) {
+	name, err := args.String("name")
+	if err != nil {
+		return "", fmt.Errorf("parse arguments: %w", err)
+	}
+
+	refs, err := m.References(name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(refs) == 0 {
+		return "no references", nil
+	}
+
+	sb := strings.Builder{}
+
+	for _, r := range refs {
+		fmt.Fprintf(&sb, "%s: %s\n", r, r.Text)
+	}
+
+	return sb.String(), nil
+}
+
+func (m *Module) implementationsTool(_ context.Context, args tools.Args) (string, error) {
+	name, err := args.String("name")
+	if err != nil {
+		return "", fmt.Errorf("parse arguments: %w", err)
+	}
+
+	impls, err := m.Implementations(name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(impls) == 0 {
+		return "no implementations", nil
+	}
+
+	sb := strings.Builder{}
+
+	for _, s := range impls {
+		fmt.Fprintf(&sb, "%s at %s\n", s.QualifiedName(), s.Range)
+	}
+
+	return sb.String(), nil
+}
+
+// comment formats the doc as a Go comment.
+func comment(doc string) string {
+	if doc == "" {
+		return ""
+	}
+
+	sb := strings.Builder{}
+
+	for _, l := range strings.Split(strings.TrimSuffix(doc, "\n"), "\n") {
+		sb.WriteString(strings.TrimRight("// "+l, " ") + "\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,160 @@
+package lines
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrOutOfRange  = errors.New("line range is out of the text")
+	ErrStale       = errors.New("lines have changed")
+	ErrOverlap     = errors.New("edits overlap")
+	ErrNotNumbered = errors.New("line is not numbered")
+)
+
+// Edit replaces the lines from Start to End inclusive with New. Lines are
+// numbered from 1 as [AddNumbers] does. An insertion before the Start line
+// has End equal to Start-1.
+type Edit struct {
+	Start int
+	End   int
+	// Old is the expected content of the lines. The edit is stale when the lines
+	// differ from it ignoring trailing white space. Insertions don't check it.
+	Old string
+	// New is the content of the lines, the empty one deletes them.
+	New string
+}
+
+// Replace returns the edit replacing the old lines from start to end inclusive with the text.
+func Replace(start, end int, old, text string) Edit {
+	return Edit{Start: start, End: end, Old: old, New: text}
+}
+
+// Insert returns the edit inserting the text before the line.
+// The line after the last one appends the text.
+func Insert(before int, text string) Edit {
+	return Edit{Start: before, End: before - 1, Old: "", New: text}
+}
+
+// Delete returns the edit deleting the lines from start to end inclusive.
+func Delete(start, end int, old string) Edit {
+	return Edit{Start: start, End: end, Old: old, New: ""}
+}
+
+func (e Edit) isInsert() bool {
+	return e.End == e.Start-1
+}
+
+func (e Edit) String() string {
+	if e.isInsert() {
+		return fmt.Sprintf("insert before line %d", e.Start)
+	}
+
+	return fmt.Sprintf("lines %d-%d", e.Start, e.End)
+}
+
+// Apply applies the edits to the s. All edits refer to the line numbers of
+// the original s, so they are applied from the bottom to the top and must not
+// overlap. Nothing is applied if any edit is out of range, stale or overlaps.
+//
+// The line feed ending the s doesn't start a new line, so the insertion before the
+// line after the last one appends the text, and the s keeps its line feed.
+func Apply(s string, edits ...Edit) (string, error) {
+	ls := split(s)
+
+	sorted := slices.Clone(edits)
+	slices.SortStableFunc(sorted, func(a, b Edit) int {
+		if a.Start != b.Start {
+			return a.Start - b.Start
+		}
+
+		return a.End - b.End
+	})
+
+	for i, e := range sorted {
+		if err := check(ls, e); err != nil {
+			return "", err
+		}
+
+		if i > 0 && overlap(sorted[i-1], e) {
+			return "", fmt.Errorf("%w: %s and %s", ErrOverlap, sorted[i-1], e)
+		}
+	}
+
+	for _, e := range slices.Backward(sorted) {
+		ls = slices.Replace(ls, e.Start-1, e.End, split(e.New)...)
+	}
+
+	if len(ls) == 0 {
+		return "", nil
+	}
+
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return strings.Join(ls, "\n") + "\n", nil
+	}
+
+	return strings.Join(ls, "\n"), nil
+}
+
+func check(ls []string, e Edit) error {
+	if e.Start < 1 || e.End < e.Start-1 || e.End > len(ls) || (e.isInsert() && e.Start > len(ls)+1) {
+		return fmt.Errorf("%w: %s of %d", ErrOutOfRange, e, len(ls))
+	}
+
+	if e.isInsert() {
+		return nil
+	}
+
+	got := ls[e.Start-1 : e.End]
+	want := split(e.Old)
+
+	if !slices.EqualFunc(got, want, func(a, b string) bool {
+		return strings.TrimRight(a, " \t\r") == strings.TrimRight(b, " \t\r")
+	}) {
+		return fmt.Errorf("%w: %s are now:\n%s", ErrStale, e, strings.Join(got, "\n"))
+	}
+
+	return nil
+}
+
+// overlap reports whether the b edit starting at or after the a one touches its lines.
+func overlap(a, b Edit) bool {
+	if a.isInsert() && b.isInsert() {
+		return a.Start == b.Start
+	}
+
+	return b.Start <= a.End
+}
+
+// split splits the text into lines, the trailing line feed doesn't start a new line.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// StripNumbers is the inverse of [AddNumbers]: it removes the "<line_number>:"
+// prefixes of the lines.
+func StripNumbers(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	ls := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+
+	for i, l := range ls {
+		num, content, ok := strings.Cut(l, ":")
+		if _, err := strconv.Atoi(num); !ok || err != nil {
+			return "", fmt.Errorf("%w: %q", ErrNotNumbered, l)
+		}
+
+		ls[i] = content
+	}
+
+	return strings.Join(ls, "\n"), nil
+}
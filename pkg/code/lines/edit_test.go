@@ -0,0 +1,111 @@
+package lines_test
+
+import (
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/code/lines"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	const text = "a\nb\nc\nd\n"
+
+	tests := []struct {
+		name    string
+		edits   []lines.Edit
+		want    string
+		wantErr error
+	}{
+		{"no edits", nil, text, nil},
+		{"replace", []lines.Edit{lines.Replace(2, 3, "b\nc\n", "B\n")}, "a\nB\nd\n", nil},
+		{"replace ignores trailing space", []lines.Edit{lines.Replace(1, 1, "a  ", "A")}, "A\nb\nc\nd\n", nil},
+		{"insert", []lines.Edit{lines.Insert(2, "x\ny\n")}, "a\nx\ny\nb\nc\nd\n", nil},
+		{"append", []lines.Edit{lines.Insert(5, "e\n")}, "a\nb\nc\nd\ne\n", nil},
+		{"delete", []lines.Edit{lines.Delete(1, 2, "a\nb")}, "c\nd\n", nil},
+		{
+			"several edits refer to original lines",
+			[]lines.Edit{lines.Delete(1, 1, "a"), lines.Replace(4, 4, "d", "D"), lines.Insert(3, "x")},
+			"b\nx\nc\nD\n",
+			nil,
+		},
+		{
+			"insert before replaced line",
+			[]lines.Edit{lines.Replace(2, 2, "b", "B"), lines.Insert(2, "x")},
+			"a\nx\nB\nc\nd\n",
+			nil,
+		},
+		{"stale", []lines.Edit{lines.Replace(2, 2, "x", "y")}, "", lines.ErrStale},
+		{"out of range", []lines.Edit{lines.Delete(4, 6, "")}, "", lines.ErrOutOfRange},
+		{"insert out of range", []lines.Edit{lines.Insert(7, "x")}, "", lines.ErrOutOfRange},
+		{"insert after the line feed", []lines.Edit{lines.Insert(6, "e\n")}, "", lines.ErrOutOfRange},
+		{"replace after the line feed", []lines.Edit{lines.Replace(5, 5, "", "e")}, "", lines.ErrOutOfRange},
+		{"zero line", []lines.Edit{lines.Insert(0, "x")}, "", lines.ErrOutOfRange},
+		{
+			"overlap",
+			[]lines.Edit{lines.Delete(1, 2, "a\nb"), lines.Replace(2, 3, "b\nc", "")},
+			"",
+			lines.ErrOverlap,
+		},
+		{"insert into replaced lines", []lines.Edit{lines.Delete(1, 2, "a\nb"), lines.Insert(2, "x")}, "", lines.ErrOverlap},
+		{"same insertion point", []lines.Edit{lines.Insert(2, "x"), lines.Insert(2, "y")}, "", lines.ErrOverlap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := lines.Apply(text, tt.edits...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApply_NoTrailingNewline(t *testing.T) {
+	t.Parallel()
+
+	got, err := lines.Apply("a\nb", lines.Replace(2, 2, "b", "B\n"), lines.Insert(3, "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\nB\nc", got)
+}
+
+func TestApply_TrailingNewline(t *testing.T) {
+	t.Parallel()
+
+	got, err := lines.Apply("a\nb\n", lines.Insert(3, "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\n", got)
+
+	_, err = lines.Apply("a\nb\n", lines.Insert(4, "c\n"))
+	require.ErrorIs(t, err, lines.ErrOutOfRange)
+
+	got, err = lines.Apply("a\n", lines.Delete(1, 1, "a"))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = lines.Apply("", lines.Insert(1, "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", got)
+}
+
+func TestStripNumbers(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "package main", "package main\n", "package main\nfunc main() {\n}\n"} {
+		got, err := lines.StripNumbers(lines.AddNumbers(s))
+		require.NoError(t, err)
+		assert.Equal(t, s, got)
+	}
+
+	_, err := lines.StripNumbers("1:a\nb\n")
+	require.ErrorIs(t, err, lines.ErrNotNumbered)
+}
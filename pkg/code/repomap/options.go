@@ -0,0 +1,34 @@
+package repomap
+
+import "github.com/WinPooh32/go-coder/pkg/llm"
+
+// defaultTokens is the size of the map in tokens.
+const defaultTokens = 2048
+
+type options struct {
+	embedder llm.Embedder
+	tokens   int
+}
+
+type Option func(*options)
+
+// WithEmbedder ranks files by the similarity of their symbols to the query
+// in addition to how often they are referenced.
+// Default: files are ranked by references only.
+func WithEmbedder(embedder llm.Embedder) Option {
+	return func(opts *options) {
+		opts.embedder = embedder
+	}
+}
+
+// WithTokens limits the size of the rendered map.
+// Default: 2048 tokens, when the limit is not positive.
+func WithTokens(n int) Option {
+	return func(opts *options) {
+		opts.tokens = n
+
+		if n <= 0 {
+			opts.tokens = defaultTokens
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package repomap
+
+import "slices"
+
+const (
+	damping        = 0.85
+	rankIterations = 50
+)
+
+// pageRank ranks the nodes of the graph by the weighted edges from a node to
+// the nodes it refers to. Random jumps land on the nodes in proportion to the
+// teleport weights, or uniformly when they are all zero.
+func pageRank(n int, edges map[int]map[int]float64, teleport []float64) []float64 {
+	if n == 0 {
+		return nil
+	}
+
+	jump := normalize(teleport, n)
+	rank := slices.Clone(jump)
+
+	outWeight := make([]float64, n)
+
+	for from, to := range edges {
+		for _, w := range to {
+			outWeight[from] += w
+		}
+	}
+
+	for range rankIterations {
+		next := make([]float64, n)
+		dangling := 0.0
+
+		for from := range n {
+			if outWeight[from] == 0 {
+				dangling += rank[from]
+
+				continue
+			}
+
+			for to, w := range edges[from] {
+				next[to] += damping * rank[from] * w / outWeight[from]
+			}
+		}
+
+		for i := range next {
+			next[i] += (1-damping)*jump[i] + damping*dangling*jump[i]
+		}
+
+		rank = next
+	}
+
+	return rank
+}
+
+// normalize returns the weights summing up to one, or the uniform ones.
+func normalize(weights []float64, n int) []float64 {
+	result := make([]float64, n)
+	sum := 0.0
+
+	for i := range min(n, len(weights)) {
+		result[i] = max(weights[i], 0)
+		sum += result[i]
+	}
+
+	for i := range result {
+		if sum == 0 {
+			result[i] = 1 / float64(n)
+		} else {
+			result[i] /= sum
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,424 @@
+// Package repomap renders a compact outline of a Go project for the context of LLMs:
+// its file tree, the import graph of its packages and the exported symbols of the files
+// most relevant to the task, within a token budget.
+package repomap
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"maps"
+	"math"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/WinPooh32/go-coder/pkg/code/golang"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+// relevanceTemperature sharpens the similarities of files to the query, so the most
+// similar ones get most of random jumps of the ranking.
+const relevanceTemperature = 0.05
+
+// The parts of the budget the file tree and the import graph may take at most,
+// the rest is left for symbols.
+const (
+	treeShare    = 4
+	importsShare = 8
+)
+
+// skippedDirs are not shown in the file tree.
+var skippedDirs = []string{"testdata", "vendor", "node_modules"}
+
+// file is a Go file with exported symbols.
+type file struct {
+	path    string
+	symbols []string
+}
+
+// summary is the text of the file compared with the query.
+func (f file) summary() string {
+	return f.path + "\n" + strings.Join(f.symbols, "\n")
+}
+
+// Map is the outline of the module.
+type Map struct {
+	opts    options
+	tree    []string
+	imports []string
+	files   []file
+	// edges are the references between files by their indexes, the files
+	// without exported symbols follow the ones of the files slice.
+	edges map[int]map[int]float64
+	nodes int
+
+	mu      sync.Mutex
+	vectors map[string][]float32
+}
+
+// New collects the outline of the module.
+func New(module *golang.Module, opts ...Option) (*Map, error) {
+	o := options{
+		embedder: nil,
+		tokens:   defaultTokens,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	tree, err := fileTree(module.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := exportedFiles(module)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		opts:    o,
+		tree:    tree,
+		imports: importGraph(module),
+		files:   files,
+		edges:   nil,
+		nodes:   0,
+		mu:      sync.Mutex{},
+		vectors: map[string][]float32{},
+	}
+
+	m.edges, m.nodes = referenceGraph(files, module.FileReferences())
+
+	return m, nil
+}
+
+// Render renders the map of at most the limit of tokens. Files are ranked by how often
+// they are referenced and, with the embedder, by how similar they are to the query.
+func (m *Map) Render(ctx context.Context, query string) (string, error) {
+	teleport, err := m.similarities(ctx, query)
+	if err != nil {
+		return "", err
+	}
+
+	rank := pageRank(m.nodes, m.edges, teleport)
+
+	order := make([]int, len(m.files))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case rank[a] > rank[b]:
+			return -1
+		case rank[a] < rank[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	b := budget{sb: strings.Builder{}, left: m.opts.tokens * llm.CharsPerToken}
+
+	b.section("Files:", m.tree, b.left/treeShare)
+	b.section("Imports:", m.imports, b.left/importsShare)
+
+	blocks := make([]string, 0, len(order))
+
+	for _, i := range order {
+		f := m.files[i]
+		blocks = append(blocks, "  "+f.path+":\n    "+strings.Join(f.symbols, "\n    "))
+	}
+
+	b.section("Symbols:", blocks, b.left)
+
+	return b.sb.String(), nil
+}
+
+// similarities returns the teleport weights of files by their similarity to the query,
+// or nil without the embedder or the query.
+func (m *Map) similarities(ctx context.Context, query string) ([]float64, error) {
+	if m.opts.embedder == nil || query == "" || len(m.files) == 0 {
+		return nil, nil
+	}
+
+	q, err := m.opts.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+
+	sims := make([]float64, len(m.files))
+
+	for i, f := range m.files {
+		v, err := m.vector(ctx, f.summary())
+		if err != nil {
+			return nil, fmt.Errorf("embed %s: %w", f.path, err)
+		}
+
+		sims[i] = float64(llm.CosineSimilarity(q, v))
+	}
+
+	best := slices.Max(sims)
+
+	for i, s := range sims {
+		sims[i] = math.Exp((s - best) / relevanceTemperature)
+	}
+
+	return sims, nil
+}
+
+// vector returns the cached embedding vector of the text.
+func (m *Map) vector(ctx context.Context, text string) ([]float32, error) {
+	m.mu.Lock()
+	v, ok := m.vectors[text]
+	m.mu.Unlock()
+
+	if ok {
+		return v, nil
+	}
+
+	v, err := m.opts.embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+
+	m.mu.Lock()
+	m.vectors[text] = v
+	m.mu.Unlock()
+
+	return v, nil
+}
+
+// budget writes sections of lines while they fit.
+type budget struct {
+	sb   strings.Builder
+	left int
+}
+
+// section writes the title and as many lines as fit into the limit. The lines that don't
+// fit are skipped and counted at the end, the section is skipped when none fits.
+func (b *budget) section(title string, lines []string, limit int) {
+	if len(lines) == 0 {
+		return
+	}
+
+	limit = min(limit, b.left)
+	head := title + "\n"
+
+	if len(head) > limit {
+		return
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(head)
+
+	skipped := 0
+
+	for i, l := range lines {
+		more := ""
+		if rest := len(lines) - i - 1 + skipped; rest > 0 {
+			more = fmt.Sprintf("  ... %d more\n", rest)
+		}
+
+		if sb.Len()+len(l)+1+len(more) > limit {
+			skipped++
+
+			continue
+		}
+
+		sb.WriteString(l + "\n")
+	}
+
+	if skipped == len(lines) {
+		return
+	}
+
+	if skipped > 0 {
+		fmt.Fprintf(&sb, "  ... %d more\n", skipped)
+	}
+
+	b.sb.WriteString(sb.String())
+	b.left -= sb.Len()
+}
+
+// fileTree lists files of the directories under the root.
+func fileTree(root string) ([]string, error) {
+	dirs := map[string][]string{}
+
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if name != root && (strings.HasPrefix(d.Name(), ".") || slices.Contains(skippedDirs, d.Name())) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return fmt.Errorf("relative path: %w", err)
+		}
+
+		dir, base := path.Split(filepath.ToSlash(rel))
+		dirs[dir] = append(dirs[dir], base)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", root, err)
+	}
+
+	lines := make([]string, 0, len(dirs))
+
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		name := dir
+		if name == "" {
+			name = "./"
+		}
+
+		lines = append(lines, "  "+name+": "+strings.Join(dirs[dir], " "))
+	}
+
+	return lines, nil
+}
+
+// importGraph lists the packages of the module imported by every package.
+func importGraph(module *golang.Module) []string {
+	modPath := module.Path()
+
+	rel := func(pkgPath string) (string, bool) {
+		if pkgPath == modPath {
+			return ".", true
+		}
+
+		rest, ok := strings.CutPrefix(pkgPath, modPath+"/")
+
+		return rest, ok
+	}
+
+	var lines []string
+
+	for _, p := range module.Packages() {
+		from, _ := rel(p.Path)
+
+		var to []string
+
+		for _, imp := range p.Imports {
+			if r, ok := rel(imp); ok {
+				to = append(to, r)
+			}
+		}
+
+		if len(to) > 0 {
+			lines = append(lines, "  "+from+" -> "+strings.Join(to, ", "))
+		}
+	}
+
+	return lines
+}
+
+// exportedFiles returns the Go files with exported symbols sorted by path.
+func exportedFiles(module *golang.Module) ([]file, error) {
+	byPath := map[string]*file{}
+
+	for _, p := range module.Packages() {
+		symbols, err := module.Symbols(p.Path)
+		if err != nil {
+			return nil, fmt.Errorf("symbols of %s: %w", p.Path, err)
+		}
+
+		for _, s := range symbols {
+			if !exported(s.Name) || strings.HasSuffix(s.Range.File, "_test.go") {
+				continue
+			}
+
+			f, ok := byPath[s.Range.File]
+			if !ok {
+				f = &file{path: s.Range.File, symbols: nil}
+				byPath[s.Range.File] = f
+			}
+
+			f.symbols = append(f.symbols, outline(s))
+		}
+	}
+
+	files := make([]file, 0, len(byPath))
+
+	for _, name := range slices.Sorted(maps.Keys(byPath)) {
+		files = append(files, *byPath[name])
+	}
+
+	return files, nil
+}
+
+// exported reports whether the symbol and the type of the method are exported.
+func exported(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if !token.IsExported(part) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// outline returns the signature on one line, types without their fields and methods.
+func outline(s golang.Symbol) string {
+	sig := s.Signature
+
+	if s.Kind == golang.KindType || s.Kind == golang.KindInterface {
+		sig, _, _ = strings.Cut(sig, "\n")
+
+		return strings.TrimSuffix(sig, " {")
+	}
+
+	sig = strings.ReplaceAll(sig, "(\n", "(")
+	sig = strings.ReplaceAll(sig, ",\n)", ")")
+
+	return strings.Join(strings.Fields(sig), " ")
+}
+
+// referenceGraph numbers the files without exported symbols after the files and returns
+// the edges between them.
+func referenceGraph(files []file, refs map[string]map[string]int) (map[int]map[int]float64, int) {
+	index := make(map[string]int, len(files))
+
+	for i, f := range files {
+		index[f.path] = i
+	}
+
+	node := func(name string) int {
+		i, ok := index[name]
+		if !ok {
+			i = len(index)
+			index[name] = i
+		}
+
+		return i
+	}
+
+	edges := map[int]map[int]float64{}
+
+	for _, from := range slices.Sorted(maps.Keys(refs)) {
+		for _, to := range slices.Sorted(maps.Keys(refs[from])) {
+			f, t := node(from), node(to)
+
+			if edges[f] == nil {
+				edges[f] = map[int]float64{}
+			}
+
+			edges[f][t] = float64(refs[from][to])
+		}
+	}
+
+	return edges, len(index)
+}
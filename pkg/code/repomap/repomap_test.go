@@ -0,0 +1,95 @@
+package repomap_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/code/golang"
+	"github.com/WinPooh32/go-coder/pkg/code/repomap"
+	"github.com/WinPooh32/go-coder/pkg/llm/llmtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T) *golang.Module {
+	t.Helper()
+
+	m, err := golang.Load(context.Background(), "testdata/store")
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestMap_Render(t *testing.T) {
+	t.Parallel()
+
+	m, err := repomap.New(load(t))
+	require.NoError(t, err)
+
+	got, err := m.Render(context.Background(), "")
+	require.NoError(t, err)
+
+	assert.Equal(t, `Files:
+  ./: README.md go.mod store.go
+  billing/: billing.go
+  memory/: memory.go
+Imports:
+  billing -> .
+  memory -> .
+Symbols:
+  store.go:
+    type Item struct
+    type Store interface
+  billing/billing.go:
+    func Invoice(s store.Store, ids ...string) (int, error)
+  memory/memory.go:
+    type Memory struct
+    func New() *Memory
+    func (m *Memory) Get(id string) (store.Item, error)
+    func (m *Memory) Put(item store.Item) error
+`, got)
+}
+
+func TestMap_Render_Tokens(t *testing.T) {
+	t.Parallel()
+
+	const tokens = 50
+
+	m, err := repomap.New(load(t), repomap.WithTokens(tokens))
+	require.NoError(t, err)
+
+	got, err := m.Render(context.Background(), "")
+	require.NoError(t, err)
+
+	assert.LessOrEqual(t, len(got), tokens*4)
+	assert.Contains(t, got, "Symbols:\n  store.go:\n")
+	assert.Contains(t, got, "  ... 2 more\n")
+}
+
+func TestMap_Render_Query(t *testing.T) {
+	t.Parallel()
+
+	embedder := llmtest.NewEmbedder("test", 64)
+
+	m, err := repomap.New(load(t), repomap.WithEmbedder(embedder))
+	require.NoError(t, err)
+
+	got, err := m.Render(context.Background(), "Send the invoice with prices of the items")
+	require.NoError(t, err)
+
+	_, symbols, _ := strings.Cut(got, "Symbols:\n")
+	assert.True(t, strings.HasPrefix(symbols, "  billing/billing.go:\n"), symbols)
+
+	calls := embedder.Calls()
+
+	_, err = m.Render(context.Background(), "Keep items in memory")
+	require.NoError(t, err)
+	assert.Equal(t, calls+1, embedder.Calls(), "only the query is embedded again")
+
+	embedder.Err = errors.New("unavailable")
+
+	_, err = m.Render(context.Background(), "Keep items in memory")
+	require.ErrorIs(t, err, embedder.Err)
+}
@@ -0,0 +1,20 @@
+// Package billing bills customers.
+package billing
+
+import "example.com/store"
+
+// Invoice sums up the prices of the items.
+func Invoice(s store.Store, ids ...string) (int, error) {
+	total := 0
+
+	for _, id := range ids {
+		item, err := s.Get(id)
+		if err != nil {
+			return 0, err
+		}
+
+		total += item.Price
+	}
+
+	return total, nil
+}
@@ -0,0 +1,38 @@
+// Package memory keeps items in memory.
+package memory
+
+import (
+	"errors"
+
+	"example.com/store"
+)
+
+var errNotFound = errors.New("not found")
+
+// Memory is the store in memory.
+type Memory struct {
+	items map[string]store.Item
+}
+
+func New() *Memory {
+	return &Memory{items: map[string]store.Item{}}
+}
+
+func (m *Memory) Get(id string) (store.Item, error) {
+	item, ok := m.items[id]
+	if !ok {
+		return store.Item{}, errNotFound
+	}
+
+	return item, nil
+}
+
+func (m *Memory) Put(item store.Item) error {
+	m.items[item.ID] = item
+
+	return nil
+}
+
+func (m *Memory) reset() {
+	clear(m.items)
+}
@@ -0,0 +1,14 @@
+// Package store keeps items.
+package store
+
+// Item is a product with a price.
+type Item struct {
+	ID    string
+	Price int
+}
+
+// Store keeps items by their IDs.
+type Store interface {
+	Get(id string) (Item, error)
+	Put(item Item) error
+}
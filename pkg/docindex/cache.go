@@ -0,0 +1,69 @@
+package docindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/WinPooh32/go-coder/internal/fsutil"
+)
+
+// vectorCache is the persisted embedding vectors of chunks keyed by a hash of the content and the model.
+type vectorCache struct {
+	Model     string               `json:"model"`
+	Dimension int                  `json:"dimension"`
+	Vectors   map[string][]float32 `json:"vectors"`
+}
+
+// loadCache reads the cache file. The empty cache is returned if the file does not exist.
+func loadCache(filename string) (vectorCache, error) {
+	cache := vectorCache{
+		Model:     "",
+		Dimension: 0,
+		Vectors:   map[string][]float32{},
+	}
+
+	if filename == "" {
+		return cache, nil
+	}
+
+	b, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return cache, nil
+	} else if err != nil {
+		return cache, fmt.Errorf("read file %q: %w", filename, err)
+	}
+
+	if err := json.Unmarshal(b, &cache); err != nil {
+		return cache, fmt.Errorf("unmarshal json %q: %w", filename, err)
+	}
+
+	if cache.Vectors == nil {
+		cache.Vectors = map[string][]float32{}
+	}
+
+	return cache, nil
+}
+
+// saveCache writes the cache file atomically.
+func saveCache(filename string, cache vectorCache) error {
+	if filename == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return fmt.Errorf("make cache directory: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(filename, b); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	return nil
+}
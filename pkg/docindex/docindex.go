@@ -0,0 +1,215 @@
+// Package docindex implements embedding-based retrieval of documentation chunks
+// discovered by doctree.
+package docindex
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+// Result is a retrieved chunk of a document.
+type Result struct {
+	// URL is the URL of the document of the chunk.
+	URL   string
+	Chunk doctree.Chunk
+	// Score is the cosine similarity of the chunk to the query with the graph boost.
+	Score float32
+}
+
+type entry struct {
+	doc    *doctree.Document
+	chunk  doctree.Chunk
+	vector []float32
+}
+
+// Index keeps embedding vectors of the documentation chunks.
+type Index struct {
+	embedder llm.Embedder
+	opts     options
+
+	mu      sync.RWMutex
+	cache   vectorCache
+	entries []entry
+}
+
+// New creates an empty index. Cached vectors of another embedding model are discarded.
+func New(embedder llm.Embedder, opts ...Option) (*Index, error) {
+	o := options{
+		cacheFile:  "",
+		graphBoost: defaultGraphBoost,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cache, err := loadCache(o.cacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("load vectors cache: %w", err)
+	}
+
+	if model := modelName(embedder); cache.Model != model {
+		cache = vectorCache{
+			Model:     model,
+			Dimension: 0,
+			Vectors:   map[string][]float32{},
+		}
+	}
+
+	return &Index{
+		embedder: embedder,
+		opts:     o,
+		mu:       sync.RWMutex{},
+		cache:    cache,
+		entries:  nil,
+	}, nil
+}
+
+// Update replaces indexed chunks with the chunks of the documents.
+// Only new and changed chunks are embedded, vectors of removed chunks are dropped from the cache.
+func (idx *Index) Update(ctx context.Context, documents map[string]*doctree.Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	cache := vectorCache{
+		Model:     idx.cache.Model,
+		Dimension: idx.cache.Dimension,
+		Vectors:   make(map[string][]float32, len(idx.cache.Vectors)),
+	}
+
+	var entries []entry
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		doc := documents[url]
+
+		for _, chunk := range doc.Chunks {
+			text := chunkText(chunk)
+			key := llm.EmbeddingKey(cache.Model, text)
+
+			vec, ok := idx.cache.Vectors[key]
+			if !ok {
+				var err error
+
+				vec, err = idx.embedder.Embed(ctx, text)
+				if err != nil {
+					return fmt.Errorf("embed chunk %q: %w", chunk.ID, err)
+				}
+			}
+
+			if cache.Dimension != len(vec) {
+				if len(entries) > 0 || len(cache.Vectors) > 0 {
+					return fmt.Errorf("chunk %q: got vector of dimension %d, want %d", chunk.ID, len(vec), cache.Dimension)
+				}
+
+				// The first vector of the changed model sets the dimension.
+				cache.Dimension = len(vec)
+			}
+
+			cache.Vectors[key] = vec
+			entries = append(entries, entry{doc: doc, chunk: chunk, vector: vec})
+		}
+	}
+
+	if err := saveCache(idx.opts.cacheFile, cache); err != nil {
+		return fmt.Errorf("save vectors cache: %w", err)
+	}
+
+	idx.cache = cache
+	idx.entries = entries
+
+	return nil
+}
+
+// Retrieve returns k chunks most relevant to the query.
+// Chunks of documents linking to or linked by the documents of the top hits
+// get a boost proportional to the similarity of those hits.
+func (idx *Index) Retrieve(ctx context.Context, query string, k int) ([]Result, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	vec, err := idx.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if len(idx.entries) == 0 {
+		return nil, nil
+	}
+
+	if len(vec) != idx.cache.Dimension {
+		return nil, fmt.Errorf("query vector of dimension %d, want %d", len(vec), idx.cache.Dimension)
+	}
+
+	similarity := make([]float32, len(idx.entries))
+	for i, e := range idx.entries {
+		similarity[i] = llm.CosineSimilarity(vec, e.vector)
+	}
+
+	order := make([]int, len(idx.entries))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(similarity[b], similarity[a])
+	})
+
+	hits := order[:min(k, len(order))]
+
+	results := make([]Result, len(idx.entries))
+
+	for i, e := range idx.entries {
+		var boost float32
+
+		for _, h := range hits {
+			if hit := idx.entries[h]; hit.doc != e.doc && linked(hit.doc, e.doc) {
+				boost = max(boost, similarity[h])
+			}
+		}
+
+		results[i] = Result{
+			URL:   e.doc.URL,
+			Chunk: e.chunk,
+			Score: similarity[i] + idx.opts.graphBoost*boost,
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b Result) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	return results[:min(k, len(results))], nil
+}
+
+// linked reports whether one of the documents links to another.
+func linked(a, b *doctree.Document) bool {
+	return slices.Contains(a.LinkedBy, b) || slices.Contains(b.LinkedBy, a)
+}
+
+// chunkText returns the text of the chunk to embed. The heading path gives context to nested sections.
+func chunkText(chunk doctree.Chunk) string {
+	if len(chunk.Headings) <= 1 {
+		return chunk.Content
+	}
+
+	return chunk.HeadingPath() + "\n\n" + chunk.Content
+}
+
+func modelName(embedder llm.Embedder) string {
+	if namer, ok := embedder.(llm.ModelNamer); ok {
+		return namer.Model()
+	}
+
+	return ""
+}
@@ -0,0 +1,112 @@
+package docindex_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/docindex"
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+	"github.com/WinPooh32/go-coder/pkg/llm/llmtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildDocs(t *testing.T) map[string]*doctree.Document {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"index.md":   "# Overview\n\nThe project [design](storage.md) and [colors](colors.md).\n",
+		"storage.md": "# Storage\n\nTasks are kept in sqlite database tables.\n\n# Backups\n\nNightly copies of the tables.\n",
+		"colors.md":  "# Colors\n\nThe palette uses red, green and blue.\n",
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	docs, err := doctree.BuildGraph(context.Background(), []string{filepath.Join(dir, "index.md")})
+	require.NoError(t, err)
+
+	return docs
+}
+
+func resultIDs(results []docindex.Result) []string {
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, filepath.Base(r.Chunk.ID))
+	}
+
+	return ids
+}
+
+func TestIndex_Retrieve(t *testing.T) {
+	t.Parallel()
+
+	docs := buildDocs(t)
+
+	tests := []struct {
+		name  string
+		boost float32
+		want  []string
+	}{
+		{"similarity only", 0, []string{"storage.md#storage", "storage.md#backups"}},
+		{"linked documents boosted", 0.9, []string{"storage.md#storage", "index.md#overview"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			idx, err := docindex.New(llmtest.NewEmbedder("model", 64), docindex.WithGraphBoost(tt.boost))
+			require.NoError(t, err)
+			require.NoError(t, idx.Update(ctx, docs))
+
+			got, err := idx.Retrieve(ctx, "sqlite database tables", 2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resultIDs(got))
+			assert.GreaterOrEqual(t, got[0].Score, got[1].Score)
+		})
+	}
+}
+
+func TestIndex_Cache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	docs := buildDocs(t)
+	cacheFile := filepath.Join(t.TempDir(), "vectors.json")
+
+	embedder := llmtest.NewEmbedder("model", 64)
+
+	idx, err := docindex.New(embedder, docindex.WithCacheFile(cacheFile))
+	require.NoError(t, err)
+	require.NoError(t, idx.Update(ctx, docs))
+
+	chunks := embedder.Calls()
+	assert.Equal(t, 4, chunks)
+
+	require.NoError(t, idx.Update(ctx, docs))
+	assert.Equal(t, chunks, embedder.Calls(), "unchanged chunks must not be embedded again")
+
+	idx, err = docindex.New(embedder, docindex.WithCacheFile(cacheFile))
+	require.NoError(t, err)
+	require.NoError(t, idx.Update(ctx, docs))
+	assert.Equal(t, chunks, embedder.Calls(), "vectors must be loaded from the cache file")
+
+	other := llmtest.NewEmbedder("other", 32)
+
+	idx, err = docindex.New(other, docindex.WithCacheFile(cacheFile))
+	require.NoError(t, err)
+	require.NoError(t, idx.Update(ctx, docs))
+	assert.Equal(t, chunks, other.Calls(), "vectors of another model must be discarded")
+
+	got, err := idx.Retrieve(ctx, "red green blue palette", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"colors.md#colors"}, resultIDs(got))
+}
@@ -0,0 +1,28 @@
+package docindex
+
+const defaultGraphBoost = 0.1
+
+type options struct {
+	cacheFile  string
+	graphBoost float32
+}
+
+type Option func(*options)
+
+// WithCacheFile persists embedding vectors of chunks to the file,
+// so unchanged chunks are not embedded again on the next run.
+// Default: vectors are kept in memory only.
+func WithCacheFile(filename string) Option {
+	return func(opts *options) {
+		opts.cacheFile = filename
+	}
+}
+
+// WithGraphBoost sets the weight of the similarity of the top hits
+// added to the score of chunks of documents linked with them.
+// Default: 0.1.
+func WithGraphBoost(weight float32) Option {
+	return func(opts *options) {
+		opts.graphBoost = weight
+	}
+}
@@ -0,0 +1,165 @@
+package doctree
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// AsciiDocLoader loads AsciiDoc documents as is with section titles as headings.
+// It extracts xref, link and URL macros and <<cross references>>.
+type AsciiDocLoader struct{}
+
+func (AsciiDocLoader) Match(url string) bool {
+	return hasExt(url, ".adoc", ".asciidoc", ".asc")
+}
+
+var (
+	// adocTitleRe matches = Title lines. Markdown-style # titles are supported by Asciidoctor too.
+	adocTitleRe = regexp.MustCompile(`^(={1,6}|#{1,6})\s+(\S.*?)\s*$`)
+	// adocAnchorRe matches [[id]] and [#id] block anchors.
+	adocAnchorRe = regexp.MustCompile(`^\[(?:\[([^\],]+)(?:,[^\]]*)?\]|#([^\].,%]+)[^\]]*)\]\s*$`)
+	// adocMacroRe matches xref:target[text], link:target[text] and https://url[text].
+	adocMacroRe = regexp.MustCompile(`(?:(xref|link):([^\s\[]+)|(https?://[^\s\[]+))\[([^\]]*)\]`)
+	// adocXrefRe matches <<target>> and <<target,text>>.
+	adocXrefRe = regexp.MustCompile(`<<([^,>]+)(?:,\s*([^>]*))?>>`)
+	// adocDelimiters start and end blocks without markup.
+	adocDelimiters = []string{"----", "....", "````", "++++", "////"}
+)
+
+func (AsciiDocLoader) Load(_ string, source []byte) (Parsed, error) {
+	lines := strings.Split(string(source), "\n")
+	parsed := Parsed{Text: string(source), Links: nil, Headings: nil}
+
+	var (
+		delimiter string
+		anchor    string
+	)
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+
+		if delimiter != "" {
+			if line == delimiter {
+				delimiter = ""
+			}
+
+			continue
+		}
+
+		if isAdocDelimiter(line) {
+			delimiter = line
+
+			continue
+		}
+
+		if m := adocAnchorRe.FindStringSubmatch(line); m != nil {
+			anchor = m[1] + m[2]
+
+			continue
+		}
+
+		if m := adocTitleRe.FindStringSubmatch(line); m != nil {
+			if anchor == "" {
+				anchor = adocID(m[2])
+			}
+
+			parsed.Headings = append(parsed.Headings, Heading{
+				Level:  len(m[1]),
+				Text:   m[2],
+				Line:   i + 1,
+				Anchor: anchor,
+			})
+
+			anchor = ""
+
+			continue
+		}
+
+		if strings.TrimSpace(line) != "" {
+			anchor = ""
+		}
+
+		parsed.Links = append(parsed.Links, adocLinks(line, i+1)...)
+	}
+
+	return parsed, nil
+}
+
+func adocLinks(line string, lineNo int) []Link {
+	var links []Link
+
+	for _, m := range adocMacroRe.FindAllStringSubmatchIndex(line, -1) {
+		dest := ""
+		if m[4] >= 0 {
+			dest = line[m[4]:m[5]]
+		} else {
+			dest = line[m[6]:m[7]]
+		}
+
+		text := line[m[8]:m[9]]
+		if text == "" {
+			text = dest
+		}
+
+		links = append(links, newLink(LinkInline, text, dest, lineNo, m[0]))
+	}
+
+	for _, m := range adocXrefRe.FindAllStringSubmatchIndex(line, -1) {
+		dest := line[m[2]:m[3]]
+
+		text := dest
+		if m[4] >= 0 {
+			text = line[m[4]:m[5]]
+		}
+
+		if !strings.Contains(dest, "#") && !hasExt(dest, ".adoc", ".asciidoc", ".asc") {
+			// Reference to an anchor of the same document.
+			dest = "#" + dest
+		}
+
+		links = append(links, newLink(LinkReference, text, dest, lineNo, m[0]))
+	}
+
+	return links
+}
+
+func isAdocDelimiter(line string) bool {
+	for _, d := range adocDelimiters {
+		if strings.HasPrefix(line, d) && strings.Count(line, line[:1]) == len(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adocID returns the section ID generated by Asciidoctor by default:
+// lowercase words joined by underscores with the underscore prefix.
+func adocID(title string) string {
+	var builder strings.Builder
+
+	builder.WriteByte('_')
+
+	sep := false
+
+	for _, r := range strings.ToLower(title) {
+		if isWordRune(r) {
+			if sep && builder.Len() > 1 {
+				builder.WriteByte('_')
+			}
+
+			builder.WriteRune(r)
+
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+
+	return builder.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,184 @@
+package doctree
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/WinPooh32/go-coder/internal/fsutil"
+)
+
+// Changes are URLs of documents changed since the previous build of the graph.
+type Changes struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty reports whether nothing changed.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Removed) == 0
+}
+
+// Contains reports whether the document was added, changed or removed.
+func (c Changes) Contains(url string) bool {
+	return slices.Contains(c.Added, url) || slices.Contains(c.Changed, url) || slices.Contains(c.Removed, url)
+}
+
+type cacheEntry struct {
+	Hash         string    `json:"hash"`
+	Size         int64     `json:"size,omitempty"`
+	ModTime      time.Time `json:"mod_time"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Content      string    `json:"content"`
+}
+
+// Cache keeps fetched documents between runs. Local files are read again only
+// when their size or modification time changes, remote documents are revalidated
+// by ETag and Last-Modified headers.
+//
+// A cache must not be used by concurrent builds.
+type Cache struct {
+	filename string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	prev    map[string]string
+	changes Changes
+}
+
+// OpenCache reads the cache file. The empty cache is returned if the file does not exist.
+// The empty filename makes the cache in-memory only.
+func OpenCache(filename string) (*Cache, error) {
+	c := &Cache{
+		filename: filename,
+		mu:       sync.Mutex{},
+		entries:  map[string]cacheEntry{},
+		prev:     nil,
+		changes:  Changes{Added: nil, Changed: nil, Removed: nil},
+	}
+
+	if filename == "" {
+		return c, nil
+	}
+
+	b, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read file %q: %w", filename, err)
+	}
+
+	if err := json.Unmarshal(b, &c.entries); err != nil {
+		return nil, fmt.Errorf("unmarshal json %q: %w", filename, err)
+	}
+
+	return c, nil
+}
+
+// Changes returns the changes found by the latest build of the graph.
+func (c *Cache) Changes() Changes {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.changes
+}
+
+func (c *Cache) get(url string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[url]
+
+	return e, ok
+}
+
+func (c *Cache) put(url string, e cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[url] = e
+}
+
+// begin remembers the cached documents before the build.
+func (c *Cache) begin() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prev = make(map[string]string, len(c.entries))
+	for url, e := range c.entries {
+		c.prev[url] = e.Hash
+	}
+}
+
+// finish compares the documents with the cached ones, drops the documents missing in the graph
+// and saves the cache.
+func (c *Cache) finish(documents map[string]*Document) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changes := Changes{Added: nil, Changed: nil, Removed: nil}
+
+	for _, url := range slices.Sorted(maps.Keys(c.entries)) {
+		if _, ok := documents[url]; !ok {
+			delete(c.entries, url)
+		}
+	}
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		hash, ok := c.prev[url]
+
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, url)
+		case hash != c.entries[url].Hash:
+			changes.Changed = append(changes.Changed, url)
+		}
+	}
+
+	for _, url := range slices.Sorted(maps.Keys(c.prev)) {
+		if _, ok := documents[url]; !ok {
+			changes.Removed = append(changes.Removed, url)
+		}
+	}
+
+	c.changes = changes
+	c.prev = nil
+
+	return c.save()
+}
+
+func (c *Cache) save() error {
+	if c.filename == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.filename), os.ModePerm); err != nil {
+		return fmt.Errorf("make cache directory: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(c.filename, b); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	return nil
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
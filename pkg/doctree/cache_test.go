@@ -0,0 +1,156 @@
+package doctree_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDoc(t *testing.T, name, content string, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(name, modTime, modTime))
+}
+
+func TestCache_Local(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, ".cache", "doctree.json")
+
+	index := filepath.Join(dir, "index.md")
+	a := filepath.Join(dir, "a.md")
+	b := filepath.Join(dir, "b.md")
+
+	modTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	writeDoc(t, index, "[a](a.md) [b](b.md)", modTime)
+	writeDoc(t, a, "# A", modTime)
+	writeDoc(t, b, "# B", modTime)
+
+	build := func() doctree.Changes {
+		t.Helper()
+
+		cache, err := doctree.OpenCache(cacheFile)
+		require.NoError(t, err)
+
+		_, err = doctree.BuildGraph(ctx, []string{index}, doctree.WithCache(cache))
+		require.NoError(t, err)
+
+		return cache.Changes()
+	}
+
+	assert.Equal(t, doctree.Changes{Added: []string{a, b, index}, Changed: nil, Removed: nil}, build())
+	assert.True(t, build().Empty())
+
+	modTime = modTime.Add(time.Hour)
+
+	writeDoc(t, index, "[a](a.md)", modTime)
+	writeDoc(t, a, "# A changed", modTime)
+	writeDoc(t, b, "# B", modTime) // Touched only.
+
+	changes := build()
+	assert.Equal(t, doctree.Changes{Added: nil, Changed: []string{a, index}, Removed: []string{b}}, changes)
+	assert.True(t, changes.Contains(b))
+	assert.True(t, build().Empty())
+}
+
+func TestCache_Remote(t *testing.T) {
+	t.Parallel()
+
+	var full, notModified atomic.Int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + r.URL.Path + `"`
+
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		full.Add(1)
+		w.Header().Set("ETag", etag)
+
+		_, _ = io.WriteString(w, "[next](next.md)")
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+
+	cache, err := doctree.OpenCache("")
+	require.NoError(t, err)
+
+	for range 2 {
+		docs, err := doctree.BuildGraph(ctx, []string{srv.URL + "/index.md"}, doctree.WithCache(cache), doctree.WithMaxDepth(1))
+		require.NoError(t, err)
+		assert.Equal(t, "[next](next.md)", docs[srv.URL+"/next.md"].Content)
+	}
+
+	assert.Equal(t, int64(2), full.Load())
+	assert.Equal(t, int64(2), notModified.Load())
+	assert.True(t, cache.Changes().Empty())
+}
+
+// mapFetcher fetches the documents of its scheme from the map.
+type mapFetcher struct {
+	docs map[string]string
+}
+
+func (mapFetcher) Match(url string) bool {
+	return strings.HasPrefix(url, "go:")
+}
+
+func (f mapFetcher) Fetch(_ context.Context, url string) ([]byte, error) {
+	return []byte(f.docs[url]), nil
+}
+
+func (mapFetcher) Load(url string, source []byte) (doctree.Parsed, error) {
+	return doctree.MarkdownLoader{}.Load(url, source)
+}
+
+func TestCache_Fetcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, "doctree.json")
+
+	index := filepath.Join(dir, "index.md")
+	writeDoc(t, index, "[pkg](go:pkg)", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	fetcher := mapFetcher{docs: map[string]string{"go:pkg": "# Package"}}
+
+	build := func() doctree.Changes {
+		t.Helper()
+
+		cache, err := doctree.OpenCache(cacheFile)
+		require.NoError(t, err)
+
+		_, err = doctree.BuildGraph(ctx, []string{index}, doctree.WithCache(cache), doctree.WithLoaders(fetcher))
+		require.NoError(t, err)
+
+		return cache.Changes()
+	}
+
+	assert.Equal(t, doctree.Changes{Added: []string{index, "go:pkg"}, Changed: nil, Removed: nil}, build())
+	assert.True(t, build().Empty(), "unchanged fetched documents must not be reported")
+
+	fetcher.docs["go:pkg"] = "# Package\n\nNew function."
+
+	assert.Equal(t, doctree.Changes{Added: nil, Changed: []string{"go:pkg"}, Removed: nil}, build())
+}
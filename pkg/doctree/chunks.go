@@ -0,0 +1,196 @@
+package doctree
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrDocumentNotFound is returned when a link points to a document missing in the graph.
+	ErrDocumentNotFound = errors.New("document not found")
+	// ErrAnchorNotFound is returned when a link points to a missing section of a document.
+	ErrAnchorNotFound = errors.New("anchor not found")
+)
+
+// HeadingPathSeparator separates headings in [Chunk.HeadingPath].
+const HeadingPathSeparator = " > "
+
+// Chunk is a heading-scoped section of a document.
+// A chunk spans from its heading to the next heading of any level,
+// so subsections are separate chunks.
+type Chunk struct {
+	// ID is the document URL with the anchor fragment, e.g. "docs/spec.md#storage".
+	// The text before the first heading has the document URL as ID.
+	ID string
+	// Anchor is the GitHub-style slug of the heading.
+	Anchor string
+	// Heading is the heading text.
+	Heading string
+	// Level is the heading level, 0 for the text before the first heading.
+	Level int
+	// Headings are the heading texts from the top-level heading to this chunk.
+	Headings []string
+	// StartLine and EndLine are 1-based inclusive line range of the chunk in the document.
+	StartLine int
+	EndLine   int
+	Content   string
+}
+
+// HeadingPath returns the headings path of the chunk, e.g. "Design > Storage".
+func (c Chunk) HeadingPath() string {
+	return strings.Join(c.Headings, HeadingPathSeparator)
+}
+
+// Section returns the chunk with the anchor followed by the chunks of its subsections.
+// The empty anchor returns all chunks of the document.
+func (d *Document) Section(anchor string) ([]Chunk, error) {
+	if anchor == "" {
+		return d.Chunks, nil
+	}
+
+	for i, chunk := range d.Chunks {
+		if chunk.Anchor != anchor || chunk.Level == 0 {
+			continue
+		}
+
+		end := i + 1
+		for end < len(d.Chunks) && d.Chunks[end].Level > chunk.Level {
+			end++
+		}
+
+		return d.Chunks[i:end], nil
+	}
+
+	return nil, fmt.Errorf("%w: %q in %q", ErrAnchorNotFound, anchor, d.URL)
+}
+
+// ResolveLink returns the chunks the link points to.
+func ResolveLink(documents map[string]*Document, link Link) ([]Chunk, error) {
+	doc, ok := documents[link.URL]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrDocumentNotFound, link.URL)
+	}
+
+	return doc.Section(link.Fragment)
+}
+
+// buildChunks splits the text by the headings.
+func buildChunks(url, text string, headings []Heading) []Chunk {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	var (
+		chunks  []Chunk
+		parents []Chunk
+		slugs   = make(map[string]int)
+	)
+
+	preamble := Chunk{
+		ID:        url,
+		Anchor:    "",
+		Heading:   "",
+		Level:     0,
+		Headings:  nil,
+		StartLine: 1,
+		EndLine:   0,
+		Content:   "",
+	}
+	chunks = append(chunks, preamble)
+
+	for _, heading := range headings {
+		last := chunks[len(chunks)-1]
+		if heading.Line < 1 || heading.Line > len(lines) || last.Level > 0 && heading.Line <= last.StartLine {
+			// Headings must be ordered and within the text.
+			continue
+		}
+
+		anchor := heading.Anchor
+		if anchor == "" {
+			anchor = uniqueSlug(slugs, heading.Text)
+		}
+
+		for len(parents) > 0 && parents[len(parents)-1].Level >= heading.Level {
+			parents = parents[:len(parents)-1]
+		}
+
+		path := make([]string, 0, len(parents)+1)
+		for _, parent := range parents {
+			path = append(path, parent.Heading)
+		}
+
+		path = append(path, heading.Text)
+
+		chunk := Chunk{
+			ID:        url + "#" + anchor,
+			Anchor:    anchor,
+			Heading:   heading.Text,
+			Level:     heading.Level,
+			Headings:  path,
+			StartLine: heading.Line,
+			EndLine:   0,
+			Content:   "",
+		}
+
+		chunks[len(chunks)-1].EndLine = heading.Line - 1
+		chunks = append(chunks, chunk)
+		parents = append(parents, chunk)
+	}
+
+	chunks[len(chunks)-1].EndLine = len(lines)
+
+	result := chunks[:0]
+
+	for _, chunk := range chunks {
+		for chunk.EndLine > chunk.StartLine && strings.TrimSpace(lines[chunk.EndLine-1]) == "" {
+			chunk.EndLine--
+		}
+
+		chunk.Content = strings.Join(lines[chunk.StartLine-1:chunk.EndLine], "\n")
+
+		if chunk.Level == 0 && strings.TrimSpace(chunk.Content) == "" {
+			continue
+		}
+
+		result = append(result, chunk)
+	}
+
+	return result
+}
+
+// uniqueSlug returns the GitHub-style anchor of the heading,
+// suffixed with a number for repeated headings.
+func uniqueSlug(slugs map[string]int, heading string) string {
+	slug := slugify(heading)
+
+	n, exists := slugs[slug]
+	slugs[slug] = n + 1
+
+	if !exists {
+		return slug
+	}
+
+	return slug + "-" + strconv.Itoa(n)
+}
+
+// slugify converts the heading to an anchor the way GitHub does:
+// lowercase, punctuation removed and spaces replaced by hyphens.
+func slugify(heading string) string {
+	var builder strings.Builder
+
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			builder.WriteRune(r)
+		case r == ' ':
+			builder.WriteRune('-')
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,77 @@
+package doctree_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Chunks(t *testing.T) {
+	t.Parallel()
+
+	url := filepath.Join("testdata", "spec.md")
+
+	doc, err := doctree.NewDocument(context.Background(), url)
+	require.NoError(t, err)
+
+	want := []doctree.Chunk{
+		{
+			ID: url, Anchor: "", Heading: "", Level: 0, Headings: nil,
+			StartLine: 1, EndLine: 1, Content: "Intro text.",
+		},
+		{
+			ID: url + "#design", Anchor: "design", Heading: "Design", Level: 1, Headings: []string{"Design"},
+			StartLine: 3, EndLine: 5, Content: "# Design\n\nOverview of the [storage](#storage-1).",
+		},
+		{
+			ID: url + "#storage", Anchor: "storage", Heading: "Storage", Level: 2, Headings: []string{"Design", "Storage"},
+			StartLine: 7, EndLine: 9, Content: "## Storage\n\nFiles.",
+		},
+		{
+			ID: url + "#storage-1", Anchor: "storage-1", Heading: "Storage", Level: 2, Headings: []string{"Design", "Storage"},
+			StartLine: 11, EndLine: 13, Content: "## Storage\n\nAgain.",
+		},
+		{
+			ID: url + "#setext-api", Anchor: "setext-api", Heading: "Setext API", Level: 1, Headings: []string{"Setext API"},
+			StartLine: 15, EndLine: 20, Content: "Setext API\n==========\n\n```go\n# not a heading\n```",
+		},
+	}
+
+	assert.Equal(t, want, doc.Chunks)
+	assert.Equal(t, "Design > Storage", doc.Chunks[3].HeadingPath())
+}
+
+func TestResolveLink(t *testing.T) {
+	t.Parallel()
+
+	url := filepath.Join("testdata", "spec.md")
+
+	documents, err := doctree.BuildGraph(context.Background(), []string{url})
+	require.NoError(t, err)
+
+	doc := documents[url]
+	require.Len(t, doc.References, 1)
+
+	chunks, err := doctree.ResolveLink(documents, doc.References[0])
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, url+"#storage-1", chunks[0].ID)
+
+	chunks, err = doc.Section("design")
+	require.NoError(t, err)
+	assert.Len(t, chunks, 3, "section must include its subsections")
+
+	chunks, err = doc.Section("")
+	require.NoError(t, err)
+	assert.Equal(t, doc.Chunks, chunks)
+
+	_, err = doc.Section("missing")
+	require.ErrorIs(t, err, doctree.ErrAnchorNotFound)
+
+	_, err = doctree.ResolveLink(documents, doctree.Link{URL: "missing.md"})
+	require.ErrorIs(t, err, doctree.ErrDocumentNotFound)
+}
@@ -3,173 +3,212 @@ package doctree
 import (
 	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"maps"
-	"net/http"
-	"os"
-	"path/filepath"
-	"regexp"
 	"slices"
 	"strings"
+	"sync"
 )
 
-// Document represents a Markdown document.
+// Document represents a document of any supported format.
 type Document struct {
-	URL      string
-	Content  string
-	Links    []string
+	URL string
+	// Content is the text of the document. Formats other than Markdown and plain text
+	// are normalized by their loaders.
+	Content string
+	// Links are unique URLs of the linked documents.
+	Links []string
+	// References are all document links with their anchors and positions,
+	// including links to sections of the same document.
+	References []Link
+	// Chunks are the heading-scoped sections of the document.
+	Chunks   []Chunk
 	LinkedBy []*Document
 }
 
 // NewDocument creates a new Document from the given URL.
-func NewDocument(url string) (*Document, error) {
-	content, err := fetchContent(url)
+func NewDocument(ctx context.Context, url string, opts ...Option) (*Document, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return newDocument(ctx, url, &o)
+}
+
+func newDocument(ctx context.Context, url string, opts *options) (*Document, error) {
+	loader := findLoader(opts.loaders, url)
+	if loader == nil {
+		// Unknown formats are read as Markdown.
+		loader = MarkdownLoader{}
+	}
+
+	source, err := fetchSource(ctx, url, loader, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	parsed, err := loader.Load(url, source)
+	if err != nil {
+		return nil, fmt.Errorf("load %q: %w", url, err)
+	}
+
 	doc := &Document{
-		URL:      url,
-		Content:  content,
-		Links:    nil,
-		LinkedBy: nil,
+		URL:        url,
+		Content:    parsed.Text,
+		Links:      nil,
+		References: resolveLinks(url, parsed.Links, opts.loaders),
+		Chunks:     buildChunks(url, parsed.Text, parsed.Headings),
+		LinkedBy:   nil,
 	}
 
-	doc.Links = doc.extractLinks()
+	doc.Links = linkedURLs(doc.URL, doc.References)
 
 	return doc, nil
 }
 
-// fetchContent reads the content from a URL (local file or HTTP/HTTPS).
-func fetchContent(url string) (string, error) {
-	if isFileURL(url) {
-		return readFile(url)
+// BuildGraph builds a graph of documents based on their links.
+// The root documents are always fetched and must be readable,
+// linked documents are fetched level by level within the configured limits
+// and skipped with a warning when they can't be read.
+func BuildGraph(ctx context.Context, urls []string, opts ...Option) (map[string]*Document, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	return readHTTP(url)
-}
-
-// isFileURL checks if the given URL is a local file path.
-func isFileURL(url string) bool {
-	return strings.HasPrefix(url, "file://") || (!strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://"))
-}
-
-// readFile reads content from a local file.
-func readFile(url string) (string, error) {
-	url = strings.TrimPrefix(url, "file://")
+	documents := make(map[string]*Document)
 
-	data, err := os.ReadFile(url)
-	if err != nil {
-		return "", fmt.Errorf("os read file %q: %w", url, err)
+	if o.cache != nil {
+		o.cache.begin()
 	}
 
-	return string(data), nil
-}
+	roots := unique(urls, documents, &o)
 
-// readHTTP reads content from an HTTP/HTTPS URL.
-func readHTTP(url string) (string, error) {
-	client := http.DefaultClient
-
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
-	if err != nil {
-		return "", fmt.Errorf("new get req: %w", err)
+	level, errs := fetchAll(ctx, roots, &o)
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("read root document %q: %w", roots[i], err)
+		}
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("get %q: %w", url, err)
-	}
-	defer resp.Body.Close()
+	for depth := 1; len(level) > 0; depth++ {
+		for _, doc := range level {
+			documents[doc.URL] = doc
+		}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
-	}
+		if o.maxDepth >= 0 && depth > o.maxDepth {
+			break
+		}
 
-	return string(data), nil
-}
+		var links []string
+
+		for _, doc := range level {
+			for _, link := range doc.Links {
+				if o.allowed(doc.URL, link) {
+					links = append(links, link)
+				}
+			}
+		}
 
-// extractLinks extracts all links from the Markdown content.
-func (d *Document) extractLinks() []string {
-	const matchGroupCount = 2
+		links = unique(links, documents, &o)
 
-	re := regexp.MustCompile(`\[(.*)\]\((.*(\.md|\.txt|\.json))\)`)
-	matches := re.FindAllStringSubmatch(d.Content, -1)
+		docs, errs := fetchAll(ctx, links, &o)
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("build graph: %w", err)
+		}
 
-	var links []string
+		level = level[:0]
 
-	for _, match := range matches {
-		if len(match) > matchGroupCount {
-			url := match[2]
+		for i, doc := range docs {
+			if errs[i] != nil {
+				slog.Warn("read linked document",
+					slog.String("url", links[i]),
+					slog.String("error", errs[i].Error()),
+				)
 
-			if !isFileURL(d.URL) && isFileURL(url) {
-				// Processing relative links on remote documents is not trivial.
-				// Then skip them.
 				continue
 			}
 
-			if isFileURL(url) && !strings.HasPrefix(url, "file://") {
-				directory := filepath.Dir(strings.TrimPrefix(d.URL, "file://"))
-				url = filepath.Join(directory, url)
-			}
+			level = append(level, doc)
+		}
+	}
+
+	linkDocuments(documents)
 
-			links = append(links, url)
+	if o.cache != nil {
+		if err := o.cache.finish(documents); err != nil {
+			return nil, fmt.Errorf("save cache: %w", err)
 		}
 	}
 
-	return links
+	return documents, nil
 }
 
-// BuildGraph builds a graph of documents based on their links.
-func BuildGraph(urls []string) (map[string]*Document, error) {
-	documents := make(map[string]*Document)
+// unique returns the URLs missing in the documents without duplicates,
+// truncated by the documents limit.
+func unique(urls []string, documents map[string]*Document, opts *options) []string {
+	var result []string
 
 	for _, url := range urls {
-		if _, exists := documents[url]; !exists {
-			doc, err := NewDocument(url)
-			if err != nil {
-				return nil, err
-			}
+		if opts.maxDocuments > 0 && len(documents)+len(result) >= opts.maxDocuments {
+			break
+		}
 
-			documents[url] = doc
+		if _, exists := documents[url]; exists || slices.Contains(result, url) {
+			continue
 		}
+
+		result = append(result, url)
 	}
 
-	for hasNewDoc := true; hasNewDoc; {
-		hasNewDoc = false
+	return result
+}
 
-		for _, doc := range documents {
-			for _, link := range doc.Links {
-				linkedDoc, exists := documents[link]
-
-				if exists {
-					if !slices.ContainsFunc(linkedDoc.LinkedBy, func(d *Document) bool {
-						return doc.URL == d.URL
-					}) {
-						linkedDoc.LinkedBy = append(linkedDoc.LinkedBy, doc)
-					}
-				} else {
-					newDoc, err := NewDocument(link)
-					if err != nil {
-						slog.Warn("read linked document",
-							slog.String("url", link),
-							slog.String("error", err.Error()),
-						)
-
-						continue
-					}
-
-					documents[link] = newDoc
-					newDoc.LinkedBy = append(newDoc.LinkedBy, doc)
-
-					hasNewDoc = true
-				}
+// fetchAll fetches the documents concurrently by the bounded number of workers.
+// The results are in the order of the URLs.
+func fetchAll(ctx context.Context, urls []string, opts *options) ([]*Document, []error) {
+	docs := make([]*Document, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, opts.workers)
+
+	for i, url := range urls {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			docs[i], errs[i] = newDocument(ctx, url, opts)
+		}()
+	}
+
+	wg.Wait()
+
+	return docs, errs
+}
+
+// linkDocuments fills LinkedBy of the documents in the order of their URLs.
+func linkDocuments(documents map[string]*Document) {
+	urls := slices.Sorted(maps.Keys(documents))
+
+	for _, url := range urls {
+		doc := documents[url]
+
+		for _, link := range doc.Links {
+			linkedDoc, exists := documents[link]
+			if !exists || slices.Contains(linkedDoc.LinkedBy, doc) {
+				continue
 			}
+
+			linkedDoc.LinkedBy = append(linkedDoc.LinkedBy, doc)
 		}
 	}
-
-	return documents, nil
 }
 
 // FormatGraph reurns formatted string representation of the graph of documents.
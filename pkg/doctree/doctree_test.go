@@ -1,8 +1,15 @@
 package doctree_test
 
 import (
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/WinPooh32/go-coder/pkg/doctree"
@@ -48,7 +55,7 @@ func TestNewDocument(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := doctree.NewDocument(tt.args.url)
+			got, err := doctree.NewDocument(context.Background(), tt.args.url)
 			require.NoError(t, err)
 
 			// Compare URLs and Content directly
@@ -117,7 +124,7 @@ func TestBuildGraph(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := doctree.BuildGraph(tt.args.urls)
+			got, err := doctree.BuildGraph(context.Background(), tt.args.urls)
 			require.NoError(t, err)
 
 			for k, v := range got {
@@ -201,3 +208,241 @@ Linked By:
 		})
 	}
 }
+
+func TestNewDocument_References(t *testing.T) {
+	t.Parallel()
+
+	url := filepath.Join("testdata", "links.md")
+
+	got, err := doctree.NewDocument(context.Background(), url)
+	require.NoError(t, err)
+
+	want := []doctree.Link{
+		{
+			Kind: doctree.LinkInline, Text: "first", Title: "", Destination: "sample.md",
+			URL: filepath.Join("testdata", "sample.md"), Fragment: "", Line: 3, Column: 5,
+		},
+		{
+			Kind: doctree.LinkInline, Text: "second", Title: "", Destination: "another-document.md#another-document",
+			URL: filepath.Join("testdata", "another-document.md"), Fragment: "another-document", Line: 3, Column: 28,
+		},
+		{
+			Kind: doctree.LinkReference, Text: "design", Title: "", Destination: "docs/design%20notes.md#storage",
+			URL: filepath.Join("testdata", "docs", "design notes.md"), Fragment: "storage", Line: 5, Column: 18,
+		},
+		{
+			Kind: doctree.LinkReference, Text: "shortcut", Title: "", Destination: "../README.md",
+			URL: "README.md", Fragment: "", Line: 5, Column: 41,
+		},
+		{
+			Kind: doctree.LinkInline, Text: "link", Title: "Sample title", Destination: "sample.md",
+			URL: filepath.Join("testdata", "sample.md"), Fragment: "", Line: 6, Column: 8,
+		},
+		{
+			Kind: doctree.LinkInline, Text: "section", Title: "", Destination: "#code",
+			URL: url, Fragment: "code", Line: 6, Column: 47,
+		},
+		{
+			Kind: doctree.LinkAuto, Text: "https://example.com/docs/readme.md", Title: "",
+			Destination: "https://example.com/docs/readme.md",
+			URL:         "https://example.com/docs/readme.md", Fragment: "", Line: 8, Column: 10,
+		},
+	}
+
+	assert.Equal(t, want, got.References)
+	assert.Equal(t, []string{
+		filepath.Join("testdata", "sample.md"),
+		filepath.Join("testdata", "another-document.md"),
+		filepath.Join("testdata", "docs", "design notes.md"),
+		"README.md",
+		"https://example.com/docs/readme.md",
+	}, got.Links)
+}
+
+func TestNewDocument_RemoteRelativeLinks(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "[guide](guide.md#intro), [spec](../api/spec.json) and [root](/index.md)\n")
+	}))
+	t.Cleanup(srv.Close)
+
+	got, err := doctree.NewDocument(context.Background(), srv.URL+"/docs/index.md")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		srv.URL + "/docs/guide.md",
+		srv.URL + "/api/spec.json",
+		srv.URL + "/index.md",
+	}, got.Links)
+	assert.Equal(t, "intro", got.References[0].Fragment)
+}
+
+func newDocsServer(t *testing.T, docs map[string]string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, content)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestBuildGraph_Remote(t *testing.T) {
+	t.Parallel()
+
+	srv := newDocsServer(t, map[string]string{
+		"/index.md":     "[b](b.md) [c](c.md) [missing](missing.md) [other](https://other.example/x.md)",
+		"/b.md":         "[d](sub/d.md)",
+		"/c.md":         "[index](index.md)",
+		"/sub/d.md":     "[large](large.md)",
+		"/sub/large.md": strings.Repeat("x", 100),
+	})
+
+	tests := []struct {
+		name string
+		opts []doctree.Option
+		want []string
+	}{
+		{
+			name: "all allowed documents",
+			opts: []doctree.Option{doctree.WithAllowedHosts(strings.TrimPrefix(srv.URL, "http://"))},
+			want: []string{"/b.md", "/c.md", "/index.md", "/sub/d.md", "/sub/large.md"},
+		},
+		{
+			name: "max depth",
+			opts: []doctree.Option{doctree.WithAllowedHosts("127.0.0.1"), doctree.WithMaxDepth(1)},
+			want: []string{"/b.md", "/c.md", "/index.md"},
+		},
+		{
+			name: "max documents",
+			opts: []doctree.Option{doctree.WithAllowedHosts("127.0.0.1"), doctree.WithMaxDocuments(2)},
+			want: []string{"/b.md", "/index.md"},
+		},
+		{
+			name: "max size",
+			opts: []doctree.Option{doctree.WithAllowedHosts("127.0.0.1"), doctree.WithMaxSize(99), doctree.WithWorkers(1)},
+			want: []string{"/b.md", "/c.md", "/index.md", "/sub/d.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := doctree.BuildGraph(context.Background(), []string{srv.URL + "/index.md"}, tt.opts...)
+			require.NoError(t, err)
+
+			want := make([]string, 0, len(tt.want))
+			for _, path := range tt.want {
+				want = append(want, srv.URL+path)
+			}
+
+			assert.ElementsMatch(t, want, slices.Collect(maps.Keys(got)))
+		})
+	}
+}
+
+func TestBuildGraph_Errors(t *testing.T) {
+	t.Parallel()
+
+	srv := newDocsServer(t, map[string]string{"/index.md": "# Index"})
+
+	_, err := doctree.BuildGraph(context.Background(), []string{srv.URL + "/missing.md"})
+	require.ErrorIs(t, err, doctree.ErrUnexpectedStatus)
+
+	_, err = doctree.BuildGraph(context.Background(), []string{srv.URL + "/index.md"}, doctree.WithMaxSize(3))
+	require.ErrorIs(t, err, doctree.ErrTooLarge)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = doctree.BuildGraph(ctx, []string{srv.URL + "/index.md"})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBuildGraph_BaseDir(t *testing.T) {
+	t.Parallel()
+
+	got, err := doctree.BuildGraph(context.Background(),
+		[]string{filepath.Join("testdata", "links.md")},
+		doctree.WithBaseDir("testdata"),
+		doctree.WithAllowedHosts("localhost"),
+	)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		filepath.Join("testdata", "links.md"),
+		filepath.Join("testdata", "sample.md"),
+		filepath.Join("testdata", "another-document.md"),
+	}, slices.Collect(maps.Keys(got)), "README.md outside of the base dir must be skipped")
+}
+
+// schemeLoader serves any document of its scheme and records the fetched URLs.
+type schemeLoader struct {
+	scheme  string
+	fetched chan string
+}
+
+func (l schemeLoader) Match(url string) bool {
+	return strings.HasPrefix(url, l.scheme)
+}
+
+func (l schemeLoader) Fetch(_ context.Context, url string) ([]byte, error) {
+	l.fetched <- url
+
+	return []byte("# Package"), nil
+}
+
+func (schemeLoader) Load(url string, source []byte) (doctree.Parsed, error) {
+	return doctree.MarkdownLoader{}.Load(url, source)
+}
+
+func TestBuildGraph_CustomSchemeLinks(t *testing.T) {
+	t.Parallel()
+
+	srv := newDocsServer(t, map[string]string{"/index.md": "[fmt](go:fmt)"})
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "index.md")
+	require.NoError(t, os.WriteFile(local, []byte("[fmt](go:fmt)"), 0o600))
+
+	loader := schemeLoader{scheme: "go:", fetched: make(chan string, 1)}
+
+	got, err := doctree.BuildGraph(context.Background(), []string{srv.URL + "/index.md"}, doctree.WithLoaders(loader))
+	require.NoError(t, err)
+	assert.Equal(t, []string{srv.URL + "/index.md"}, slices.Collect(maps.Keys(got)),
+		"remote documents must not link to custom schemes")
+	assert.Empty(t, loader.fetched)
+
+	got, err = doctree.BuildGraph(context.Background(), []string{local}, doctree.WithLoaders(loader))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{local, "go:fmt"}, slices.Collect(maps.Keys(got)))
+	assert.Equal(t, "go:fmt", <-loader.fetched)
+}
+
+func TestBuildGraph_RemoteFileLinks(t *testing.T) {
+	t.Parallel()
+
+	secret := filepath.Join(t.TempDir(), "secret.md")
+	require.NoError(t, os.WriteFile(secret, []byte("# Secret"), 0o600))
+
+	srv := newDocsServer(t, map[string]string{
+		"/index.md": "[secret](file://" + filepath.ToSlash(secret) + ") [guide](guide.md)",
+		"/guide.md": "# Guide",
+	})
+
+	got, err := doctree.BuildGraph(context.Background(), []string{srv.URL + "/index.md"})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{srv.URL + "/index.md", srv.URL + "/guide.md"}, slices.Collect(maps.Keys(got)))
+	assert.Equal(t, []string{srv.URL + "/guide.md"}, got[srv.URL+"/index.md"].Links)
+}
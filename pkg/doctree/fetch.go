@@ -0,0 +1,240 @@
+package doctree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnexpectedStatus is returned when a remote document responds with a non-2xx status.
+	ErrUnexpectedStatus = errors.New("unexpected status")
+	// ErrTooLarge is returned when a document exceeds the size limit.
+	ErrTooLarge = errors.New("document is too large")
+	// ErrUnsupportedURL is returned when no loader can fetch a document with the URL scheme.
+	ErrUnsupportedURL = errors.New("unsupported url")
+)
+
+// fetchSource reads the source of the document by the loader if it is a [Fetcher]
+// or from a local file or HTTP/HTTPS URL otherwise.
+// Unchanged files and HTTP documents are taken from the cache if it is set.
+func fetchSource(ctx context.Context, url string, loader Loader, opts *options) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("fetch %q: %w", url, err)
+	}
+
+	if fetcher, ok := loader.(Fetcher); ok {
+		data, err := fetcher.Fetch(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("fetch %q: %w", url, err)
+		}
+
+		if opts.maxSize > 0 && int64(len(data)) > opts.maxSize {
+			return nil, fmt.Errorf("%w: %q is larger than %d bytes", ErrTooLarge, url, opts.maxSize)
+		}
+
+		// Fetched documents are not revalidated, they are cached only to report the changes.
+		if opts.cache != nil {
+			opts.cache.put(url, cacheEntry{
+				Hash:         contentHash(data),
+				Size:         int64(len(data)),
+				ModTime:      time.Time{},
+				ETag:         "",
+				LastModified: "",
+				Content:      string(data),
+			})
+		}
+
+		return data, nil
+	}
+
+	var (
+		cached *cacheEntry
+		entry  cacheEntry
+		err    error
+	)
+
+	if opts.cache != nil {
+		if e, ok := opts.cache.get(url); ok {
+			cached = &e
+		}
+	}
+
+	switch {
+	case isFileURL(url):
+		entry, err = readFile(url, opts.maxSize, cached)
+	case isHTTPURL(url):
+		entry, err = readHTTP(ctx, url, opts.httpClient, opts.maxSize, cached)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnsupportedURL, url)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.cache != nil {
+		opts.cache.put(url, entry)
+	}
+
+	return []byte(entry.Content), nil
+}
+
+// isFileURL checks if the given URL is a local file path.
+func isFileURL(url string) bool {
+	scheme, _, ok := strings.Cut(url, ":")
+	if !ok || len(scheme) <= 1 || strings.ContainsAny(scheme, "/\\.") {
+		// Not a scheme: a path or a Windows drive letter.
+		return true
+	}
+
+	return scheme == "file"
+}
+
+// isHTTPURL checks if the given URL is an HTTP/HTTPS URL.
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// readFile reads content from a local file.
+// The cached entry is returned if the size and the modification time of the file are the same.
+func readFile(url string, maxSize int64, cached *cacheEntry) (cacheEntry, error) {
+	url = strings.TrimPrefix(url, "file://")
+
+	info, err := os.Stat(url)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("os stat %q: %w", url, err)
+	}
+
+	if maxSize > 0 && info.Size() > maxSize {
+		return cacheEntry{}, fmt.Errorf("%w: %q is %d bytes", ErrTooLarge, url, info.Size())
+	}
+
+	if cached != nil && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return *cached, nil
+	}
+
+	data, err := os.ReadFile(url)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("os read file %q: %w", url, err)
+	}
+
+	return cacheEntry{
+		Hash:         contentHash(data),
+		Size:         info.Size(),
+		ModTime:      info.ModTime(),
+		ETag:         "",
+		LastModified: "",
+		Content:      string(data),
+	}, nil
+}
+
+// readHTTP reads content from an HTTP/HTTPS URL.
+// The cached entry is revalidated by a conditional request.
+func readHTTP(ctx context.Context, url string, client *http.Client, maxSize int64, cached *cacheEntry) (cacheEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("new get req: %w", err)
+	}
+
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("get %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return *cached, nil
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return cacheEntry{}, fmt.Errorf("get %q: %w: %s", url, ErrUnexpectedStatus, resp.Status)
+	}
+
+	var body io.Reader = resp.Body
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return cacheEntry{}, fmt.Errorf("read response: %w", err)
+	}
+
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return cacheEntry{}, fmt.Errorf("%w: %q is larger than %d bytes", ErrTooLarge, url, maxSize)
+	}
+
+	return cacheEntry{
+		Hash:         contentHash(data),
+		Size:         int64(len(data)),
+		ModTime:      time.Time{},
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Content:      string(data),
+	}, nil
+}
+
+// allowed reports whether the document linked from the document at the given URL may be fetched.
+// Only local documents may link to files and to the URLs of custom loaders, e.g. "go:".
+func (o *options) allowed(from, link string) bool {
+	if !isHTTPURL(link) && !isFileURL(from) {
+		return false
+	}
+
+	if isFileURL(link) {
+		if o.baseDir == "" {
+			return true
+		}
+
+		return isWithin(o.baseDir, strings.TrimPrefix(link, "file://"))
+	}
+
+	if !isHTTPURL(link) || len(o.allowedHosts) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(o.allowedHosts, u.Host) || slices.Contains(o.allowedHosts, u.Hostname())
+}
+
+func isWithin(dir, name string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+
+	name, err = filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(dir, name)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
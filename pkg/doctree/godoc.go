@@ -0,0 +1,107 @@
+package doctree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// GoDocScheme is the URL scheme of Go package documentation, e.g. "go:./pkg/doctree" or "go:net/http".
+const GoDocScheme = "go:"
+
+// GoDocLoader loads documentation of Go packages rendered by "go doc -all".
+// Every declared type, function and method becomes a heading with its name as the anchor,
+// e.g. "go:./pkg/doctree#Document.Section".
+type GoDocLoader struct {
+	// Dir is the working directory of the go command. Relative packages are resolved from it.
+	Dir string
+}
+
+func (GoDocLoader) Match(url string) bool {
+	return strings.HasPrefix(url, GoDocScheme)
+}
+
+func (l GoDocLoader) Fetch(ctx context.Context, url string) ([]byte, error) {
+	pkg := strings.TrimPrefix(url, GoDocScheme)
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "go", "doc", "-all", pkg)
+	cmd.Dir = l.Dir
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go doc %q: %w: %s", pkg, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+var (
+	goDocFuncRe = regexp.MustCompile(`^func (?:\(\w* ?\*?(\w+)(?:\[[^\]]*\])?\) )?(\w+)`)
+	goDocTypeRe = regexp.MustCompile(`^type (\w+)`)
+	goDocURLRe  = regexp.MustCompile(`https?://[^\s<>"')\]]+`)
+)
+
+// Heading levels of "go doc -all" output.
+const (
+	goDocPackageLevel = iota + 1
+	goDocSectionLevel
+	goDocDeclLevel
+	goDocMemberLevel
+)
+
+// goDocSections are headers of the sections of "go doc -all" output.
+var goDocSections = []string{"CONSTANTS", "VARIABLES", "FUNCTIONS", "TYPES"}
+
+func (GoDocLoader) Load(_ string, source []byte) (Parsed, error) {
+	lines := strings.Split(string(source), "\n")
+	parsed := Parsed{Text: string(source), Links: nil, Headings: nil}
+
+	inTypes := false
+
+	for i, line := range lines {
+		heading := Heading{Level: 0, Text: "", Line: i + 1, Anchor: ""}
+
+		switch {
+		case i == 0 && strings.HasPrefix(line, "package "):
+			name, _, _ := strings.Cut(line, " //")
+			heading.Level, heading.Text = goDocPackageLevel, name
+		case slices.Contains(goDocSections, line):
+			inTypes = line == "TYPES"
+			heading.Level, heading.Text = goDocSectionLevel, line
+		case goDocTypeRe.MatchString(line):
+			name := goDocTypeRe.FindStringSubmatch(line)[1]
+			heading.Level, heading.Text, heading.Anchor = goDocDeclLevel, "type "+name, name
+		case goDocFuncRe.MatchString(line):
+			m := goDocFuncRe.FindStringSubmatch(line)
+
+			heading.Level, heading.Text, heading.Anchor = goDocDeclLevel, "func "+m[2], m[2]
+
+			if inTypes {
+				// Constructors and methods are listed under their types.
+				heading.Level = goDocMemberLevel
+			}
+
+			if m[1] != "" {
+				heading.Text, heading.Anchor = "method "+m[1]+"."+m[2], m[1]+"."+m[2]
+			}
+		default:
+			for _, m := range goDocURLRe.FindAllStringIndex(line, -1) {
+				url := strings.TrimRight(line[m[0]:m[1]], ".,;:")
+				parsed.Links = append(parsed.Links, newLink(LinkAuto, url, url, i+1, m[0]))
+			}
+
+			continue
+		}
+
+		parsed.Headings = append(parsed.Headings, heading)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,307 @@
+package doctree
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BrokenLink is a link to a missing document or a missing section of a document.
+type BrokenLink struct {
+	// Source is the URL of the document containing the link.
+	Source string
+	Link   Link
+	// Err is wrapped [ErrDocumentNotFound] or [ErrAnchorNotFound].
+	Err error
+}
+
+func (b BrokenLink) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", b.Source, b.Link.Line, b.Link.Column, b.Err)
+}
+
+// BrokenLinks returns the links which can't be resolved within the documents,
+// ordered by the source document and the position of the link.
+func BrokenLinks(documents map[string]*Document) []BrokenLink {
+	var broken []BrokenLink
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		for _, link := range documents[url].References {
+			if _, err := ResolveLink(documents, link); err != nil {
+				broken = append(broken, BrokenLink{Source: url, Link: link, Err: err})
+			}
+		}
+	}
+
+	return broken
+}
+
+// Orphans returns sorted URLs of the documents not reachable from the index document.
+func Orphans(documents map[string]*Document, index string) []string {
+	reachable := make(map[string]struct{})
+
+	for _, url := range bfs(documents, index) {
+		reachable[url] = struct{}{}
+	}
+
+	var orphans []string
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		if _, ok := reachable[url]; !ok {
+			orphans = append(orphans, url)
+		}
+	}
+
+	return orphans
+}
+
+// Cycles returns groups of documents linking to each other directly or through other documents.
+// Each group is sorted, groups are ordered by their first URL.
+func Cycles(documents map[string]*Document) [][]string {
+	t := tarjan{
+		documents: documents,
+		index:     make(map[string]int),
+		lowlink:   make(map[string]int),
+		onStack:   make(map[string]bool),
+		stack:     nil,
+		next:      0,
+		cycles:    nil,
+	}
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		if _, visited := t.index[url]; !visited {
+			t.connect(url)
+		}
+	}
+
+	for _, cycle := range t.cycles {
+		slices.Sort(cycle)
+	}
+
+	slices.SortFunc(t.cycles, func(a, b []string) int {
+		return cmp.Compare(a[0], b[0])
+	})
+
+	return t.cycles
+}
+
+// ReadingOrder returns the documents reachable from the index document in the order to read them:
+// a document goes after all documents linking to it. Cycles are broken in the breadth-first order
+// of links, so the index document always goes first.
+func ReadingOrder(documents map[string]*Document, index string) []string {
+	reachable := bfs(documents, index)
+	if len(reachable) == 0 {
+		return nil
+	}
+
+	rank := make(map[string]int, len(reachable))
+	for i, url := range reachable {
+		rank[url] = i
+	}
+
+	indegree := make(map[string]int, len(reachable))
+
+	for _, url := range reachable {
+		for _, link := range documents[url].Links {
+			if _, ok := rank[link]; ok && link != index {
+				indegree[link]++
+			}
+		}
+	}
+
+	order := make([]string, 0, len(reachable))
+	done := make(map[string]bool, len(reachable))
+
+	for len(order) < len(reachable) {
+		// Take the first document in BFS order without unread documents linking to it
+		// or the first unread document at all when the rest are in cycles.
+		next := ""
+
+		for _, url := range reachable {
+			if done[url] {
+				continue
+			}
+
+			if next == "" {
+				next = url
+			}
+
+			if indegree[url] == 0 {
+				next = url
+
+				break
+			}
+		}
+
+		done[next] = true
+		order = append(order, next)
+
+		for _, link := range documents[next].Links {
+			if _, ok := rank[link]; ok {
+				indegree[link]--
+			}
+		}
+	}
+
+	return order
+}
+
+// FormatDOT returns the graph of documents in the Graphviz DOT language.
+func FormatDOT(documents map[string]*Document) string {
+	var builder strings.Builder
+
+	builder.WriteString("digraph docs {\n")
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		builder.WriteString(fmt.Sprintf("  %s;\n", strconv.Quote(url)))
+	}
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		for _, link := range documents[url].Links {
+			if _, ok := documents[link]; ok {
+				builder.WriteString(fmt.Sprintf("  %s -> %s;\n", strconv.Quote(url), strconv.Quote(link)))
+			}
+		}
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+type jsonGraph struct {
+	Documents []jsonDocument `json:"documents"`
+}
+
+type jsonDocument struct {
+	URL      string      `json:"url"`
+	Links    []string    `json:"links"`
+	LinkedBy []string    `json:"linked_by"`
+	Chunks   []jsonChunk `json:"chunks"`
+}
+
+type jsonChunk struct {
+	ID          string `json:"id"`
+	HeadingPath string `json:"heading_path"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+}
+
+// FormatJSON returns the graph of documents as JSON without the content of documents.
+func FormatJSON(documents map[string]*Document) ([]byte, error) {
+	graph := jsonGraph{Documents: make([]jsonDocument, 0, len(documents))}
+
+	for _, url := range slices.Sorted(maps.Keys(documents)) {
+		doc := documents[url]
+
+		d := jsonDocument{
+			URL:      url,
+			Links:    append([]string{}, doc.Links...),
+			LinkedBy: make([]string, 0, len(doc.LinkedBy)),
+			Chunks:   make([]jsonChunk, 0, len(doc.Chunks)),
+		}
+
+		for _, by := range doc.LinkedBy {
+			d.LinkedBy = append(d.LinkedBy, by.URL)
+		}
+
+		for _, chunk := range doc.Chunks {
+			d.Chunks = append(d.Chunks, jsonChunk{
+				ID:          chunk.ID,
+				HeadingPath: chunk.HeadingPath(),
+				StartLine:   chunk.StartLine,
+				EndLine:     chunk.EndLine,
+			})
+		}
+
+		graph.Documents = append(graph.Documents, d)
+	}
+
+	b, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal json: %w", err)
+	}
+
+	return b, nil
+}
+
+// bfs returns the documents reachable from the root in the breadth-first order of links.
+func bfs(documents map[string]*Document, root string) []string {
+	if _, ok := documents[root]; !ok {
+		return nil
+	}
+
+	visited := map[string]bool{root: true}
+	queue := []string{root}
+
+	for i := 0; i < len(queue); i++ {
+		for _, link := range documents[queue[i]].Links {
+			if _, ok := documents[link]; ok && !visited[link] {
+				visited[link] = true
+				queue = append(queue, link)
+			}
+		}
+	}
+
+	return queue
+}
+
+// tarjan finds strongly connected components of the graph.
+type tarjan struct {
+	documents map[string]*Document
+	index     map[string]int
+	lowlink   map[string]int
+	onStack   map[string]bool
+	stack     []string
+	next      int
+	cycles    [][]string
+}
+
+func (t *tarjan) connect(url string) {
+	t.index[url] = t.next
+	t.lowlink[url] = t.next
+	t.next++
+
+	t.stack = append(t.stack, url)
+	t.onStack[url] = true
+
+	// Links never point to the document itself, so single documents are not cycles.
+	for _, link := range t.documents[url].Links {
+		if _, ok := t.documents[link]; !ok {
+			continue
+		}
+
+		if _, visited := t.index[link]; !visited {
+			t.connect(link)
+			t.lowlink[url] = min(t.lowlink[url], t.lowlink[link])
+		} else if t.onStack[link] {
+			t.lowlink[url] = min(t.lowlink[url], t.index[link])
+		}
+	}
+
+	if t.lowlink[url] != t.index[url] {
+		return
+	}
+
+	var component []string
+
+	for {
+		n := len(t.stack) - 1
+		top := t.stack[n]
+		t.stack = t.stack[:n]
+		t.onStack[top] = false
+
+		component = append(component, top)
+
+		if top == url {
+			break
+		}
+	}
+
+	if len(component) > 1 {
+		t.cycles = append(t.cycles, component)
+	}
+}
@@ -0,0 +1,132 @@
+package doctree_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildFiles writes the files into a temporary directory and builds the graph from the roots.
+func buildFiles(t *testing.T, files map[string]string, roots ...string) (map[string]*doctree.Document, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	urls := make([]string, 0, len(roots))
+	for _, root := range roots {
+		urls = append(urls, filepath.Join(dir, root))
+	}
+
+	docs, err := doctree.BuildGraph(context.Background(), urls)
+	require.NoError(t, err)
+
+	return docs, dir
+}
+
+func TestGraphAnalysis(t *testing.T) {
+	t.Parallel()
+
+	docs, dir := buildFiles(t, map[string]string{
+		"index.md":  "# Index\n\n[a](a.md) [b](b.md#missing)\n[gone](gone.md)\n",
+		"a.md":      "# A\n\n[b](b.md) [index](index.md#index)\n",
+		"b.md":      "# B\n\n[a](a.md#a)\n",
+		"orphan.md": "[c](c.md)\n",
+		"c.md":      "[orphan](orphan.md)\n",
+	}, "index.md", "orphan.md")
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	broken := doctree.BrokenLinks(docs)
+	require.Len(t, broken, 2)
+	assert.ErrorIs(t, broken[0].Err, doctree.ErrAnchorNotFound)
+	assert.Equal(t, path("index.md")+":3:11: anchor not found: \"missing\" in \""+path("b.md")+"\"", broken[0].String())
+	assert.ErrorIs(t, broken[1].Err, doctree.ErrDocumentNotFound)
+	assert.Equal(t, 4, broken[1].Link.Line)
+
+	assert.Equal(t, []string{path("c.md"), path("orphan.md")}, doctree.Orphans(docs, path("index.md")))
+
+	assert.Equal(t, [][]string{
+		{path("a.md"), path("b.md"), path("index.md")},
+		{path("c.md"), path("orphan.md")},
+	}, doctree.Cycles(docs))
+}
+
+func TestReadingOrder(t *testing.T) {
+	t.Parallel()
+
+	docs, dir := buildFiles(t, map[string]string{
+		"index.md":   "[details](details.md) [intro](intro.md)\n",
+		"intro.md":   "[details](details.md)\n",
+		"details.md": "[index](index.md)\n",
+	}, "index.md")
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	// The intro links to the details, so it goes first despite the BFS order.
+	assert.Equal(t, []string{
+		path("index.md"), path("intro.md"), path("details.md"),
+	}, doctree.ReadingOrder(docs, path("index.md")))
+
+	assert.Nil(t, doctree.ReadingOrder(docs, path("missing.md")))
+}
+
+func TestFormatDOT(t *testing.T) {
+	t.Parallel()
+
+	docs, dir := buildFiles(t, map[string]string{
+		"index.md": "[a](a.md) [gone](gone.md)\n",
+		"a.md":     "# A\n",
+	}, "index.md")
+
+	index := filepath.Join(dir, "index.md")
+	a := filepath.Join(dir, "a.md")
+
+	assert.Equal(t, "digraph docs {\n"+
+		"  \""+a+"\";\n"+
+		"  \""+index+"\";\n"+
+		"  \""+index+"\" -> \""+a+"\";\n"+
+		"}\n", doctree.FormatDOT(docs))
+}
+
+func TestFormatJSON(t *testing.T) {
+	t.Parallel()
+
+	docs, dir := buildFiles(t, map[string]string{
+		"index.md": "[a](a.md)\n",
+		"a.md":     "# A\n",
+	}, "index.md")
+
+	b, err := doctree.FormatJSON(docs)
+	require.NoError(t, err)
+
+	var got struct {
+		Documents []struct {
+			URL      string   `json:"url"`
+			Links    []string `json:"links"`
+			LinkedBy []string `json:"linked_by"`
+			Chunks   []struct {
+				ID          string `json:"id"`
+				HeadingPath string `json:"heading_path"`
+			} `json:"chunks"`
+		} `json:"documents"`
+	}
+
+	require.NoError(t, json.Unmarshal(b, &got))
+	require.Len(t, got.Documents, 2)
+
+	a := got.Documents[0]
+	assert.Equal(t, filepath.Join(dir, "a.md"), a.URL)
+	assert.Equal(t, []string{filepath.Join(dir, "index.md")}, a.LinkedBy)
+	assert.Equal(t, "A", a.Chunks[0].HeadingPath)
+	assert.Equal(t, []string{filepath.Join(dir, "a.md")}, got.Documents[1].Links)
+}
@@ -0,0 +1,239 @@
+package doctree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLLoader loads HTML documents converted to plain text with <a href> links and <h1>-<h6> headings.
+type HTMLLoader struct{}
+
+func (HTMLLoader) Match(url string) bool {
+	return hasExt(url, ".html", ".htm")
+}
+
+var (
+	htmlSkippedTags = []string{"head", "script", "style", "noscript", "template", "svg"}
+	htmlBlockTags   = []string{
+		"address", "article", "aside", "blockquote", "dd", "div", "dl", "dt", "figcaption", "figure",
+		"footer", "form", "header", "hr", "li", "main", "nav", "ol", "p", "section", "table", "tr", "ul",
+	}
+)
+
+func (HTMLLoader) Load(_ string, source []byte) (Parsed, error) {
+	z := html.NewTokenizer(bytes.NewReader(source))
+	w := htmlWriter{}
+	offset := 0
+
+	for {
+		tt := z.Next()
+		start := offset
+		offset += len(z.Raw())
+
+		switch tt {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return w.parsed(), nil
+			}
+
+			return Parsed{}, fmt.Errorf("tokenize html: %w", z.Err())
+		case html.TextToken:
+			if w.skip == 0 {
+				w.text(string(z.Text()))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, attrs := htmlTag(z)
+
+			if tt == html.StartTagToken && slices.Contains(htmlSkippedTags, name) {
+				w.skip++
+
+				continue
+			}
+
+			line, column := position(source, start)
+			w.start(name, attrs, line, column)
+		case html.EndTagToken:
+			name, _ := z.TagName()
+
+			if slices.Contains(htmlSkippedTags, string(name)) {
+				w.skip = max(w.skip-1, 0)
+
+				continue
+			}
+
+			w.end(string(name))
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+func htmlTag(z *html.Tokenizer) (string, map[string]string) {
+	name, hasAttr := z.TagName()
+	attrs := make(map[string]string)
+
+	for hasAttr {
+		var key, val []byte
+
+		key, val, hasAttr = z.TagAttr()
+		attrs[string(key)] = string(val)
+	}
+
+	return string(name), attrs
+}
+
+// htmlWriter renders HTML tokens as plain text.
+type htmlWriter struct {
+	buf      strings.Builder
+	line     int
+	space    bool
+	skip     int
+	pre      int
+	links    []Link
+	link     *Link
+	linkText strings.Builder
+	headings []Heading
+	heading  *Heading
+}
+
+func (w *htmlWriter) start(name string, attrs map[string]string, line, column int) {
+	switch name {
+	case "a":
+		href, ok := attrs["href"]
+		if !ok {
+			return
+		}
+
+		w.link = &Link{
+			Kind:        LinkInline,
+			Text:        "",
+			Title:       attrs["title"],
+			Destination: href,
+			URL:         "",
+			Fragment:    "",
+			Line:        line,
+			Column:      column,
+		}
+		w.linkText.Reset()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		w.block()
+
+		w.heading = &Heading{
+			Level:  int(name[1] - '0'),
+			Text:   "",
+			Line:   w.line + 1,
+			Anchor: attrs["id"],
+		}
+	case "pre":
+		w.block()
+		w.pre++
+	case "br":
+		w.write("\n")
+	default:
+		if slices.Contains(htmlBlockTags, name) {
+			w.block()
+		}
+	}
+}
+
+func (w *htmlWriter) end(name string) {
+	switch name {
+	case "a":
+		if w.link != nil {
+			w.link.Text = strings.Join(strings.Fields(w.linkText.String()), " ")
+			w.links = append(w.links, *w.link)
+			w.link = nil
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		if w.heading != nil {
+			w.heading.Text = strings.Join(strings.Fields(w.heading.Text), " ")
+			w.headings = append(w.headings, *w.heading)
+			w.heading = nil
+		}
+
+		w.block()
+	case "pre":
+		w.pre = max(w.pre-1, 0)
+		w.block()
+	default:
+		if slices.Contains(htmlBlockTags, name) {
+			w.block()
+		}
+	}
+}
+
+func (w *htmlWriter) text(s string) {
+	if w.link != nil {
+		w.linkText.WriteString(s)
+	}
+
+	if w.heading != nil {
+		w.heading.Text += s
+	}
+
+	if w.pre > 0 {
+		w.write(s)
+
+		return
+	}
+
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		w.space = w.space || s != ""
+
+		return
+	}
+
+	if w.space || strings.IndexFunc(s[:1], isSpace) == 0 {
+		w.write(" ")
+	}
+
+	w.write(strings.Join(words, " "))
+
+	w.space = strings.LastIndexFunc(s, isSpace) == len(s)-1
+}
+
+// block separates blocks of text by an empty line.
+func (w *htmlWriter) block() {
+	w.space = false
+
+	s := w.buf.String()
+
+	switch {
+	case s == "", strings.HasSuffix(s, "\n\n"):
+	case strings.HasSuffix(s, "\n"):
+		w.write("\n")
+	default:
+		w.write("\n\n")
+	}
+}
+
+func (w *htmlWriter) write(s string) {
+	if s == " " {
+		w.space = false
+
+		if w.buf.Len() == 0 || strings.HasSuffix(w.buf.String(), "\n") {
+			return
+		}
+	}
+
+	w.buf.WriteString(s)
+	w.line += strings.Count(s, "\n")
+}
+
+func (w *htmlWriter) parsed() Parsed {
+	return Parsed{
+		Text:     strings.TrimRight(w.buf.String(), "\n") + "\n",
+		Links:    w.links,
+		Headings: w.headings,
+	}
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
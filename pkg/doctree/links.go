@@ -0,0 +1,158 @@
+package doctree
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// LinkKind is a kind of the link syntax.
+type LinkKind int
+
+const (
+	// LinkInline is an inline link: [text](url "title").
+	LinkInline LinkKind = iota + 1
+	// LinkReference is a reference link: [text][ref] with the [ref]: url definition.
+	LinkReference
+	// LinkAuto is an autolink: <https://example.com/doc.md>.
+	LinkAuto
+)
+
+func (k LinkKind) String() string {
+	switch k {
+	case LinkInline:
+		return "inline"
+	case LinkReference:
+		return "reference"
+	case LinkAuto:
+		return "auto"
+	default:
+		return "unknown"
+	}
+}
+
+// Link is a link from a document to another document or to a section of the same document.
+type Link struct {
+	Kind LinkKind
+	// Text is the link text as plain text.
+	Text string
+	// Title is the optional link title.
+	Title string
+	// Destination is the link destination as written in the document.
+	Destination string
+	// URL is the resolved URL of the target document without the fragment.
+	URL string
+	// Fragment is the anchor of the target section without the leading '#'.
+	Fragment string
+	// Line and Column are 1-based position of the link in the document.
+	Line   int
+	Column int
+}
+
+// resolveLinks resolves destinations of the links relative to the base document
+// and keeps the links to sections of the same document and to documents of supported formats.
+func resolveLinks(base string, links []Link, loaders []Loader) []Link {
+	resolved := make([]Link, 0, len(links))
+
+	for _, link := range links {
+		target, fragment, ok := resolveLink(base, link.Destination)
+		if !ok {
+			continue
+		}
+
+		if target != base && findLoader(loaders, target) == nil {
+			continue
+		}
+
+		link.URL = target
+		link.Fragment = fragment
+
+		resolved = append(resolved, link)
+	}
+
+	return resolved
+}
+
+// linkedURLs returns unique URLs of other documents in the order of their first appearance.
+func linkedURLs(self string, links []Link) []string {
+	urls := []string{}
+
+	for _, link := range links {
+		if link.URL == self || slices.Contains(urls, link.URL) {
+			continue
+		}
+
+		urls = append(urls, link.URL)
+	}
+
+	return urls
+}
+
+// resolveLink resolves the link destination relative to the base document.
+// It reports false for destinations which are not URLs or paths
+// and for local files and custom schemes linked from remote documents.
+func resolveLink(base, dest string) (string, string, bool) {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return "", "", false
+	}
+
+	fragment := u.Fragment
+
+	switch u.Scheme {
+	case "http", "https":
+		u.Fragment = ""
+
+		return u.String(), fragment, true
+	case "":
+	case "mailto", "tel", "javascript", "data":
+		return "", "", false
+	default:
+		if !isFileURL(base) {
+			// Remote documents may only link to other remote documents.
+			return "", "", false
+		}
+
+		target, _, _ := strings.Cut(dest, "#")
+
+		return target, fragment, true
+	}
+
+	if u.Path == "" {
+		// Anchor in the same document.
+		return base, fragment, fragment != "" && u.RawQuery == ""
+	}
+
+	if !isFileURL(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", "", false
+		}
+
+		u.Fragment = ""
+
+		return baseURL.ResolveReference(u).String(), fragment, true
+	}
+
+	if filepath.IsAbs(u.Path) {
+		return filepath.Clean(u.Path), fragment, true
+	}
+
+	directory := filepath.Dir(strings.TrimPrefix(base, "file://"))
+
+	return filepath.Join(directory, filepath.FromSlash(u.Path)), fragment, true
+}
+
+// position converts the byte offset to 1-based line and column.
+func position(source []byte, offset int) (int, int) {
+	if offset < 0 || offset > len(source) {
+		return 0, 0
+	}
+
+	line := bytes.Count(source[:offset], []byte{'\n'}) + 1
+	column := offset - bytes.LastIndexByte(source[:offset], '\n')
+
+	return line, column
+}
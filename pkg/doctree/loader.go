@@ -0,0 +1,72 @@
+package doctree
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Heading is a heading of a loaded document.
+type Heading struct {
+	Level int
+	Text  string
+	// Line is the 1-based line of the heading in the normalized text.
+	Line int
+	// Anchor is the explicit anchor of the heading. The slug of the text is used if it is empty.
+	Anchor string
+}
+
+// Parsed is a document converted by a loader.
+type Parsed struct {
+	// Text is the content of the document normalized to text.
+	Text string
+	// Links are the links with unresolved destinations and positions in the source document.
+	Links []Link
+	// Headings split the text into chunks.
+	Headings []Heading
+}
+
+// Loader converts documents of some format to text and extracts their links.
+type Loader interface {
+	// Match reports whether the loader handles the document with the URL.
+	Match(url string) bool
+	// Load converts the source of the document.
+	Load(url string, source []byte) (Parsed, error)
+}
+
+// Fetcher is implemented by loaders reading documents in their own way instead of files and HTTP.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// DefaultLoaders returns loaders of all supported document formats.
+// [GoDocLoader] is not included because it runs the go command; add it with [WithLoaders].
+func DefaultLoaders() []Loader {
+	return []Loader{
+		MarkdownLoader{},
+		HTMLLoader{},
+		RSTLoader{},
+		AsciiDocLoader{},
+		OpenAPILoader{},
+	}
+}
+
+// findLoader returns the first loader matching the URL or nil.
+func findLoader(loaders []Loader, url string) Loader { //nolint:ireturn // Loaders are chosen by URL at runtime.
+	for _, loader := range loaders {
+		if loader.Match(url) {
+			return loader
+		}
+	}
+
+	return nil
+}
+
+// hasExt reports whether the path of the URL has one of the extensions.
+func hasExt(url string, exts ...string) bool {
+	url, _, _ = strings.Cut(url, "#")
+	url, _, _ = strings.Cut(url, "?")
+
+	return slices.Contains(exts, strings.ToLower(path.Ext(url)))
+}
@@ -0,0 +1,119 @@
+package doctree_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/doctree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func anchors(chunks []doctree.Chunk) []string {
+	result := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		result = append(result, chunk.Anchor)
+	}
+
+	return result
+}
+
+// targets returns the references as "<base name of URL>#<fragment>".
+func targets(links []doctree.Link) []string {
+	result := make([]string, 0, len(links))
+	for _, link := range links {
+		result = append(result, filepath.Base(link.URL)+"#"+link.Fragment)
+	}
+
+	return result
+}
+
+func TestBuildGraph_Formats(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join("testdata", "formats")
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	docs, err := doctree.BuildGraph(context.Background(), []string{path("index.md")},
+		doctree.WithLoaders(doctree.GoDocLoader{Dir: ""}))
+	require.NoError(t, err)
+
+	assert.Empty(t, doctree.BrokenLinks(docs))
+	assert.Empty(t, doctree.Orphans(docs, path("index.md")))
+
+	tests := []struct {
+		url         string
+		wantAnchors []string
+		wantTargets []string
+	}{
+		{
+			url:         path("guide.html"),
+			wantAnchors: []string{"guide", "install"},
+			wantTargets: []string{"conventions.rst#"},
+		},
+		{
+			url:         path("conventions.rst"),
+			wantAnchors: []string{"conventions", "naming", "errors"},
+			wantTargets: []string{"manual.adoc#", "index.md#", "api.yaml#"},
+		},
+		{
+			url:         path("manual.adoc"),
+			wantAnchors: []string{"_manual", "_usage", "errors-section"},
+			wantTargets: []string{"guide.html#", "manual.adoc#errors-section", "conventions.rst#errors"},
+		},
+		{
+			url:         path("api.yaml"),
+			wantAnchors: []string{"tasks-10", "listTasks", "post-tasks"},
+			wantTargets: []string{"manual.adoc#", "common.yaml#"},
+		},
+		{
+			url:         path("common.yaml"),
+			wantAnchors: []string{""},
+			wantTargets: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.url), func(t *testing.T) {
+			t.Parallel()
+
+			doc, ok := docs[tt.url]
+			require.True(t, ok)
+			assert.Equal(t, tt.wantAnchors, anchors(doc.Chunks))
+			assert.Equal(t, tt.wantTargets, targets(doc.References))
+		})
+	}
+
+	lines, ok := docs["go:../code/lines"]
+	require.True(t, ok, "go package docs must be loaded")
+	assert.Contains(t, anchors(lines.Chunks), "AddNumbers")
+}
+
+func TestHTMLLoader(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := doctree.HTMLLoader{}.Load("guide.html", []byte(
+		"<h1>Title</h1><p>Some <b>bold</b>\n  text with <a href=\"a.md\">a\nlink</a>.</p><pre>  code\n  block</pre>"+
+			"<ul><li>one</li><li>two</li></ul><script>ignored()</script>",
+	))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Title\n\nSome bold text with a link.\n\n  code\n  block\n\none\n\ntwo\n", parsed.Text)
+	assert.Equal(t, []doctree.Heading{{Level: 1, Text: "Title", Line: 1, Anchor: ""}}, parsed.Headings)
+	require.Len(t, parsed.Links, 1)
+	assert.Equal(t, "a link", parsed.Links[0].Text)
+	assert.Equal(t, 2, parsed.Links[0].Line)
+}
+
+func TestOpenAPILoader_NotSpecification(t *testing.T) {
+	t.Parallel()
+
+	source := []byte(`{"name": "not an api", "$ref": "other.json"}`)
+
+	parsed, err := doctree.OpenAPILoader{}.Load("data.json", source)
+	require.NoError(t, err)
+	assert.Equal(t, string(source), parsed.Text)
+	assert.Empty(t, parsed.Links)
+	assert.Empty(t, parsed.Headings)
+}
@@ -0,0 +1,142 @@
+package doctree
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// MarkdownLoader loads CommonMark documents. Plain text files are loaded as Markdown too.
+type MarkdownLoader struct{}
+
+func (MarkdownLoader) Match(url string) bool {
+	return hasExt(url, ".md", ".markdown", ".txt")
+}
+
+// Load extracts inline, reference and autolinks and top-level headings.
+// Links inside code blocks and code spans are ignored.
+func (MarkdownLoader) Load(_ string, source []byte) (Parsed, error) {
+	root := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	return Parsed{
+		Text:     string(source),
+		Links:    markdownLinks(root, source),
+		Headings: markdownHeadings(root, source),
+	}, nil
+}
+
+func markdownLinks(root ast.Node, source []byte) []Link {
+	var links []Link
+
+	_ = ast.Walk(root, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		var link Link
+
+		switch n := node.(type) {
+		case *ast.Link:
+			link = Link{
+				Kind:        LinkInline,
+				Text:        nodeText(n, source),
+				Title:       string(n.Title),
+				Destination: string(n.Destination),
+				URL:         "",
+				Fragment:    "",
+				Line:        0,
+				Column:      0,
+			}
+
+			if n.Reference != nil {
+				link.Kind = LinkReference
+			}
+		case *ast.AutoLink:
+			if n.AutoLinkType != ast.AutoLinkURL {
+				return ast.WalkSkipChildren, nil
+			}
+
+			dest := string(n.URL(source))
+
+			link = Link{
+				Kind:        LinkAuto,
+				Text:        dest,
+				Title:       "",
+				Destination: dest,
+				URL:         "",
+				Fragment:    "",
+				Line:        0,
+				Column:      0,
+			}
+		default:
+			return ast.WalkContinue, nil
+		}
+
+		link.Line, link.Column = position(source, node.Pos())
+
+		links = append(links, link)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return links
+}
+
+// markdownHeadings returns the headings of the document level. Headings in lists and quotes are ignored.
+func markdownHeadings(root ast.Node, source []byte) []Heading {
+	var headings []Heading
+
+	for node := root.FirstChild(); node != nil; node = node.NextSibling() {
+		heading, ok := node.(*ast.Heading)
+		if !ok {
+			continue
+		}
+
+		offset := heading.Pos()
+		if heading.Lines().Len() > 0 {
+			offset = heading.Lines().At(0).Start
+		}
+
+		line, _ := position(source, offset)
+
+		headings = append(headings, Heading{
+			Level:  heading.Level,
+			Text:   nodeText(heading, source),
+			Line:   line,
+			Anchor: "",
+		})
+	}
+
+	return headings
+}
+
+// nodeText returns the plain text of the inline node.
+func nodeText(node ast.Node, source []byte) string {
+	var buf bytes.Buffer
+
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.Text:
+			buf.Write(n.Value(source))
+
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(n.Value)
+		case *ast.AutoLink:
+			buf.Write(n.URL(source))
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,205 @@
+package doctree
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPILoader loads OpenAPI and Swagger specifications in YAML or JSON.
+// Every operation becomes a heading, so it can be retrieved as a separate chunk.
+// Other YAML and JSON documents are loaded as plain text without links.
+type OpenAPILoader struct{}
+
+func (OpenAPILoader) Match(url string) bool {
+	return hasExt(url, ".yaml", ".yml", ".json")
+}
+
+// Heading levels of the rendered specification.
+const (
+	openAPITitleLevel = iota + 1
+	openAPIOperationLevel
+)
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func (OpenAPILoader) Load(_ string, source []byte) (Parsed, error) {
+	plain := Parsed{Text: string(source), Links: nil, Headings: nil}
+
+	var root yaml.Node
+
+	// JSON is a subset of YAML, so both are parsed with positions of nodes.
+	if err := yaml.Unmarshal(source, &root); err != nil || len(root.Content) == 0 {
+		return plain, nil //nolint:nilerr // Not a specification.
+	}
+
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode || (mapValue(doc, "openapi") == nil && mapValue(doc, "swagger") == nil) {
+		return plain, nil
+	}
+
+	w := openAPIWriter{buf: strings.Builder{}, line: 1, parsed: Parsed{Text: "", Links: nil, Headings: nil}}
+
+	info := mapValue(doc, "info")
+	title := scalar(mapValue(info, "title"))
+
+	if title == "" {
+		title = "API"
+	}
+
+	if version := scalar(mapValue(info, "version")); version != "" {
+		title += " " + version
+	}
+
+	w.heading(openAPITitleLevel, title, "")
+	w.paragraph(scalar(mapValue(info, "description")))
+
+	if paths := mapValue(doc, "paths"); paths != nil && paths.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(paths.Content); i += 2 {
+			w.path(paths.Content[i].Value, paths.Content[i+1])
+		}
+	}
+
+	w.links(doc)
+
+	w.parsed.Text = w.buf.String()
+
+	return w.parsed, nil
+}
+
+type openAPIWriter struct {
+	buf    strings.Builder
+	line   int
+	parsed Parsed
+}
+
+func (w *openAPIWriter) path(path string, item *yaml.Node) {
+	if item.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		method := strings.ToLower(item.Content[i].Value)
+		if !slices.Contains(openAPIMethods, method) {
+			continue
+		}
+
+		op := item.Content[i+1]
+		title := strings.ToUpper(method) + " " + path
+
+		w.heading(openAPIOperationLevel, title, scalar(mapValue(op, "operationId")))
+		w.paragraph(scalar(mapValue(op, "summary")))
+		w.paragraph(scalar(mapValue(op, "description")))
+
+		if params := mapValue(op, "parameters"); params != nil && len(params.Content) > 0 {
+			var lines []string
+
+			for _, p := range params.Content {
+				line := fmt.Sprintf("- %s (%s)", scalar(mapValue(p, "name")), scalar(mapValue(p, "in")))
+				if desc := scalar(mapValue(p, "description")); desc != "" {
+					line += ": " + desc
+				}
+
+				lines = append(lines, line)
+			}
+
+			w.paragraph("Parameters:\n" + strings.Join(lines, "\n"))
+		}
+
+		if responses := mapValue(op, "responses"); responses != nil && responses.Kind == yaml.MappingNode {
+			var lines []string
+
+			for j := 0; j+1 < len(responses.Content); j += 2 {
+				lines = append(lines, fmt.Sprintf("- %s: %s",
+					responses.Content[j].Value, scalar(mapValue(responses.Content[j+1], "description"))))
+			}
+
+			w.paragraph("Responses:\n" + strings.Join(lines, "\n"))
+		}
+	}
+}
+
+func (w *openAPIWriter) heading(level int, text, anchor string) {
+	w.parsed.Headings = append(w.parsed.Headings, Heading{
+		Level:  level,
+		Text:   text,
+		Line:   w.line,
+		Anchor: anchor,
+	})
+
+	w.paragraph(strings.Repeat("#", level) + " " + text)
+}
+
+func (w *openAPIWriter) paragraph(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	w.buf.WriteString(text)
+	w.buf.WriteString("\n\n")
+	w.line += strings.Count(text, "\n") + 2
+}
+
+// links collects external $ref and externalDocs links of the specification.
+func (w *openAPIWriter) links(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			switch {
+			case key.Value == "$ref" && value.Kind == yaml.ScalarNode && !strings.HasPrefix(value.Value, "#"):
+				// JSON pointers are not anchors of chunks, so only the document is linked.
+				dest, _, _ := strings.Cut(value.Value, "#")
+				w.parsed.Links = append(w.parsed.Links, yamlLink(LinkReference, dest, dest, value))
+			case key.Value == "externalDocs" && value.Kind == yaml.MappingNode:
+				if u := mapValue(value, "url"); u != nil {
+					text := scalar(mapValue(value, "description"))
+					w.parsed.Links = append(w.parsed.Links, yamlLink(LinkInline, text, u.Value, u))
+				}
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		w.links(child)
+	}
+}
+
+func yamlLink(kind LinkKind, text, dest string, node *yaml.Node) Link {
+	return Link{
+		Kind:        kind,
+		Text:        text,
+		Title:       "",
+		Destination: dest,
+		URL:         "",
+		Fragment:    "",
+		Line:        node.Line,
+		Column:      node.Column,
+	}
+}
+
+// mapValue returns the value of the key of the mapping node or nil.
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return node.Value
+}
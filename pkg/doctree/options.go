@@ -0,0 +1,117 @@
+package doctree
+
+import (
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout = 30 * time.Second
+	defaultMaxSize     = 10 << 20
+	defaultWorkers     = 8
+)
+
+type options struct {
+	httpClient   *http.Client
+	maxDepth     int
+	maxDocuments int
+	maxSize      int64
+	allowedHosts []string
+	baseDir      string
+	workers      int
+	cache        *Cache
+	loaders      []Loader
+}
+
+func defaultOptions() options {
+	return options{
+		httpClient:   &http.Client{Timeout: defaultHTTPTimeout}, //nolint:exhaustruct // Zero values are the defaults.
+		maxDepth:     -1,
+		maxDocuments: 0,
+		maxSize:      defaultMaxSize,
+		allowedHosts: nil,
+		baseDir:      "",
+		workers:      defaultWorkers,
+		cache:        nil,
+		loaders:      DefaultLoaders(),
+	}
+}
+
+type Option func(*options)
+
+// WithHTTPClient sets the client for fetching remote documents.
+// Default: client with 30 seconds timeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(opts *options) {
+		opts.httpClient = client
+	}
+}
+
+// WithMaxDepth limits how many links away from the root documents are followed.
+// Zero fetches the root documents only, negative depth means no limit.
+// Default: no limit.
+func WithMaxDepth(depth int) Option {
+	return func(opts *options) {
+		opts.maxDepth = depth
+	}
+}
+
+// WithMaxDocuments limits the number of documents in the graph.
+// Zero means no limit.
+// Default: no limit.
+func WithMaxDocuments(n int) Option {
+	return func(opts *options) {
+		opts.maxDocuments = n
+	}
+}
+
+// WithMaxSize limits the size of a single document in bytes.
+// Default: 10 MiB.
+func WithMaxSize(size int64) Option {
+	return func(opts *options) {
+		opts.maxSize = size
+	}
+}
+
+// WithAllowedHosts restricts linked remote documents to the given hosts.
+// Hosts are matched with and without the port.
+// Default: any host.
+func WithAllowedHosts(hosts ...string) Option {
+	return func(opts *options) {
+		opts.allowedHosts = hosts
+	}
+}
+
+// WithBaseDir restricts linked local documents to the directory.
+// Default: any directory.
+func WithBaseDir(dir string) Option {
+	return func(opts *options) {
+		opts.baseDir = dir
+	}
+}
+
+// WithWorkers sets the number of concurrent fetches.
+// Default: 8.
+func WithWorkers(n int) Option {
+	return func(opts *options) {
+		opts.workers = max(n, 1)
+	}
+}
+
+// WithCache keeps fetched documents in the cache and revalidates only the changed ones.
+// BuildGraph saves the cache and records the changes of the documents, see [Cache.Changes].
+// Default: no cache.
+func WithCache(cache *Cache) Option {
+	return func(opts *options) {
+		opts.cache = cache
+	}
+}
+
+// WithLoaders adds loaders of documents. They take precedence over the loaders of the built-in formats.
+// Default: [DefaultLoaders].
+func WithLoaders(loaders ...Loader) Option {
+	return func(opts *options) {
+		opts.loaders = append(slices.Clone(loaders), opts.loaders...)
+	}
+}
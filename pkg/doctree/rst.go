@@ -0,0 +1,150 @@
+package doctree
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// RSTLoader loads reStructuredText documents as is with section titles as headings.
+// It extracts embedded URIs, hyperlink targets and Sphinx :doc: roles.
+type RSTLoader struct{}
+
+func (RSTLoader) Match(url string) bool {
+	return hasExt(url, ".rst", ".rest")
+}
+
+var (
+	// rstInlineRe matches `text <url>`_ and anonymous `text <url>`__ references.
+	rstInlineRe = regexp.MustCompile("`([^`<]*?)\\s*<([^<>`]+)>`__?")
+	// rstTargetRe matches .. _name: url hyperlink targets.
+	rstTargetRe = regexp.MustCompile(`^\.\. _([^:]+):\s+(\S+)\s*$`)
+	// rstDocRe matches :doc:`path` and :doc:`text <path>` roles.
+	rstDocRe = regexp.MustCompile(":doc:`(?:([^`<]*?)\\s*<([^<>`]+)>|([^`<>]+))`")
+)
+
+const rstAdornmentChars = "=-~^\"'`+*#:._!$%&,/;<>?@[\\]{|}()"
+
+func (RSTLoader) Load(_ string, source []byte) (Parsed, error) {
+	lines := strings.Split(string(source), "\n")
+	parsed := Parsed{Text: string(source), Links: nil, Headings: nil}
+
+	var (
+		styles  []string
+		literal bool
+	)
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+
+		if literal {
+			if strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				continue
+			}
+
+			literal = false
+		}
+
+		if i+1 < len(lines) && isRSTTitle(line, lines[i+1]) {
+			style := lines[i+1][:1]
+			start := i + 1
+
+			if i > 0 && strings.TrimSpace(lines[i-1]) == strings.TrimSpace(lines[i+1]) {
+				// Titles with overline differ from titles with underline only.
+				style += "o"
+				start = i
+			}
+
+			level := slices.Index(styles, style) + 1
+			if level == 0 {
+				styles = append(styles, style)
+				level = len(styles)
+			}
+
+			parsed.Headings = append(parsed.Headings, Heading{
+				Level:  level,
+				Text:   strings.TrimSpace(line),
+				Line:   start,
+				Anchor: "",
+			})
+
+			i++
+
+			continue
+		}
+
+		parsed.Links = append(parsed.Links, rstLinks(line, i+1)...)
+
+		// Indented lines after "::" and code directives are literal blocks.
+		trimmed := strings.TrimSpace(line)
+		literal = strings.HasSuffix(trimmed, "::") || strings.HasPrefix(trimmed, ".. code")
+	}
+
+	return parsed, nil
+}
+
+func rstLinks(line string, lineNo int) []Link {
+	var links []Link
+
+	for _, m := range rstInlineRe.FindAllStringSubmatchIndex(line, -1) {
+		links = append(links, newLink(LinkInline, line[m[2]:m[3]], line[m[4]:m[5]], lineNo, m[0]))
+	}
+
+	if m := rstTargetRe.FindStringSubmatchIndex(line); m != nil && !strings.HasSuffix(line[m[4]:m[5]], "_") {
+		links = append(links, newLink(LinkReference, line[m[2]:m[3]], line[m[4]:m[5]], lineNo, m[0]))
+	}
+
+	for _, m := range rstDocRe.FindAllStringSubmatchIndex(line, -1) {
+		text, dest := "", ""
+
+		if m[4] >= 0 {
+			text, dest = line[m[2]:m[3]], line[m[4]:m[5]]
+		} else {
+			dest = line[m[6]:m[7]]
+			text = dest
+		}
+
+		if path.Ext(dest) == "" {
+			dest += ".rst"
+		}
+
+		links = append(links, newLink(LinkInline, text, dest, lineNo, m[0]))
+	}
+
+	return links
+}
+
+// isRSTTitle reports whether the line is a section title underlined by the next line.
+func isRSTTitle(line, next string) bool {
+	title := strings.TrimSpace(line)
+	if title == "" || line != strings.TrimLeft(line, " \t") || isRSTAdornment(line) {
+		return false
+	}
+
+	return isRSTAdornment(next) && utf8.RuneCountInString(strings.TrimSpace(next)) >= utf8.RuneCountInString(title)
+}
+
+func isRSTAdornment(line string) bool {
+	line = strings.TrimRight(line, " \t\r")
+	if len(line) < 2 || !strings.ContainsRune(rstAdornmentChars, rune(line[0])) {
+		return false
+	}
+
+	return strings.Count(line, line[:1]) == len(line)
+}
+
+// newLink returns the link found at the byte offset of the line.
+func newLink(kind LinkKind, text, dest string, line, offset int) Link {
+	return Link{
+		Kind:        kind,
+		Text:        strings.TrimSpace(text),
+		Title:       "",
+		Destination: strings.TrimSpace(dest),
+		URL:         "",
+		Fragment:    "",
+		Line:        line,
+		Column:      offset + 1,
+	}
+}
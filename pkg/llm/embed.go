@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+)
+
+// EmbeddingKey returns the cache key of the vector of the text embedded by the model.
+func EmbeddingKey(model, text string) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CosineSimilarity returns the cosine of the angle between two vectors.
+// It is 0 if any of the vectors is zero. Extra elements of a longer vector are ignored.
+func CosineSimilarity(a, b []float32) float32 {
+	var dot, na, nb float64
+
+	for i := range min(len(a), len(b)) {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+
+	if na == 0 || nb == 0 {
+		return 0
+	}
+
+	return float32(dot / (math.Sqrt(na) * math.Sqrt(nb)))
+}
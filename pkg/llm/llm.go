@@ -7,6 +7,9 @@ import (
 	"strings"
 )
 
+// CharsPerToken is a rough estimation of characters per LLM token.
+const CharsPerToken = 4
+
 var ErrNotStopDoneReason = errors.New("reason of done is not \"stop\"")
 
 type MessageGenerator interface {
@@ -18,10 +21,36 @@ type Embedder interface {
 	Embed(ctx context.Context, text string) ([]float32, error)
 }
 
+// ModelNamer is implemented by generators and embedders which can report the name of their model.
+type ModelNamer interface {
+	Model() string
+}
+
 type Message struct {
 	Role      Role
 	Content   string
 	ToolCalls []ToolCallFunction
+	// Usage is reported by generators for generated messages, it's ignored in the history.
+	Usage Usage
+}
+
+// Usage is the number of tokens spent on a generation.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Total returns the number of prompt and completion tokens.
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add returns the sum of usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+	}
 }
 
 type Role int
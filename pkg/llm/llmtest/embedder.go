@@ -0,0 +1,80 @@
+// Package llmtest provides fakes of LLM interfaces for tests.
+package llmtest
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Embedder is a deterministic fake embedder. It maps a text to the normalized
+// bag-of-words vector, so texts sharing words are close to each other.
+type Embedder struct {
+	// ModelName is reported by the Model method.
+	ModelName string
+	// Dimension is the length of produced vectors.
+	Dimension int
+	// Err is returned by Embed if set, simulating an unavailable embedder.
+	Err error
+
+	calls atomic.Int64
+}
+
+// NewEmbedder returns the fake embedder of the model producing vectors of the given dimension.
+func NewEmbedder(model string, dim int) *Embedder {
+	return &Embedder{
+		ModelName: model,
+		Dimension: dim,
+		Err:       nil,
+		calls:     atomic.Int64{},
+	}
+}
+
+func (e *Embedder) Model() string {
+	return e.ModelName
+}
+
+// Calls returns the number of Embed calls.
+func (e *Embedder) Calls() int {
+	return int(e.calls.Load())
+}
+
+func (e *Embedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.calls.Add(1)
+
+	if e.Err != nil {
+		return nil, e.Err
+	}
+
+	vec := make([]float32, e.Dimension)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, w := range words {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+
+		vec[int(h.Sum32()%uint32(e.Dimension))]++
+	}
+
+	var norm float32
+
+	for _, v := range vec {
+		norm += v * v
+	}
+
+	if norm > 0 {
+		norm = float32(math.Sqrt(float64(norm)))
+
+		for i := range vec {
+			vec[i] /= norm
+		}
+	}
+
+	return vec, nil
+}
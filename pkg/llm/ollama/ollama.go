@@ -46,6 +46,10 @@ func NewGenerator(serverURL string, model string, opts ...Option) (*LLM, error)
 	}, nil
 }
 
+func (ollm *LLM) Model() string {
+	return ollm.model
+}
+
 func (ollm *LLM) Generate(ctx context.Context, history []llm.Message, tools []llm.ToolFunction) (llm.Message, error) {
 	stream := false
 
@@ -90,6 +94,13 @@ func (ollm *LLM) Generate(ctx context.Context, history []llm.Message, tools []ll
 		msg.Content += msgChunk.Content
 		msg.ToolCalls = append(msg.ToolCalls, msgChunk.ToolCalls...)
 
+		if resp.Done {
+			msg.Usage = llm.Usage{
+				PromptTokens:     resp.PromptEvalCount,
+				CompletionTokens: resp.EvalCount,
+			}
+		}
+
 		return nil
 	}); err != nil {
 		return llm.Message{}, fmt.Errorf("ollama client: chat: %w", err)
@@ -134,6 +145,10 @@ func NewEmbedder(serverURL string, model string, opts ...Option) (*Embedder, err
 	}, nil
 }
 
+func (mbd *Embedder) Model() string {
+	return mbd.model
+}
+
 func (mbd *Embedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	opts, err := mbd.options.ollamaOptions.AsMapParams()
 	if err != nil {
@@ -302,6 +317,7 @@ func convertResponseToMessage(msg api.Message) (llm.Message, error) {
 		Role:      role,
 		Content:   msg.Content,
 		ToolCalls: toolCalls,
+		Usage:     llm.Usage{PromptTokens: 0, CompletionTokens: 0},
 	}
 
 	return llmMsg, nil
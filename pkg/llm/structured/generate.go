@@ -0,0 +1,120 @@
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+var ErrRepairFailed = errors.New("response is still invalid after repairs")
+
+// Decode validates the JSON document against the schema and decodes it into T.
+func Decode[T any](schema *Schema, data []byte) (T, error) {
+	var v T
+
+	if err := schema.Validate(data); err != nil {
+		return v, err
+	}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+
+	return v, nil
+}
+
+// Generate generates the next message of the history and decodes it into T.
+// An invalid response is sent back to the model with the validation errors,
+// so it can repair the answer, see [WithRepairAttempts]. The returned usage
+// sums up all generations.
+func Generate[T any](
+	ctx context.Context, gen llm.MessageGenerator, schema *Schema, history []llm.Message, opts ...Option,
+) (T, llm.Usage, error) {
+	o := options{
+		repairAttempts: defaultRepairAttempts,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var (
+		zero  T
+		usage llm.Usage
+	)
+
+	history = append([]llm.Message(nil), history...)
+
+	for attempt := 0; ; attempt++ {
+		msg, err := gen.Generate(ctx, history, nil)
+		if err != nil {
+			return zero, usage, fmt.Errorf("generate: %w", err)
+		}
+
+		usage = usage.Add(msg.Usage)
+
+		v, err := Decode[T](schema, []byte(msg.Content))
+		if err == nil {
+			return v, usage, nil
+		}
+
+		if attempt >= o.repairAttempts {
+			return zero, usage, fmt.Errorf("%w: %d repairs: %w", ErrRepairFailed, attempt, err)
+		}
+
+		history = append(history, msg, repairRequest(err))
+	}
+}
+
+// repairRequest asks the model to fix the answer.
+func repairRequest(err error) llm.Message {
+	sb := strings.Builder{}
+
+	sb.WriteString("Your answer doesn't match the JSON schema:\n")
+
+	problems := validationErrors(err)
+	if len(problems) == 0 {
+		sb.WriteString("- " + err.Error() + "\n")
+	}
+
+	for _, p := range problems {
+		sb.WriteString("- " + p.Error() + "\n")
+	}
+
+	sb.WriteString("Answer again with the corrected JSON only.")
+
+	return llm.Message{
+		Role:      llm.User,
+		Content:   sb.String(),
+		ToolCalls: nil,
+		Usage:     llm.Usage{PromptTokens: 0, CompletionTokens: 0},
+	}
+}
+
+// validationErrors collects the validation errors from the tree of errors.
+func validationErrors(err error) []*ValidationError {
+	var verr *ValidationError
+
+	switch e := err.(type) { //nolint:errorlint // Walking the tree of errors.
+	case interface{ Unwrap() []error }:
+		var errs []*ValidationError
+
+		for _, err := range e.Unwrap() {
+			errs = append(errs, validationErrors(err)...)
+		}
+
+		return errs
+	case interface{ Unwrap() error }:
+		return validationErrors(e.Unwrap())
+	default:
+		if errors.As(err, &verr) {
+			return []*ValidationError{verr}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package structured_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type answer struct {
+	Name  string   `json:"name"`
+	Kind  string   `json:"kind"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+// scriptedGenerator answers with the responses in order and records the histories.
+type scriptedGenerator struct {
+	responses []string
+	histories [][]llm.Message
+}
+
+func (g *scriptedGenerator) Generate(
+	_ context.Context, history []llm.Message, _ []llm.ToolFunction,
+) (llm.Message, error) {
+	g.histories = append(g.histories, history)
+
+	if len(g.responses) == 0 {
+		return llm.Message{}, errors.New("no more responses")
+	}
+
+	content := g.responses[0]
+	g.responses = g.responses[1:]
+
+	return llm.Message{
+		Role:    llm.Assistant,
+		Content: content,
+		Usage:   llm.Usage{PromptTokens: 10, CompletionTokens: 1},
+	}, nil
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	schema, err := structured.ParseSchema([]byte(testSchema))
+	require.NoError(t, err)
+
+	history := []llm.Message{{Role: llm.User, Content: "Describe the issue."}}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		gen := &scriptedGenerator{responses: []string{`{"name":"a","kind":"bug","count":2}`}}
+
+		got, usage, err := structured.Generate[answer](context.Background(), gen, schema, history)
+		require.NoError(t, err)
+
+		assert.Equal(t, answer{Name: "a", Kind: "bug", Count: 2}, got)
+		assert.Equal(t, 11, usage.Total())
+		assert.Len(t, gen.histories, 1)
+	})
+
+	t.Run("repaired", func(t *testing.T) {
+		t.Parallel()
+
+		gen := &scriptedGenerator{responses: []string{
+			`{"name":"a","kind":"chore"}`,
+			`{"name":"a","kind":"feature","tags":["x"]}`,
+		}}
+
+		got, usage, err := structured.Generate[answer](context.Background(), gen, schema, history)
+		require.NoError(t, err)
+
+		assert.Equal(t, answer{Name: "a", Kind: "feature", Tags: []string{"x"}}, got)
+		assert.Equal(t, 22, usage.Total())
+		require.Len(t, gen.histories, 2)
+
+		repair := gen.histories[1]
+		require.Len(t, repair, 3)
+		assert.Equal(t, `{"name":"a","kind":"chore"}`, repair[1].Content)
+		assert.Equal(t, llm.User, repair[2].Role)
+		assert.Contains(t, repair[2].Content, `- $.kind: value "chore" is not one of ["bug","feature"]`)
+		assert.Len(t, history, 1, "history of the caller must not change")
+	})
+
+	t.Run("repair failed", func(t *testing.T) {
+		t.Parallel()
+
+		gen := &scriptedGenerator{responses: []string{`{}`, `{}`}}
+
+		_, _, err := structured.Generate[answer](context.Background(), gen, schema, history,
+			structured.WithRepairAttempts(1))
+		require.ErrorIs(t, err, structured.ErrRepairFailed)
+		require.ErrorIs(t, err, structured.ErrSchemaMismatch)
+		assert.Len(t, gen.histories, 2)
+	})
+
+	t.Run("generation error", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := structured.Generate[answer](context.Background(), &scriptedGenerator{}, schema, history)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, structured.ErrRepairFailed)
+	})
+}
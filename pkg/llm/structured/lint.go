@@ -0,0 +1,231 @@
+package structured
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// keywords are the supported JSON Schema keywords.
+var keywords = []string{
+	"additionalProperties", "description", "enum", "items", "properties", "required", "title", "type",
+}
+
+var types = []string{"array", "boolean", "integer", "null", "number", "object", "string"}
+
+// LintFormat checks the envelope of a response schema and the schema itself, see [Lint].
+func LintFormat(b []byte) error {
+	f, err := ParseFormat(b)
+	if err != nil {
+		return err
+	}
+
+	return Lint(f.JSONSchema.Schema, f.JSONSchema.Strict)
+}
+
+// Lint checks the JSON schema for mistakes: unknown keywords, properties which are
+// not schemas, required properties which are not defined, arrays without items.
+// Strict schemas must also require all properties of objects and forbid additional
+// ones, as structured outputs do. All problems are reported as [ValidationError]s
+// wrapped with [ErrInvalidSchema].
+func Lint(b []byte, strict bool) error {
+	var v any
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	l := linter{strict: strict, errs: nil}
+	l.schema("$", v)
+
+	if len(l.errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidSchema, errors.Join(l.errs...))
+	}
+
+	return nil
+}
+
+type linter struct {
+	strict bool
+	errs   []error
+}
+
+func (l *linter) report(path, format string, args ...any) {
+	l.errs = append(l.errs, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) schema(path string, v any) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		l.report(path, "schema must be an object, got %s", typeOf(v))
+
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		if slices.Contains(keywords, name) {
+			continue
+		}
+
+		if kw, ok := similarKeyword(name); ok {
+			l.report(path, "unknown keyword %q, did you mean %q?", name, kw)
+		} else {
+			l.report(path, "unknown keyword %q", name)
+		}
+	}
+
+	typs := l.types(path, obj["type"])
+	props := l.properties(path, obj["properties"])
+
+	l.required(path, obj["required"], props)
+	l.enum(path, obj)
+
+	if additional, ok := obj["additionalProperties"]; ok {
+		if _, isBool := additional.(bool); !isBool {
+			l.report(path+".additionalProperties", "must be a boolean")
+		}
+	}
+
+	if items, ok := obj["items"]; ok {
+		l.schema(path+".items", items)
+	} else if slices.Contains(typs, "array") {
+		l.report(path, "array without items")
+	}
+
+	if l.strict && slices.Contains(typs, "object") {
+		l.strictObject(path, obj, props)
+	}
+}
+
+func (l *linter) types(path string, v any) []string {
+	var typs []string
+
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		typs = []string{v}
+	case []any:
+		for _, t := range v {
+			s, ok := t.(string)
+			if !ok {
+				l.report(path+".type", "must be a string or an array of strings")
+
+				return nil
+			}
+
+			typs = append(typs, s)
+		}
+	default:
+		l.report(path+".type", "must be a string or an array of strings")
+
+		return nil
+	}
+
+	for _, t := range typs {
+		if !slices.Contains(types, t) {
+			l.report(path+".type", "unknown type %q", t)
+		}
+	}
+
+	return typs
+}
+
+func (l *linter) properties(path string, v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+
+	props, ok := v.(map[string]any)
+	if !ok {
+		l.report(path+".properties", "must be an object")
+
+		return nil
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		propPath := path + ".properties." + name
+
+		if _, isSchema := props[name].(map[string]any); !isSchema && slices.Contains(keywords, name) {
+			l.report(propPath, "keyword %q is placed inside properties", name)
+
+			continue
+		}
+
+		l.schema(propPath, props[name])
+	}
+
+	return props
+}
+
+func (l *linter) required(path string, v any, props map[string]any) {
+	if v == nil {
+		return
+	}
+
+	names, ok := v.([]any)
+	if !ok {
+		l.report(path+".required", "must be an array of strings")
+
+		return
+	}
+
+	for _, n := range names {
+		name, ok := n.(string)
+		if !ok {
+			l.report(path+".required", "must be an array of strings")
+
+			continue
+		}
+
+		if _, ok := props[name]; !ok {
+			l.report(path+".required", "property %q is not defined", name)
+		}
+	}
+}
+
+func (l *linter) enum(path string, obj map[string]any) {
+	v, ok := obj["enum"]
+	if !ok {
+		return
+	}
+
+	if values, ok := v.([]any); !ok || len(values) == 0 {
+		l.report(path+".enum", "must be a non-empty array")
+	}
+}
+
+func (l *linter) strictObject(path string, obj, props map[string]any) {
+	if additional, ok := obj["additionalProperties"].(bool); !ok || additional {
+		l.report(path, "strict schema must set additionalProperties to false")
+	}
+
+	required, _ := obj["required"].([]any)
+
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		if !slices.Contains(required, any(name)) {
+			l.report(path, "strict schema must require property %q", name)
+		}
+	}
+}
+
+// similarKeyword finds the keyword differing from the name only by case and separators,
+// e.g. "additional_properties".
+func similarKeyword(name string) (string, bool) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+
+	for _, kw := range keywords {
+		if normalize(kw) == normalize(name) {
+			return kw, true
+		}
+	}
+
+	return "", false
+}
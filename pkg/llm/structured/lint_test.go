@@ -0,0 +1,87 @@
+package structured_test
+
+import (
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		schema string
+		strict bool
+		want   []string
+	}{
+		{
+			name:   "valid",
+			schema: testSchema,
+		},
+		{
+			name:   "valid strict",
+			schema: `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"],"additionalProperties":false}`,
+			strict: true,
+		},
+		{
+			name: "misplaced required",
+			schema: `{"type":"object","properties":{"a":{"type":"string"},"required":["a"]},` +
+				`"additional_properties":false}`,
+			want: []string{
+				`$: unknown keyword "additional_properties", did you mean "additionalProperties"?`,
+				`$.properties.required: keyword "required" is placed inside properties`,
+			},
+		},
+		{
+			name:   "undefined required property",
+			schema: `{"type":"object","properties":{"a":{"type":"string"}},"required":["b"]}`,
+			want:   []string{`$.required: property "b" is not defined`},
+		},
+		{
+			name:   "bad types and arrays",
+			schema: `{"type":"object","properties":{"a":{"type":"list"},"b":{"type":["array","null"]},"c":1}}`,
+			want: []string{
+				`$.properties.a.type: unknown type "list"`,
+				`$.properties.b: array without items`,
+				`$.properties.c: schema must be an object, got number`,
+			},
+		},
+		{
+			name:   "strict object",
+			schema: `{"type":"object","properties":{"a":{"type":"string"}}}`,
+			strict: true,
+			want: []string{
+				`$: strict schema must set additionalProperties to false`,
+				`$: strict schema must require property "a"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := structured.Lint([]byte(tt.schema), tt.strict)
+			if len(tt.want) == 0 {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, structured.ErrInvalidSchema)
+
+			var got []string
+
+			for _, e := range unwrapAll(err) {
+				if verr, ok := e.(*structured.ValidationError); ok { //nolint:errorlint // Leaves of the tree.
+					got = append(got, verr.Error())
+				}
+			}
+
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
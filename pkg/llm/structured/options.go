@@ -0,0 +1,19 @@
+package structured
+
+// defaultRepairAttempts is the number of times an invalid response is sent back to the model.
+const defaultRepairAttempts = 2
+
+type options struct {
+	repairAttempts int
+}
+
+type Option func(*options)
+
+// WithRepairAttempts sets how many times an invalid response is sent back to the model
+// with the validation errors. Zero disables repairs.
+// Default: 2.
+func WithRepairAttempts(n int) Option {
+	return func(opts *options) {
+		opts.repairAttempts = max(n, 0)
+	}
+}
@@ -0,0 +1,251 @@
+package structured
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedType = errors.New("unsupported type")
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// SchemaOf generates the strict schema of the type T as encoding/json encodes it:
+//
+//   - fields are named by their json tags, fields tagged "-" and unexported ones are skipped,
+//     embedded structs without tags are inlined;
+//   - all properties are required and additional properties are forbidden;
+//   - pointers and slices are nullable, because nil values are encoded as null;
+//   - the "description" tag describes the property;
+//   - the "enum" tag lists comma-separated allowed values of the property;
+//   - types implementing [encoding.TextMarshaler] and []byte are strings.
+//
+// Maps, interfaces, channels, functions, complex numbers and recursive types are not supported.
+func SchemaOf[T any]() (*Schema, error) {
+	g := generator{visiting: map[reflect.Type]bool{}}
+
+	return g.schema(reflect.TypeFor[T]())
+}
+
+type generator struct {
+	visiting map[reflect.Type]bool
+}
+
+func (g *generator) schema(typ reflect.Type) (*Schema, error) {
+	nullable := false
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+		nullable = true
+	}
+
+	s, err := g.nonNullSchema(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullable || typ.Kind() == reflect.Slice {
+		s.Type = append(s.Type, "null")
+	}
+
+	return s, nil
+}
+
+func (g *generator) nonNullSchema(typ reflect.Type) (*Schema, error) {
+	if typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return newSchema("string"), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return newSchema("boolean"), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return newSchema("integer"), nil
+	case reflect.Float32, reflect.Float64:
+		return newSchema("number"), nil
+	case reflect.String:
+		return newSchema("string"), nil
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 && typ.Kind() == reflect.Slice {
+			return newSchema("string"), nil
+		}
+
+		items, err := g.schema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		s := newSchema("array")
+		s.Items = items
+
+		return s, nil
+	case reflect.Struct:
+		return g.object(typ)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, typ)
+	}
+}
+
+func newSchema(typ string) *Schema {
+	return &Schema{
+		Type:                 Types{typ},
+		Description:          "",
+		Properties:           nil,
+		Required:             nil,
+		Items:                nil,
+		Enum:                 nil,
+		AdditionalProperties: nil,
+	}
+}
+
+func (g *generator) object(typ reflect.Type) (*Schema, error) {
+	if g.visiting[typ] {
+		return nil, fmt.Errorf("%w: recursive type %s", ErrUnsupportedType, typ)
+	}
+
+	g.visiting[typ] = true
+	defer delete(g.visiting, typ)
+
+	forbid := false
+
+	s := newSchema("object")
+	s.Properties = map[string]*Schema{}
+	s.Required = []string{}
+	s.AdditionalProperties = &forbid
+
+	if err := g.fields(s, typ); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (g *generator) fields(s *Schema, typ reflect.Type) error {
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+
+		tag := f.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+
+		if name == "-" && tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				if err := g.fields(s, embedded); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := g.property(f)
+		if err != nil {
+			return fmt.Errorf("field %s.%s: %w", typ, f.Name, err)
+		}
+
+		s.Properties[name] = prop
+		s.Required = append(s.Required, name)
+	}
+
+	return nil
+}
+
+func (g *generator) property(f reflect.StructField) (*Schema, error) {
+	prop, err := g.schema(f.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	prop.Description = f.Tag.Get("description")
+
+	if enum, ok := f.Tag.Lookup("enum"); ok {
+		prop.Enum, err = enumValues(prop, enum)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return prop, nil
+}
+
+func enumValues(prop *Schema, enum string) ([]any, error) {
+	var values []any
+
+	for _, v := range strings.Split(enum, ",") {
+		switch {
+		case slices.Contains(prop.Type, "string"):
+			values = append(values, v)
+		case slices.Contains(prop.Type, "integer"):
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("enum value %q: %w", v, err)
+			}
+
+			values = append(values, n)
+		default:
+			return nil, fmt.Errorf("%w: enum of %s", ErrUnsupportedType, strings.Join(prop.Type, " or "))
+		}
+	}
+
+	if slices.Contains(prop.Type, "null") {
+		values = append(values, nil)
+	}
+
+	return values, nil
+}
+
+// FormatOf generates the strict {"type":"json_schema","json_schema":{...}} envelope
+// of the schema of T, see [SchemaOf].
+func FormatOf[T any](name string) (json.RawMessage, error) {
+	schema, err := SchemaOf[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	return newFormat(name, schema)
+}
+
+func newFormat(name string, schema *Schema) (json.RawMessage, error) {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("marshal schema: %w", err)
+	}
+
+	f := Format{
+		Type: "json_schema",
+		JSONSchema: NamedSchema{
+			Name:   name,
+			Strict: true,
+			Schema: b,
+		},
+	}
+
+	b, err = json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal format: %w", err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,140 @@
+package structured_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Base struct {
+	ID string `json:"id" description:"Identifier."`
+}
+
+type subtask struct {
+	Title string `json:"title"`
+}
+
+type report struct {
+	Base
+
+	Kind     string            `json:"kind"     enum:"bug,feature"`
+	Priority int               `json:"priority" enum:"1,2,3"`
+	Score    float64           `json:"score"`
+	Done     bool              `json:"done,omitempty"`
+	Subtasks []subtask         `json:"subtasks" description:"Parts of the work."`
+	Parent   *subtask          `json:"parent"`
+	Due      time.Time         `json:"due"`
+	Raw      []byte            `json:"raw"`
+	Labels   [2]string         `json:"labels"`
+	Note     *string           `json:"note"     enum:"a,b"`
+	Untagged string            //nolint:tagliatelle // Named by the field.
+	Skipped  string            `json:"-"`
+	hidden   string            //nolint:unused // Unexported fields are skipped.
+	Meta     map[string]string `json:"-"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	t.Parallel()
+
+	schema, err := structured.SchemaOf[report]()
+	require.NoError(t, err)
+
+	b, err := json.Marshal(schema)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"type": "object",
+		"additionalProperties": false,
+		"required": [
+			"id", "kind", "priority", "score", "done", "subtasks",
+			"parent", "due", "raw", "labels", "note", "Untagged"
+		],
+		"properties": {
+			"id": {"type": "string", "description": "Identifier."},
+			"kind": {"type": "string", "enum": ["bug", "feature"]},
+			"priority": {"type": "integer", "enum": [1, 2, 3]},
+			"score": {"type": "number"},
+			"done": {"type": "boolean"},
+			"subtasks": {
+				"type": ["array", "null"],
+				"description": "Parts of the work.",
+				"items": {
+					"type": "object",
+					"additionalProperties": false,
+					"required": ["title"],
+					"properties": {"title": {"type": "string"}}
+				}
+			},
+			"parent": {
+				"type": ["object", "null"],
+				"additionalProperties": false,
+				"required": ["title"],
+				"properties": {"title": {"type": "string"}}
+			},
+			"due": {"type": "string"},
+			"raw": {"type": ["string", "null"]},
+			"labels": {"type": "array", "items": {"type": "string"}},
+			"note": {"type": ["string", "null"], "enum": ["a", "b", null]},
+			"Untagged": {"type": "string"}
+		}
+	}`, string(b))
+
+	assert.Regexp(t, `^\{"type":"object","required":\["id","kind",.*"properties":\{"id":.*"kind":.*"priority":`, string(b),
+		"properties must be ordered as fields")
+}
+
+func TestSchemaOf_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		Children []node `json:"children"`
+	}
+
+	type withMap struct {
+		Meta map[string]string `json:"meta"`
+	}
+
+	type badEnum struct {
+		N int `json:"n" enum:"one"`
+	}
+
+	_, err := structured.SchemaOf[node]()
+	require.ErrorIs(t, err, structured.ErrUnsupportedType)
+
+	_, err = structured.SchemaOf[withMap]()
+	require.ErrorIs(t, err, structured.ErrUnsupportedType)
+
+	_, err = structured.SchemaOf[badEnum]()
+	require.Error(t, err)
+}
+
+func TestNewSpec(t *testing.T) {
+	t.Parallel()
+
+	spec, err := structured.NewSpec[report]("report")
+	require.NoError(t, err)
+
+	require.NoError(t, structured.LintFormat(spec.Format()))
+
+	f, err := structured.ParseFormat(spec.Format())
+	require.NoError(t, err)
+
+	assert.Equal(t, "report", f.JSONSchema.Name)
+	assert.True(t, f.JSONSchema.Strict)
+
+	valid := `{"id":"1","kind":"bug","priority":2,"score":0.5,"done":false,"subtasks":null,"parent":{"title":"p"},` +
+		`"due":"2025-01-02T00:00:00Z","raw":null,"labels":["a","b"],"note":null,"Untagged":""}`
+
+	got, err := spec.Decode([]byte(valid))
+	require.NoError(t, err)
+
+	assert.Equal(t, "bug", got.Kind)
+	assert.Equal(t, "p", got.Parent.Title)
+
+	_, err = spec.Decode([]byte(`{"id":"1"}`))
+	require.ErrorIs(t, err, structured.ErrSchemaMismatch)
+}
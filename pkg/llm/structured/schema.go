@@ -0,0 +1,319 @@
+// Package structured validates structured outputs of LLMs against JSON schemas.
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidJSON    = errors.New("invalid json")
+	ErrSchemaMismatch = errors.New("value doesn't match schema")
+	ErrInvalidFormat  = errors.New("invalid format")
+)
+
+// Format is the {"type":"json_schema","json_schema":{...}} envelope of a response schema.
+type Format struct {
+	Type       string      `json:"type"`
+	JSONSchema NamedSchema `json:"json_schema"`
+}
+
+type NamedSchema struct {
+	Name   string          `json:"name"`
+	Strict bool            `json:"strict"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema used for structured outputs.
+type Schema struct {
+	Type        Types              `json:"type,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	// AdditionalProperties forbids properties not listed in Properties when false.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"` //nolint:tagliatelle // JSON Schema keyword.
+}
+
+// Types are the allowed JSON types of a value. A single type is encoded as a string.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	var v any = []string(t)
+
+	if len(t) == 1 {
+		v = t[0]
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal types: %w", err)
+	}
+
+	return b, nil
+}
+
+func (t *Types) UnmarshalJSON(b []byte) error {
+	var s string
+
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = Types{s}
+
+		return nil
+	}
+
+	var ss []string
+
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return fmt.Errorf("unmarshal types: %w", err)
+	}
+
+	*t = ss
+
+	return nil
+}
+
+// MarshalJSON encodes the schema with properties ordered as required ones and then
+// by names. Models generate properties in the order of the schema, so it matters,
+// e.g. thoughts should go before the answer.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+
+	p := plain(*s)
+	p.Properties = nil
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("marshal schema: %w", err)
+	}
+
+	if len(s.Properties) == 0 {
+		return b, nil
+	}
+
+	names := slices.DeleteFunc(slices.Clone(s.Required), func(name string) bool {
+		_, ok := s.Properties[name]
+
+		return !ok
+	})
+
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	buf := bytes.NewBuffer(b[:len(b)-1])
+
+	if len(b) > len("{}") {
+		buf.WriteByte(',')
+	}
+
+	buf.WriteString(`"properties":{`)
+
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, fmt.Errorf("marshal property name: %w", err)
+		}
+
+		prop, err := json.Marshal(s.Properties[name])
+		if err != nil {
+			return nil, fmt.Errorf("marshal property %q: %w", name, err)
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(prop)
+	}
+
+	buf.WriteString("}}")
+
+	return buf.Bytes(), nil
+}
+
+// ParseFormat parses the envelope of a response schema.
+func ParseFormat(b []byte) (Format, error) {
+	var f Format
+
+	if err := json.Unmarshal(b, &f); err != nil {
+		return Format{}, fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	}
+
+	if f.Type != "json_schema" || len(f.JSONSchema.Schema) == 0 {
+		return Format{}, fmt.Errorf("%w: expected type \"json_schema\" with a schema", ErrInvalidFormat)
+	}
+
+	return f, nil
+}
+
+// ParseSchema parses the JSON schema.
+func ParseSchema(b []byte) (*Schema, error) {
+	var s Schema
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("unmarshal schema: %w", err)
+	}
+
+	return &s, nil
+}
+
+// ValidationError describes a value not matching the schema.
+type ValidationError struct {
+	// Path is the location of the value, e.g. "$.subtasks[0].title".
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks the JSON document against the schema. All mismatches are
+// reported as [ValidationError]s wrapped with [ErrSchemaMismatch].
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+
+	if dec.More() {
+		return fmt.Errorf("%w: unexpected data after the value", ErrInvalidJSON)
+	}
+
+	if errs := s.validate("$", v); len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrSchemaMismatch, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (s *Schema) validate(path string, v any) []error {
+	if len(s.Type) > 0 && !s.allows(v) {
+		return []error{&ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), typeOf(v)),
+		}}
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(v) {
+		return []error{&ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("value %s is not one of %s", encode(v), encode(s.Enum)),
+		}}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		return s.validateObject(path, v)
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+
+		var errs []error
+
+		for i, item := range v {
+			errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+		}
+
+		return errs
+	default:
+		return nil
+	}
+}
+
+func (s *Schema) validateObject(path string, obj map[string]any) []error {
+	var errs []error
+
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, &ValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		prop, ok := s.Properties[name]
+
+		switch {
+		case ok && prop != nil:
+			errs = append(errs, prop.validate(path+"."+name, obj[name])...)
+		case !ok && s.AdditionalProperties != nil && !*s.AdditionalProperties:
+			errs = append(errs, &ValidationError{Path: path, Message: fmt.Sprintf("unexpected property %q", name)})
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) allows(v any) bool {
+	typ := typeOf(v)
+
+	for _, t := range s.Type {
+		if t == typ || (t == "number" && typ == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Schema) inEnum(v any) bool {
+	enc := encode(v)
+
+	for _, e := range s.Enum {
+		if encode(e) == enc {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+
+		return "number"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func encode(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,133 @@
+package structured_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/llm/structured"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"kind": {"type": "string", "enum": ["bug", "feature"]},
+		"count": {"type": "integer"},
+		"ratio": {"type": "number"},
+		"tags": {"type": ["array", "null"], "items": {"type": "string"}}
+	},
+	"required": ["name", "kind"],
+	"additionalProperties": false
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	t.Parallel()
+
+	schema, err := structured.ParseSchema([]byte(testSchema))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		data     string
+		wantErr  error
+		wantPath []string
+	}{
+		{
+			name: "valid",
+			data: `{"name":"a","kind":"bug","count":1,"ratio":1,"tags":["x"]}`,
+		},
+		{
+			name: "nullable",
+			data: `{"name":"a","kind":"bug","tags":null}`,
+		},
+		{
+			name:    "invalid json",
+			data:    `{"name":`,
+			wantErr: structured.ErrInvalidJSON,
+		},
+		{
+			name:    "trailing data",
+			data:    `{"name":"a","kind":"bug"} {}`,
+			wantErr: structured.ErrInvalidJSON,
+		},
+		{
+			name:     "missing required",
+			data:     `{"name":"a"}`,
+			wantErr:  structured.ErrSchemaMismatch,
+			wantPath: []string{"$"},
+		},
+		{
+			name:     "wrong types",
+			data:     `{"name":1,"kind":"bug","count":1.5,"tags":[1]}`,
+			wantErr:  structured.ErrSchemaMismatch,
+			wantPath: []string{"$.count", "$.name", "$.tags[0]"},
+		},
+		{
+			name:     "enum and additional properties",
+			data:     `{"name":"a","kind":"chore","extra":true}`,
+			wantErr:  structured.ErrSchemaMismatch,
+			wantPath: []string{"$", "$.kind"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := schema.Validate([]byte(tt.data))
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+
+			var paths []string
+
+			for _, e := range unwrapAll(err) {
+				var verr *structured.ValidationError
+				if errors.As(e, &verr) {
+					paths = append(paths, verr.Path)
+				}
+			}
+
+			assert.ElementsMatch(t, tt.wantPath, paths)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	f, err := structured.ParseFormat([]byte(
+		`{"type":"json_schema","json_schema":{"name":"x","strict":true,"schema":{"type":"object"}}}`,
+	))
+	require.NoError(t, err)
+
+	assert.Equal(t, "x", f.JSONSchema.Name)
+	assert.JSONEq(t, `{"type":"object"}`, string(f.JSONSchema.Schema))
+
+	_, err = structured.ParseFormat([]byte(`{"type":"object"}`))
+	require.ErrorIs(t, err, structured.ErrInvalidFormat)
+}
+
+// unwrapAll returns the leaves of the error tree.
+func unwrapAll(err error) []error {
+	switch e := err.(type) { //nolint:errorlint // Walking the tree.
+	case interface{ Unwrap() []error }:
+		var leaves []error
+
+		for _, e := range e.Unwrap() {
+			leaves = append(leaves, unwrapAll(e)...)
+		}
+
+		return leaves
+	case interface{ Unwrap() error }:
+		return append([]error{err}, unwrapAll(e.Unwrap())...)
+	default:
+		return []error{err}
+	}
+}
@@ -0,0 +1,55 @@
+package structured
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+// Spec ties the schema of responses to the Go type T, so they can't drift apart.
+type Spec[T any] struct {
+	schema *Schema
+	format json.RawMessage
+}
+
+// NewSpec generates the schema of T, see [SchemaOf], and its envelope named name.
+// The generated schema is linted as a strict one.
+func NewSpec[T any](name string) (Spec[T], error) {
+	schema, err := SchemaOf[T]()
+	if err != nil {
+		return Spec[T]{}, err
+	}
+
+	format, err := newFormat(name, schema)
+	if err != nil {
+		return Spec[T]{}, err
+	}
+
+	if err := LintFormat(format); err != nil {
+		return Spec[T]{}, err
+	}
+
+	return Spec[T]{schema: schema, format: format}, nil
+}
+
+func (s Spec[T]) Schema() *Schema {
+	return s.schema
+}
+
+// Format returns the {"type":"json_schema","json_schema":{...}} envelope of the schema.
+func (s Spec[T]) Format() json.RawMessage {
+	return s.format
+}
+
+// Decode validates the JSON document and decodes it into T, see [Decode].
+func (s Spec[T]) Decode(data []byte) (T, error) {
+	return Decode[T](s.schema, data)
+}
+
+// Generate generates the next message of the history and decodes it into T, see [Generate].
+func (s Spec[T]) Generate(
+	ctx context.Context, gen llm.MessageGenerator, history []llm.Message, opts ...Option,
+) (T, llm.Usage, error) {
+	return Generate[T](ctx, gen, s.schema, history, opts...)
+}
@@ -0,0 +1,159 @@
+// Package tools dispatches tool calls of LLMs to Go functions.
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+var (
+	ErrUnknownTool     = errors.New("unknown tool")
+	ErrDuplicateTool   = errors.New("duplicate tool")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// Handler executes a tool call and returns the content of the tool message.
+type Handler func(ctx context.Context, args Args) (string, error)
+
+// Tool is a function the model can call.
+type Tool struct {
+	Function llm.ToolFunction
+	Handler  Handler
+}
+
+// Set is a set of tools with unique names.
+type Set struct {
+	tools []Tool
+	index map[string]int
+}
+
+// NewSet returns the set of the tools.
+func NewSet(tools ...Tool) (*Set, error) {
+	set := &Set{tools: nil, index: map[string]int{}}
+
+	if err := set.Add(tools...); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// Add adds the tools to the set.
+func (s *Set) Add(tools ...Tool) error {
+	for _, t := range tools {
+		if _, ok := s.index[t.Function.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateTool, t.Function.Name)
+		}
+
+		s.index[t.Function.Name] = len(s.tools)
+		s.tools = append(s.tools, t)
+	}
+
+	return nil
+}
+
+// Functions returns the functions of the tools in the order they were added.
+func (s *Set) Functions() []llm.ToolFunction {
+	functions := make([]llm.ToolFunction, 0, len(s.tools))
+
+	for _, t := range s.tools {
+		functions = append(functions, t.Function)
+	}
+
+	return functions
+}
+
+// Call checks the required arguments of the call and executes it.
+func (s *Set) Call(ctx context.Context, call llm.ToolCallFunction) (string, error) {
+	i, ok := s.index[call.Name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTool, call.Name)
+	}
+
+	t := s.tools[i]
+
+	for name, param := range t.Function.Parameters {
+		if _, ok := call.Arguments[name]; param.Required && !ok {
+			return "", fmt.Errorf("%s: %w: %s is required", call.Name, ErrInvalidArgument, name)
+		}
+	}
+
+	content, err := t.Handler(ctx, Args(call.Arguments))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", call.Name, err)
+	}
+
+	return content, nil
+}
+
+// Message executes the call and returns the tool message with its result.
+// Errors are reported to the model in the content, so it can correct the call.
+func (s *Set) Message(ctx context.Context, call llm.ToolCallFunction) llm.Message {
+	content, err := s.Call(ctx, call)
+	if err != nil {
+		content = "error: " + err.Error()
+	}
+
+	return llm.Message{
+		Role:      llm.Tool,
+		Content:   content,
+		ToolCalls: nil,
+		Usage:     llm.Usage{PromptTokens: 0, CompletionTokens: 0},
+	}
+}
+
+// Args are the arguments of a tool call decoded from JSON.
+type Args map[string]any
+
+// String returns the string argument, or the empty string if it's missing.
+func (a Args) String(name string) (string, error) {
+	v, ok := a[name]
+	if !ok {
+		return "", nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s must be a string, got %T", ErrInvalidArgument, name, v)
+	}
+
+	return s, nil
+}
+
+// Int returns the integer argument, or the zero if it's missing.
+func (a Args) Int(name string) (int, error) {
+	v, ok := a[name]
+	if !ok {
+		return 0, nil
+	}
+
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case float64:
+		if n == math.Trunc(n) {
+			return int(n), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s must be an integer, got %v", ErrInvalidArgument, name, v)
+}
+
+// Bool returns the boolean argument, or false if it's missing.
+func (a Args) Bool(name string) (bool, error) {
+	v, ok := a[name]
+	if !ok {
+		return false, nil
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %s must be a boolean, got %T", ErrInvalidArgument, name, v)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,127 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBoom = errors.New("boom")
+
+func repeatTool() tools.Tool {
+	return tools.Tool{
+		Function: llm.ToolFunction{
+			Name:        "repeat",
+			Description: "Repeat the text.",
+			Parameters: map[string]llm.FunctionProperty{
+				"text":  {Type: llm.String, Description: "Text.", Required: true},
+				"count": {Type: llm.Integer, Description: "Count."},
+				"fail":  {Type: llm.Boolean, Description: "Fail."},
+			},
+		},
+		Handler: func(_ context.Context, args tools.Args) (string, error) {
+			text, err := args.String("text")
+			if err != nil {
+				return "", err
+			}
+
+			count, err := args.Int("count")
+			if err != nil {
+				return "", err
+			}
+
+			fail, err := args.Bool("fail")
+			if err != nil {
+				return "", err
+			}
+
+			if fail {
+				return "", errBoom
+			}
+
+			return strings.Repeat(text, max(count, 1)), nil
+		},
+	}
+}
+
+func TestSet_Call(t *testing.T) {
+	t.Parallel()
+
+	set, err := tools.NewSet(repeatTool())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		call    llm.ToolCallFunction
+		want    string
+		wantErr error
+	}{
+		{"default", llm.ToolCallFunction{Name: "repeat", Arguments: map[string]any{"text": "a"}}, "a", nil},
+		{
+			"json number",
+			llm.ToolCallFunction{Name: "repeat", Arguments: map[string]any{"text": "a", "count": 3.0}},
+			"aaa",
+			nil,
+		},
+		{"missing required", llm.ToolCallFunction{Name: "repeat", Arguments: nil}, "", tools.ErrInvalidArgument},
+		{
+			"fractional integer",
+			llm.ToolCallFunction{Name: "repeat", Arguments: map[string]any{"text": "a", "count": 1.5}},
+			"",
+			tools.ErrInvalidArgument,
+		},
+		{
+			"wrong type",
+			llm.ToolCallFunction{Name: "repeat", Arguments: map[string]any{"text": 1}},
+			"",
+			tools.ErrInvalidArgument,
+		},
+		{
+			"handler error",
+			llm.ToolCallFunction{Name: "repeat", Arguments: map[string]any{"text": "a", "fail": true}},
+			"",
+			errBoom,
+		},
+		{"unknown", llm.ToolCallFunction{Name: "missing", Arguments: nil}, "", tools.ErrUnknownTool},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := set.Call(context.Background(), tt.call)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSet_Message(t *testing.T) {
+	t.Parallel()
+
+	set, err := tools.NewSet(repeatTool())
+	require.NoError(t, err)
+
+	msg := set.Message(context.Background(), llm.ToolCallFunction{Name: "missing", Arguments: nil})
+	assert.Equal(t, llm.Tool, msg.Role)
+	assert.Equal(t, "error: unknown tool: missing", msg.Content)
+}
+
+func TestNewSet_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	_, err := tools.NewSet(repeatTool(), repeatTool())
+	require.ErrorIs(t, err, tools.ErrDuplicateTool)
+}
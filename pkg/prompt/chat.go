@@ -0,0 +1,121 @@
+package prompt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+// messageMark starts a marker of a new message in the rendered text.
+// Every execution appends a random nonce to it,
+// so the data of a template can't forge the markers.
+const messageMark = "\x00message:"
+
+// message starts a new message of the role. It's used in templates as
+// {{message "system"}}, see [Prompt.Messages].
+func message(role string) (string, error) {
+	return messageFunc(messageMark)(role)
+}
+
+// messageFunc returns the message function printing the marker.
+func messageFunc(mark string) func(role string) (string, error) {
+	return func(role string) (string, error) {
+		r, err := llm.RoleFromString(role)
+		if err != nil {
+			return "", fmt.Errorf("message: %w", err)
+		}
+
+		return mark + r.String() + "\x00", nil
+	}
+}
+
+// newMessageMark returns the message marker unique for an execution.
+func newMessageMark() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("message marker: %w", err)
+	}
+
+	return messageMark + hex.EncodeToString(nonce) + ":", nil
+}
+
+// Messages executes the template and splits the result into chat messages.
+//
+// Every message starts with {{message "<role>"}} where the role is one of
+// "system", "user", "assistant" or "tool", so a template can define a system
+// prompt and few-shot example turns:
+//
+//	{{message "system"}}You are a helpful assistant.
+//	{{message "user"}}2+2
+//	{{message "assistant"}}4
+//	{{message "user"}}{{.Question}}
+//
+// Contents of messages are trimmed of surrounding white space. A template
+// without messages renders to a single user message.
+func (p *Prompt) Messages(data any) ([]llm.Message, error) {
+	text, mark, err := p.executeMarked(data)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(text, mark)
+
+	if len(parts) == 1 {
+		return []llm.Message{newMessage(llm.User, text)}, nil
+	}
+
+	if strings.TrimSpace(parts[0]) != "" {
+		return nil, fmt.Errorf("template %q: %w", p.tpl.Name(), ErrTextOutsideMessage)
+	}
+
+	msgs := make([]llm.Message, 0, len(parts)-1)
+
+	for _, part := range parts[1:] {
+		role, content, _ := strings.Cut(part, "\x00")
+
+		r, err := llm.RoleFromString(role)
+		if err != nil {
+			return nil, fmt.Errorf("template %q: %w", p.tpl.Name(), err)
+		}
+
+		msgs = append(msgs, newMessage(r, content))
+	}
+
+	return msgs, nil
+}
+
+// executeMarked executes the template with the message markers unique for the execution.
+// It returns the text and the marker.
+func (p *Prompt) executeMarked(data any) (string, string, error) {
+	mark, err := newMessageMark()
+	if err != nil {
+		return "", "", err
+	}
+
+	tpl, err := p.tpl.Clone()
+	if err != nil {
+		return "", "", fmt.Errorf("clone template %q: %w", p.tpl.Name(), err)
+	}
+
+	tpl.Funcs(template.FuncMap{"message": messageFunc(mark)})
+
+	text, err := (&Prompt{tpl: tpl}).execute(data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return text, mark, nil
+}
+
+func newMessage(role llm.Role, content string) llm.Message {
+	return llm.Message{
+		Role:      role,
+		Content:   strings.TrimSpace(content),
+		ToolCalls: nil,
+		Usage:     llm.Usage{PromptTokens: 0, CompletionTokens: 0},
+	}
+}
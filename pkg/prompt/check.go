@@ -0,0 +1,323 @@
+package prompt
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"text/template"
+	"text/template/parse"
+)
+
+// checker validates the field references of templates against the type of their data.
+// A nil type is unknown, e.g. a value of an empty interface, and is not checked.
+type checker struct {
+	set     *template.Template
+	funcs   template.FuncMap
+	visited map[visit]bool
+}
+
+type visit struct {
+	name string
+	dot  reflect.Type
+}
+
+type scope struct {
+	dot  reflect.Type
+	vars map[string]reflect.Type
+}
+
+func (s scope) with(dot reflect.Type) scope {
+	return scope{dot: dot, vars: maps.Clone(s.vars)}
+}
+
+// checkFields checks that every field and method referenced by the template
+// and the templates it invokes exists on the type of data.
+func checkFields(tpl *template.Template, typ reflect.Type) error {
+	c := checker{
+		set:     tpl,
+		funcs:   Funcs(),
+		visited: map[visit]bool{},
+	}
+
+	return c.template(tpl.Name(), typ)
+}
+
+func (c *checker) template(name string, dot reflect.Type) error {
+	key := visit{name: name, dot: dot}
+
+	if c.visited[key] {
+		return nil
+	}
+
+	c.visited[key] = true
+
+	t := c.set.Lookup(name)
+	if t == nil || t.Tree == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+	}
+
+	return c.node(t.Tree, t.Root, scope{dot: dot, vars: map[string]reflect.Type{"$": dot}})
+}
+
+func (c *checker) node(tree *parse.Tree, node parse.Node, sc scope) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		return c.list(tree, n, sc)
+	case *parse.ActionNode:
+		typ, err := c.pipe(tree, n.Pipe, sc)
+		if err != nil {
+			return err
+		}
+
+		declare(sc, n.Pipe, typ)
+	case *parse.IfNode:
+		return c.branch(tree, &n.BranchNode, sc, func(reflect.Type) scope { return sc.with(sc.dot) })
+	case *parse.WithNode:
+		return c.branch(tree, &n.BranchNode, sc, func(typ reflect.Type) scope {
+			inner := sc.with(typ)
+			declare(inner, n.Pipe, typ)
+
+			return inner
+		})
+	case *parse.RangeNode:
+		return c.branch(tree, &n.BranchNode, sc, func(typ reflect.Type) scope {
+			key, elem := elemTypes(typ)
+			inner := sc.with(elem)
+
+			switch len(n.Pipe.Decl) {
+			case 1:
+				inner.vars[n.Pipe.Decl[0].Ident[0]] = elem
+			case 2: //nolint:mnd // Key and element variables.
+				inner.vars[n.Pipe.Decl[0].Ident[0]] = key
+				inner.vars[n.Pipe.Decl[1].Ident[0]] = elem
+			}
+
+			return inner
+		})
+	case *parse.TemplateNode:
+		typ, err := c.pipe(tree, n.Pipe, sc)
+		if err != nil {
+			return err
+		}
+
+		if err := c.template(n.Name, typ); err != nil {
+			return fmt.Errorf("%s: %w", location(tree, n), err)
+		}
+	}
+
+	return nil
+}
+
+func (c *checker) list(tree *parse.Tree, list *parse.ListNode, sc scope) error {
+	if list == nil {
+		return nil
+	}
+
+	for _, n := range list.Nodes {
+		if err := c.node(tree, n, sc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// branch checks the pipeline, the list in the inner scope and the else list in the outer scope.
+func (c *checker) branch(tree *parse.Tree, n *parse.BranchNode, sc scope, inner func(reflect.Type) scope) error {
+	typ, err := c.pipe(tree, n.Pipe, sc)
+	if err != nil {
+		return err
+	}
+
+	if err := c.list(tree, n.List, inner(typ)); err != nil {
+		return err
+	}
+
+	return c.list(tree, n.ElseList, sc.with(sc.dot))
+}
+
+// pipe returns the type of the pipeline result.
+func (c *checker) pipe(tree *parse.Tree, pipe *parse.PipeNode, sc scope) (reflect.Type, error) {
+	if pipe == nil {
+		return nil, nil
+	}
+
+	var typ reflect.Type
+
+	for _, cmd := range pipe.Cmds {
+		var err error
+
+		typ, err = c.command(tree, cmd, sc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return typ, nil
+}
+
+func (c *checker) command(tree *parse.Tree, cmd *parse.CommandNode, sc scope) (reflect.Type, error) {
+	var typ reflect.Type
+
+	for i, arg := range cmd.Args {
+		t, err := c.arg(tree, arg, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			typ = t
+		}
+	}
+
+	return typ, nil
+}
+
+func (c *checker) arg(tree *parse.Tree, node parse.Node, sc scope) (reflect.Type, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return sc.dot, nil
+	case *parse.FieldNode:
+		return fields(tree, n, sc.dot, n.Ident)
+	case *parse.VariableNode:
+		return fields(tree, n, sc.vars[n.Ident[0]], n.Ident[1:])
+	case *parse.ChainNode:
+		typ, err := c.arg(tree, n.Node, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		return fields(tree, n, typ, n.Field)
+	case *parse.PipeNode:
+		return c.pipe(tree, n, sc)
+	case *parse.IdentifierNode:
+		return c.funcResult(n.Ident), nil
+	default:
+		return nil, nil
+	}
+}
+
+// funcResult returns the result type of the helper function, builtin functions are unknown.
+func (c *checker) funcResult(name string) reflect.Type {
+	fn, ok := c.funcs[name]
+	if !ok {
+		return nil
+	}
+
+	typ := reflect.TypeOf(fn)
+
+	if typ.NumOut() == 0 {
+		return nil
+	}
+
+	return typ.Out(0)
+}
+
+func fields(tree *parse.Tree, node parse.Node, typ reflect.Type, names []string) (reflect.Type, error) {
+	for _, name := range names {
+		if typ == nil {
+			return nil, nil
+		}
+
+		next, ok := field(typ, name)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w: %s has no field or method %q", location(tree, node), ErrUnknownField, typ, name)
+		}
+
+		typ = next
+	}
+
+	return typ, nil
+}
+
+// field returns the type of the field or the method result. The type is nil
+// when it is unknown.
+func field(typ reflect.Type, name string) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Interface {
+		if m, ok := typ.MethodByName(name); ok {
+			return result(m.Type), true
+		}
+
+		// The dynamic type is unknown.
+		return nil, true
+	}
+
+	ptr := typ
+	if typ.Kind() != reflect.Pointer {
+		ptr = reflect.PointerTo(typ)
+	}
+
+	if m, ok := ptr.MethodByName(name); ok {
+		return result(m.Type), true
+	}
+
+	typ = deref(typ)
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		f, ok := typ.FieldByName(name)
+		if !ok || !f.IsExported() {
+			return nil, false
+		}
+
+		return f.Type, true
+	case reflect.Map:
+		return typ.Elem(), typ.Key().Kind() == reflect.String
+	case reflect.Interface:
+		return nil, true
+	default:
+		return nil, false
+	}
+}
+
+func result(fn reflect.Type) reflect.Type {
+	if fn.NumOut() == 0 {
+		return nil
+	}
+
+	return fn.Out(0)
+}
+
+// elemTypes returns the key and element types of ranging over the typ.
+func elemTypes(typ reflect.Type) (reflect.Type, reflect.Type) {
+	if typ == nil {
+		return nil, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeFor[int](), typ.Elem()
+	case reflect.Map:
+		return typ.Key(), typ.Elem()
+	case reflect.Chan:
+		return nil, typ.Elem()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return typ, typ
+	default:
+		return nil, nil
+	}
+}
+
+func deref(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+// declare sets the type of the variable declared by the pipeline.
+func declare(sc scope, pipe *parse.PipeNode, typ reflect.Type) {
+	if pipe == nil || pipe.IsAssign || len(pipe.Decl) == 0 {
+		return
+	}
+
+	sc.vars[pipe.Decl[0].Ident[0]] = typ
+}
+
+func location(tree *parse.Tree, node parse.Node) string {
+	loc, _ := tree.ErrorContext(node)
+
+	return loc
+}
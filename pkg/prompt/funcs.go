@@ -0,0 +1,213 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/WinPooh32/go-coder/pkg/code/lines"
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+// minFence is the minimal length of a markdown code fence.
+const minFence = 3
+
+var extLanguages = map[string]string{
+	".c":        "c",
+	".cc":       "cpp",
+	".cpp":      "cpp",
+	".css":      "css",
+	".go":       "go",
+	".h":        "c",
+	".hpp":      "cpp",
+	".html":     "html",
+	".java":     "java",
+	".js":       "javascript",
+	".json":     "json",
+	".kt":       "kotlin",
+	".markdown": "markdown",
+	".md":       "markdown",
+	".proto":    "protobuf",
+	".py":       "python",
+	".rb":       "ruby",
+	".rs":       "rust",
+	".sh":       "bash",
+	".sql":      "sql",
+	".toml":     "toml",
+	".tpl":      "gotemplate",
+	".ts":       "typescript",
+	".xml":      "xml",
+	".yaml":     "yaml",
+	".yml":      "yaml",
+}
+
+var fileLanguages = map[string]string{
+	"Dockerfile": "dockerfile",
+	"Makefile":   "makefile",
+	"go.mod":     "gomod",
+	"justfile":   "just",
+}
+
+// Funcs returns the helper functions available in every prompt template:
+//
+//   - json: marshals a value to indented JSON;
+//   - indent: indents every non-empty line by n spaces;
+//   - numberLines: prefixes lines with their numbers, see [lines.AddNumbers];
+//   - truncateTokens: cuts a text to approximately n LLM tokens;
+//   - join: joins the elements of a slice with a separator;
+//   - codeFence: wraps a code into a markdown fence, the language is taken
+//     from a language name or a file name, or detected from the code itself;
+//   - message: starts a chat message of the role, see [Prompt.Messages].
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"json":           toJSON,
+		"indent":         indent,
+		"numberLines":    lines.AddNumbers,
+		"truncateTokens": truncateTokens,
+		"join":           join,
+		"codeFence":      codeFence,
+		"message":        message,
+	}
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal json: %w", err)
+	}
+
+	return string(b), nil
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	ss := strings.Split(s, "\n")
+
+	for i, line := range ss {
+		if line != "" {
+			ss[i] = pad + line
+		}
+	}
+
+	return strings.Join(ss, "\n")
+}
+
+func truncateTokens(n int, s string) string {
+	limit := n * llm.CharsPerToken
+
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	cut := string([]rune(s)[:max(limit, 0)])
+
+	// Prefer to cut at a word boundary when it doesn't lose too much text.
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > len(cut)/2 {
+		cut = strings.TrimRightFunc(cut[:i], unicode.IsSpace)
+	}
+
+	return cut + "…"
+}
+
+func join(sep string, items any) (string, error) {
+	if ss, ok := items.([]string); ok {
+		return strings.Join(ss, sep), nil
+	}
+
+	v := reflect.ValueOf(items)
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: %w: %T", ErrNotSlice, items)
+	}
+
+	ss := make([]string, v.Len())
+
+	for i := range v.Len() {
+		ss[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+
+	return strings.Join(ss, sep), nil
+}
+
+func codeFence(lang, code string) string {
+	fence := strings.Repeat("`", max(minFence, longestRun(code, '`')+1))
+
+	code = strings.TrimRight(code, "\n")
+
+	return fence + detectLanguage(lang, code) + "\n" + code + "\n" + fence
+}
+
+// detectLanguage returns a language of the code fence. The hint is either
+// a language name or a file name; when it's empty the code is sniffed.
+func detectLanguage(hint, code string) string {
+	if hint == "" {
+		return sniffLanguage(code)
+	}
+
+	base := path.Base(hint)
+
+	if lang, ok := fileLanguages[base]; ok {
+		return lang
+	}
+
+	if ext := path.Ext(base); ext != "" {
+		if lang, ok := extLanguages[strings.ToLower(ext)]; ok {
+			return lang
+		}
+
+		return sniffLanguage(code)
+	}
+
+	return hint
+}
+
+func sniffLanguage(code string) string {
+	s := strings.TrimSpace(code)
+
+	if strings.HasPrefix(s, "#!") {
+		shebang, _, _ := strings.Cut(s, "\n")
+
+		switch {
+		case strings.Contains(shebang, "python"):
+			return "python"
+		case strings.Contains(shebang, "sh"):
+			return "bash"
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(s, "package ") || strings.HasPrefix(s, "// Package "):
+		return "go"
+	case strings.HasPrefix(s, "<?xml"):
+		return "xml"
+	case strings.HasPrefix(strings.ToLower(s), "<!doctype html"), strings.HasPrefix(s, "<html"):
+		return "html"
+	case (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) && json.Valid([]byte(s)):
+		return "json"
+	case strings.HasPrefix(s, "---\n"):
+		return "yaml"
+	}
+
+	return ""
+}
+
+func longestRun(s string, r rune) int {
+	longest, current := 0, 0
+
+	for _, c := range s {
+		if c != r {
+			current = 0
+			continue
+		}
+
+		current++
+		longest = max(longest, current)
+	}
+
+	return longest
+}
@@ -0,0 +1,116 @@
+package prompt_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tpl  string
+		data map[string]any
+		want string
+	}{
+		{
+			name: "json",
+			tpl:  `{{json .V}}`,
+			data: map[string]any{"V": map[string]any{"a": 1}},
+			want: "{\n  \"a\": 1\n}",
+		},
+		{
+			name: "indent",
+			tpl:  `{{indent 2 .V}}`,
+			data: map[string]any{"V": "a\n\nb"},
+			want: "  a\n\n  b",
+		},
+		{
+			name: "numberLines",
+			tpl:  `{{numberLines .V}}`,
+			data: map[string]any{"V": "a\nb"},
+			want: "1:a\n2:b\n",
+		},
+		{
+			name: "truncateTokens short",
+			tpl:  `{{truncateTokens 10 .V}}`,
+			data: map[string]any{"V": "short text"},
+			want: "short text",
+		},
+		{
+			name: "truncateTokens at word boundary",
+			tpl:  `{{truncateTokens 3 .V}}`,
+			data: map[string]any{"V": "one two three four five"},
+			want: "one two…",
+		},
+		{
+			name: "join strings",
+			tpl:  `{{join ", " .V}}`,
+			data: map[string]any{"V": []string{"a", "b"}},
+			want: "a, b",
+		},
+		{
+			name: "join pipeline",
+			tpl:  `{{.V | join "+"}}`,
+			data: map[string]any{"V": []int{1, 2, 3}},
+			want: "1+2+3",
+		},
+		{
+			name: "codeFence language",
+			tpl:  `{{codeFence "sql" .V}}`,
+			data: map[string]any{"V": "SELECT 1;\n"},
+			want: "```sql\nSELECT 1;\n```",
+		},
+		{
+			name: "codeFence file name",
+			tpl:  `{{codeFence "cmd/main.go" .V}}`,
+			data: map[string]any{"V": "func main() {}"},
+			want: "```go\nfunc main() {}\n```",
+		},
+		{
+			name: "codeFence detected",
+			tpl:  `{{codeFence "" .V}}`,
+			data: map[string]any{"V": "#!/usr/bin/env python3\nprint(1)"},
+			want: "```python\n#!/usr/bin/env python3\nprint(1)\n```",
+		},
+		{
+			name: "codeFence nested fence",
+			tpl:  `{{codeFence "README.md" .V}}`,
+			data: map[string]any{"V": "```go\n```"},
+			want: "````markdown\n```go\n```\n````",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prompts, err := prompt.Load(fstest.MapFS{"p.tpl": {Data: []byte(tt.tpl)}}, "*.tpl")
+			require.NoError(t, err)
+
+			p := prompts["p"]
+
+			got, err := p.Execute(tt.data)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFuncs_JoinNotSlice(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := prompt.Load(fstest.MapFS{"p.tpl": {Data: []byte(`{{join "," .V}}`)}}, "*.tpl")
+	require.NoError(t, err)
+
+	p := prompts["p"]
+
+	_, err = p.Execute(map[string]any{"V": 1})
+	require.ErrorIs(t, err, prompt.ErrNotSlice)
+}
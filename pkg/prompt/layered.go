@@ -0,0 +1,217 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// BuiltinLayer is the name of the layer with the embedded templates.
+	BuiltinLayer = "builtin"
+	// UserLayer is the name of the layer with the templates from the user config directory.
+	UserLayer = "user"
+	// ProjectLayer is the name of the layer with the project-local templates.
+	ProjectLayer = "project"
+
+	// ProjectDir is the directory of the project-local templates relative to the project root.
+	ProjectDir = ".coder/prompts"
+)
+
+// Layer is a named source of templates.
+type Layer struct {
+	Name string
+	// Root is a location of the layer for humans. It's empty for embedded layers.
+	Root string
+	FS   fs.FS
+}
+
+// LayeredFS is a read-only union of layers. A file of an upper layer overrides
+// the file with the same path from lower layers, directory listings are merged.
+type LayeredFS struct {
+	// layers are ordered from the lowest to the uppermost one.
+	layers []Layer
+}
+
+var (
+	_ fs.ReadDirFS  = (*LayeredFS)(nil)
+	_ fs.ReadFileFS = (*LayeredFS)(nil)
+)
+
+// NewLayeredFS returns the union of layers ordered from the lowest to the uppermost one.
+func NewLayeredFS(layers ...Layer) *LayeredFS {
+	return &LayeredFS{layers: slices.Clone(layers)}
+}
+
+// DefaultLayers stacks the builtin templates, the templates from the user config
+// directory ("coder/prompts" inside [os.UserConfigDir]) and the templates from
+// the [ProjectDir] of the project. Missing directories are skipped.
+func DefaultLayers(builtin fs.FS, projectRoot string) (*LayeredFS, error) {
+	layers := []Layer{{Name: BuiltinLayer, Root: "", FS: builtin}}
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		layers = appendDirLayer(layers, UserLayer, filepath.Join(dir, "coder", "prompts"))
+	}
+
+	projectDir, err := filepath.Abs(filepath.Join(projectRoot, filepath.FromSlash(ProjectDir)))
+	if err != nil {
+		return nil, fmt.Errorf("resolve project prompts directory: %w", err)
+	}
+
+	layers = appendDirLayer(layers, ProjectLayer, projectDir)
+
+	return NewLayeredFS(layers...), nil
+}
+
+func appendDirLayer(layers []Layer, name, dir string) []Layer {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return layers
+	}
+
+	return append(layers, Layer{Name: name, Root: dir, FS: os.DirFS(dir)})
+}
+
+// Layers returns the layers ordered from the lowest to the uppermost one.
+func (l *LayeredFS) Layers() []Layer {
+	return slices.Clone(l.layers)
+}
+
+// Origins returns the layers containing the file ordered from the active
+// (uppermost) one to the lowest.
+func (l *LayeredFS) Origins(name string) []Layer {
+	var origins []Layer
+
+	for _, layer := range slices.Backward(l.layers) {
+		if info, err := fs.Stat(layer.FS, name); err == nil && !info.IsDir() {
+			origins = append(origins, layer)
+		}
+	}
+
+	return origins
+}
+
+// Open opens the file from the uppermost layer containing it.
+// Directories list the merged entries of all layers.
+func (l *LayeredFS) Open(name string) (fs.File, error) {
+	for _, layer := range slices.Backward(l.layers) {
+		f, err := layer.FS.Open(name)
+		if err == nil {
+			return l.wrapDir(name, f)
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("open %q of %s layer: %w", name, layer.Name, err)
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile reads the file from the uppermost layer containing it.
+func (l *LayeredFS) ReadFile(name string) ([]byte, error) {
+	for _, layer := range slices.Backward(l.layers) {
+		b, err := fs.ReadFile(layer.FS, name)
+		if err == nil {
+			return b, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("read %q of %s layer: %w", name, layer.Name, err)
+		}
+	}
+
+	return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir merges the directory entries of all layers. An entry of an upper
+// layer replaces the entry with the same name from lower layers.
+func (l *LayeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := map[string]fs.DirEntry{}
+	found := false
+
+	for _, layer := range l.layers {
+		des, err := fs.ReadDir(layer.FS, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read dir %q of %s layer: %w", name, layer.Name, err)
+		}
+
+		found = true
+
+		for _, de := range des {
+			entries[de.Name()] = de
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	merged := make([]fs.DirEntry, 0, len(entries))
+
+	for _, de := range entries {
+		merged = append(merged, de)
+	}
+
+	slices.SortFunc(merged, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return merged, nil
+}
+
+func (l *LayeredFS) wrapDir(name string, f fs.File) (fs.File, error) {
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return nil, fmt.Errorf("stat %q: %w", name, err)
+	}
+
+	if !info.IsDir() {
+		return f, nil
+	}
+
+	entries, err := l.ReadDir(name)
+	if err != nil {
+		f.Close()
+
+		return nil, err
+	}
+
+	return &layeredDir{File: f, entries: entries}, nil
+}
+
+// layeredDir is a directory of the uppermost layer listing the merged entries.
+type layeredDir struct {
+	fs.File
+
+	entries []fs.DirEntry
+}
+
+func (d *layeredDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+
+		return entries, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+
+	return entries, nil
+}
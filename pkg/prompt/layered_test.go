@@ -0,0 +1,115 @@
+package prompt_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLayers() *prompt.LayeredFS {
+	return prompt.NewLayeredFS(
+		prompt.Layer{Name: prompt.BuiltinLayer, FS: fstest.MapFS{
+			"greeting.tpl":           {Data: []byte(`Hello, {{template "name" .}}!`)},
+			"farewell.tpl":           {Data: []byte(`Goodbye, {{template "name" .}}!`)},
+			"_partials/name.tpl":     {Data: []byte(`{{.Name}}`)},
+			"_partials/unused.tpl":   {Data: []byte(`unused`)},
+			"analyze_task_schema.js": {Data: []byte(`{}`)},
+		}},
+		prompt.Layer{Name: prompt.UserLayer, Root: "/home/user/.config/coder/prompts", FS: fstest.MapFS{
+			"greeting.tpl": {Data: []byte(`Hi, {{template "name" .}}!`)},
+		}},
+		prompt.Layer{Name: prompt.ProjectLayer, Root: "/project/.coder/prompts", FS: fstest.MapFS{
+			"_partials/name.tpl": {Data: []byte(`dear {{.Name}}`)},
+		}},
+	)
+}
+
+func TestLayeredFS_Load(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := prompt.Load(newLayers(), "*.tpl")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "greeting", want: "Hi, dear Alice!"},
+		{name: "farewell", want: "Goodbye, dear Alice!"},
+	}
+
+	for _, tt := range tests {
+		p, ok := prompts[tt.name]
+		require.True(t, ok, tt.name)
+
+		got, err := p.Execute(map[string]any{"Name": "Alice"})
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestLayeredFS_Origins(t *testing.T) {
+	t.Parallel()
+
+	layers := newLayers()
+
+	names := func(layers []prompt.Layer) []string {
+		var names []string
+
+		for _, l := range layers {
+			names = append(names, l.Name)
+		}
+
+		return names
+	}
+
+	assert.Equal(t, []string{prompt.UserLayer, prompt.BuiltinLayer}, names(layers.Origins("greeting.tpl")))
+	assert.Equal(t, []string{prompt.ProjectLayer, prompt.BuiltinLayer}, names(layers.Origins("_partials/name.tpl")))
+	assert.Equal(t, []string{prompt.BuiltinLayer}, names(layers.Origins("farewell.tpl")))
+	assert.Empty(t, layers.Origins("missing.tpl"))
+	assert.Empty(t, layers.Origins("_partials"), "directories are not templates")
+}
+
+func TestLayeredFS_ReadDir(t *testing.T) {
+	t.Parallel()
+
+	matches, err := fs.Glob(newLayers(), "_partials/*.tpl")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"_partials/name.tpl", "_partials/unused.tpl"}, matches)
+
+	_, err = fs.ReadDir(newLayers(), "missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, fstest.TestFS(newLayers(), "greeting.tpl", "_partials/name.tpl"))
+}
+
+func TestDefaultLayers(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, filepath.FromSlash(prompt.ProjectDir))
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greeting.tpl"), []byte("Project"), 0o600))
+
+	layers, err := prompt.DefaultLayers(fstest.MapFS{"greeting.tpl": {Data: []byte("Builtin")}}, root)
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(layers, "greeting.tpl")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Project", string(b))
+
+	origins := layers.Origins("greeting.tpl")
+	require.NotEmpty(t, origins)
+	assert.Equal(t, prompt.ProjectLayer, origins[0].Name)
+	assert.Equal(t, dir, origins[0].Root)
+}
@@ -1,13 +1,30 @@
 package prompt
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
-	"path/filepath"
+	"path"
+	"reflect"
 	"strings"
 	"text/template"
 )
 
+// PartialsDir is the name of a directory with shared templates. It's looked up
+// next to every pattern passed to [Load].
+const PartialsDir = "_partials"
+
+var (
+	ErrNameCollision      = errors.New("name collision")
+	ErrNoFiles            = errors.New("pattern matches no files")
+	ErrNotSlice           = errors.New("not a slice")
+	ErrChatPrompt         = errors.New("prompt consists of chat messages")
+	ErrTextOutsideMessage = errors.New("text outside of messages")
+	ErrUnknownField       = errors.New("unknown field")
+	ErrUnknownTemplate    = errors.New("unknown template")
+	ErrPromptNotFound     = errors.New("prompt not found")
+)
+
 type Prompt struct {
 	tpl *template.Template
 }
@@ -16,7 +33,33 @@ func (p *Prompt) Name() string {
 	return p.tpl.Name()
 }
 
-func (p *Prompt) Execute(data map[string]any) (string, error) {
+// Execute executes the template into a single text. Chat templates can't be
+// executed as a text, use [Prompt.Messages] for them.
+func (p *Prompt) Execute(data any) (string, error) {
+	text, mark, err := p.executeMarked(data)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(text, mark) {
+		return "", fmt.Errorf("template %q: %w", p.tpl.Name(), ErrChatPrompt)
+	}
+
+	return text, nil
+}
+
+// Validate checks that every field and method referenced by the template exists
+// on the type of data. Only the type of data is used, so it can be a zero value.
+// Values of interface types and maps can't be checked and are skipped.
+func (p *Prompt) Validate(data any) error {
+	if err := checkFields(p.tpl, reflect.TypeOf(data)); err != nil {
+		return fmt.Errorf("validate template %q: %w", p.tpl.Name(), err)
+	}
+
+	return nil
+}
+
+func (p *Prompt) execute(data any) (string, error) {
 	sb := strings.Builder{}
 
 	if err := p.tpl.Option("missingkey=error").Execute(&sb, data); err != nil {
@@ -26,20 +69,179 @@ func (p *Prompt) Execute(data map[string]any) (string, error) {
 	return sb.String(), nil
 }
 
+// Load parses the files matched by the patterns into independent prompts
+// keyed by the file names without extensions.
+//
+// Files from the [PartialsDir] next to a pattern that match the pattern's base
+// are partials: they are shared by all prompts and are invoked by the file name
+// without extension, e.g. {{template "task" .}} for "_partials/task.tpl".
+// Templates defined inside partials with {{define}} are shared too.
+// A partial must not have the same name as a prompt or a template defined
+// by a prompt, otherwise [ErrNameCollision] is returned.
+//
+// All templates have access to the helper functions, see [Funcs].
 func Load(dir fs.FS, patterns ...string) (map[string]Prompt, error) {
-	tpl, err := template.ParseFS(dir, patterns...)
+	files, partialFiles, err := glob(dir, patterns)
 	if err != nil {
-		return nil, fmt.Errorf("parse fs templates: %w", err)
+		return nil, err
+	}
+
+	root, partials, err := parsePartials(dir, partialFiles)
+	if err != nil {
+		return nil, err
 	}
 
 	prompts := map[string]Prompt{}
+	sources := map[string]string{}
 
-	for _, t := range tpl.Templates() {
-		ext := filepath.Ext(t.Name())
-		name := strings.TrimSuffix(t.Name(), ext)
+	for _, file := range files {
+		name := trimExt(path.Base(file))
 
-		prompts[name] = Prompt{tpl: t}
+		if partial, ok := partials[name]; ok {
+			return nil, fmt.Errorf("%w: prompt %q is shadowed by partial %q", ErrNameCollision, file, partial)
+		}
+
+		if other, ok := sources[name]; ok {
+			return nil, fmt.Errorf("%w: prompts %q and %q", ErrNameCollision, other, file)
+		}
+
+		tpl, err := parsePrompt(dir, root, partials, file)
+		if err != nil {
+			return nil, err
+		}
+
+		prompts[name] = Prompt{tpl: tpl}
+		sources[name] = file
 	}
 
 	return prompts, nil
 }
+
+func glob(dir fs.FS, patterns []string) (files, partials []string, err error) {
+	seen := map[string]bool{}
+
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(dir, pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("glob %q: %w", pattern, err)
+		}
+
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("parse fs templates: %w: %q", ErrNoFiles, pattern)
+		}
+
+		for _, m := range matches {
+			if path.Base(m) == PartialsDir || seen[m] {
+				continue
+			}
+
+			seen[m] = true
+			files = append(files, m)
+		}
+
+		partialPattern := path.Join(path.Dir(pattern), PartialsDir, path.Base(pattern))
+
+		matches, err = fs.Glob(dir, partialPattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("glob %q: %w", partialPattern, err)
+		}
+
+		for _, m := range matches {
+			if seen[m] {
+				continue
+			}
+
+			seen[m] = true
+			partials = append(partials, m)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("parse fs templates: %w: %q", ErrNoFiles, patterns)
+	}
+
+	return files, partials, nil
+}
+
+// parsePartials parses the partial files into a single template set.
+// It returns the set and the names of all defined templates mapped
+// to their files.
+func parsePartials(dir fs.FS, files []string) (*template.Template, map[string]string, error) {
+	root := template.New("").Funcs(Funcs())
+	defined := map[string]string{}
+
+	for _, file := range files {
+		tpl, err := parseFile(dir, file, trimExt(path.Base(file)))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, t := range tpl.Templates() {
+			if other, ok := defined[t.Name()]; ok {
+				return nil, nil, fmt.Errorf("%w: partial %q is defined by %q and %q",
+					ErrNameCollision, t.Name(), other, file)
+			}
+
+			defined[t.Name()] = file
+
+			if t.Tree == nil {
+				continue
+			}
+
+			if _, err := root.AddParseTree(t.Name(), t.Tree); err != nil {
+				return nil, nil, fmt.Errorf("add partial %q: %w", t.Name(), err)
+			}
+		}
+	}
+
+	return root, defined, nil
+}
+
+func parsePrompt(dir fs.FS, root *template.Template, partials map[string]string, file string) (*template.Template, error) {
+	tpl, err := parseFile(dir, file, path.Base(file))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tpl.Templates() {
+		if partial, ok := partials[t.Name()]; ok {
+			return nil, fmt.Errorf("%w: template %q of prompt %q is shadowed by partial %q",
+				ErrNameCollision, t.Name(), file, partial)
+		}
+	}
+
+	set, err := root.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("clone partials: %w", err)
+	}
+
+	for _, t := range tpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+
+		if _, err := set.AddParseTree(t.Name(), t.Tree); err != nil {
+			return nil, fmt.Errorf("add template %q: %w", t.Name(), err)
+		}
+	}
+
+	return set.Lookup(tpl.Name()), nil
+}
+
+func parseFile(dir fs.FS, file, name string) (*template.Template, error) {
+	b, err := fs.ReadFile(dir, file)
+	if err != nil {
+		return nil, fmt.Errorf("read template %q: %w", file, err)
+	}
+
+	tpl, err := template.New(name).Funcs(Funcs()).Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", file, err)
+	}
+
+	return tpl, nil
+}
+
+func trimExt(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
+}
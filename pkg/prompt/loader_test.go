@@ -4,6 +4,7 @@ import (
 	"embed"
 	"io/fs"
 	"testing"
+	"testing/fstest"
 
 	"github.com/WinPooh32/go-coder/pkg/prompt"
 	"github.com/stretchr/testify/assert"
@@ -85,3 +86,82 @@ func TestLoad_NoTemplatesFound(t *testing.T) {
 
 	assert.Empty(t, prompts, "should not contain any templates")
 }
+
+//go:embed all:testdata/partials
+var partialsFS embed.FS
+
+func TestLoad_Partials(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := prompt.Load(partialsFS, "testdata/partials/*.tpl")
+	require.NoError(t, err)
+
+	assert.Len(t, prompts, 1, "partials must not be loaded as prompts")
+
+	report, ok := prompts["report"]
+	require.True(t, ok)
+
+	result, err := report.Execute(map[string]any{
+		"Title": "Release",
+		"Items": []string{"build", "deploy"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Report for \"Release\":\n- build\n- deploy\n", result)
+}
+
+func TestLoad_NameCollision(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		fsys     fstest.MapFS
+	}{
+		{
+			name: "prompt and partial",
+			fsys: fstest.MapFS{
+				"p/task.tpl":           {Data: []byte("prompt")},
+				"p/_partials/task.tpl": {Data: []byte("partial")},
+			},
+		},
+		{
+			name: "prompt defines partial",
+			fsys: fstest.MapFS{
+				"p/report.tpl":         {Data: []byte(`{{define "task"}}local{{end}}{{template "task"}}`)},
+				"p/_partials/task.tpl": {Data: []byte("partial")},
+			},
+		},
+		{
+			name: "partials define the same template",
+			fsys: fstest.MapFS{
+				"p/report.tpl":          {Data: []byte("report")},
+				"p/_partials/a.tpl":     {Data: []byte(`{{define "x"}}a{{end}}`)},
+				"p/_partials/b.tpl":     {Data: []byte(`{{define "x"}}b{{end}}`)},
+				"p/_partials/other.tpl": {Data: []byte("other")},
+			},
+		},
+		{
+			name:     "prompts from different directories",
+			patterns: []string{"a/*.tpl", "b/*.tpl"},
+			fsys: fstest.MapFS{
+				"a/report.tpl": {Data: []byte("a")},
+				"b/report.tpl": {Data: []byte("b")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			patterns := tt.patterns
+			if patterns == nil {
+				patterns = []string{"p/*.tpl"}
+			}
+
+			_, err := prompt.Load(tt.fsys, patterns...)
+			require.ErrorIs(t, err, prompt.ErrNameCollision)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Package prompttest checks that [prompt.Prompt] templates render with sample data.
+package prompttest
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RenderAll validates every prompt against the type of its sample data and
+// renders it to messages, every message must have content. Prompts without
+// samples and samples without prompts fail the test, so new and renamed
+// templates can't be left unchecked.
+func RenderAll(t *testing.T, prompts map[string]prompt.Prompt, samples map[string]any) {
+	t.Helper()
+
+	for _, name := range slices.Sorted(maps.Keys(samples)) {
+		if _, ok := prompts[name]; !ok {
+			t.Errorf("sample %q has no prompt", name)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(prompts)) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sample, ok := samples[name]
+			require.True(t, ok, "prompt %q has no sample data", name)
+
+			p := prompts[name]
+
+			require.NoError(t, p.Validate(sample))
+
+			msgs, err := p.Messages(sample)
+			require.NoError(t, err)
+			require.NotEmpty(t, msgs)
+
+			for i, msg := range msgs {
+				assert.NotEmpty(t, msg.Content, "message %d (%s) is empty", i, msg.Role)
+			}
+		})
+	}
+}
@@ -0,0 +1 @@
+{{define "bullet"}}- {{.}}{{end}}
\ No newline at end of file
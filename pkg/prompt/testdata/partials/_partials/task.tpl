@@ -0,0 +1 @@
+"{{.Title}}"
\ No newline at end of file
@@ -0,0 +1,3 @@
+Report for {{template "task" .}}:
+{{range .Items}}{{template "bullet" .}}
+{{end}}
\ No newline at end of file
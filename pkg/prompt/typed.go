@@ -0,0 +1,49 @@
+package prompt
+
+import (
+	"fmt"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+// Typed is a prompt executed with data of the type T. Its template is
+// validated against T when the prompt is created, so typos in field names
+// are found at load time instead of execution.
+type Typed[T any] struct {
+	prompt Prompt
+}
+
+// NewTyped validates the prompt against the type T, see [Prompt.Validate].
+func NewTyped[T any](p Prompt) (Typed[T], error) {
+	var zero T
+
+	if err := p.Validate(zero); err != nil {
+		return Typed[T]{}, err
+	}
+
+	return Typed[T]{prompt: p}, nil
+}
+
+// Lookup returns the prompt of the loaded ones validated against the type T.
+func Lookup[T any](prompts map[string]Prompt, name string) (Typed[T], error) {
+	p, ok := prompts[name]
+	if !ok {
+		return Typed[T]{}, fmt.Errorf("%w: %q", ErrPromptNotFound, name)
+	}
+
+	return NewTyped[T](p)
+}
+
+func (t Typed[T]) Name() string {
+	return t.prompt.Name()
+}
+
+// Execute executes the template into a single text, see [Prompt.Execute].
+func (t Typed[T]) Execute(data T) (string, error) {
+	return t.prompt.Execute(data)
+}
+
+// Messages executes the template into chat messages, see [Prompt.Messages].
+func (t Typed[T]) Messages(data T) ([]llm.Message, error) {
+	return t.prompt.Messages(data)
+}
@@ -0,0 +1,108 @@
+package prompt_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name string
+	Tags []string
+}
+
+type report struct {
+	Title   string
+	Items   []item
+	Owner   *item
+	Created time.Time
+	Extra   map[string]any
+	Any     any
+}
+
+func TestNewTyped(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tpl     string
+		wantErr bool
+	}{
+		{name: "field", tpl: `{{.Title}}`},
+		{name: "typo", tpl: `{{.Tittle}}`, wantErr: true},
+		{name: "method", tpl: `{{.Created.Format "2006"}}`},
+		{name: "unknown method", tpl: `{{.Created.Formatt "2006"}}`, wantErr: true},
+		{name: "range", tpl: `{{range .Items}}{{.Name}}{{range .Tags}}{{.}}{{end}}{{end}}`},
+		{name: "range typo", tpl: `{{range .Items}}{{.Title}}{{end}}`, wantErr: true},
+		{name: "range variables", tpl: `{{range $i, $it := .Items}}{{$i}}{{$it.Name}}{{$.Title}}{{end}}`},
+		{name: "range variable typo", tpl: `{{range $it := .Items}}{{$it.Nam}}{{end}}`, wantErr: true},
+		{name: "else of range", tpl: `{{range .Items}}{{.Name}}{{else}}{{.Title}}{{end}}`},
+		{name: "with pointer", tpl: `{{with .Owner}}{{.Name}}{{end}}`},
+		{name: "with typo", tpl: `{{with .Owner}}{{.Title}}{{end}}`, wantErr: true},
+		{name: "variable", tpl: `{{$o := .Owner}}{{$o.Tags}}`},
+		{name: "root variable typo", tpl: `{{with .Owner}}{{$.Name}}{{end}}`, wantErr: true},
+		{name: "function arguments", tpl: `{{join ", " .Items}}{{if eq .Title "x"}}{{end}}`},
+		{name: "function argument typo", tpl: `{{printf "%s" .Titel}}`, wantErr: true},
+		{name: "parenthesized", tpl: `{{(index .Items 0).Name}}`},
+		{name: "map and interface", tpl: `{{.Extra.anything.deep}}{{.Any.Whatever}}`},
+		{name: "partial", tpl: `{{template "owner" .Owner}}`},
+		{name: "partial typo", tpl: `{{template "title" .Owner}}`, wantErr: true},
+		{name: "unknown template", tpl: `{{template "missing" .}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prompts, err := prompt.Load(fstest.MapFS{
+				"p.tpl":               {Data: []byte(tt.tpl)},
+				"_partials/owner.tpl": {Data: []byte(`{{.Name}}`)},
+				"_partials/title.tpl": {Data: []byte(`{{.Title}}`)},
+			}, "*.tpl")
+			require.NoError(t, err)
+
+			_, err = prompt.NewTyped[report](prompts["p"])
+			if !tt.wantErr {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNewTyped_ErrorPosition(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := prompt.Load(fstest.MapFS{"p.tpl": {Data: []byte("Title:\n{{.Tittle}}")}}, "*.tpl")
+	require.NoError(t, err)
+
+	_, err = prompt.NewTyped[*report](prompts["p"])
+	require.ErrorIs(t, err, prompt.ErrUnknownField)
+	assert.ErrorContains(t, err, `p.tpl:2:2`)
+	assert.ErrorContains(t, err, `"Tittle"`)
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := prompt.Load(fstest.MapFS{"p.tpl": {Data: []byte(`{{.Title}}: {{len .Items}}`)}}, "*.tpl")
+	require.NoError(t, err)
+
+	p, err := prompt.Lookup[report](prompts, "p")
+	require.NoError(t, err)
+
+	got, err := p.Execute(report{Title: "Report", Items: []item{{Name: "a"}}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Report: 1", got)
+
+	_, err = prompt.Lookup[report](prompts, "missing")
+	require.ErrorIs(t, err, prompt.ErrPromptNotFound)
+}
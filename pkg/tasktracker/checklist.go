@@ -0,0 +1,227 @@
+package tasktracker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const tabWidth = 4
+
+var (
+	checklistItemRe = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\] (.*)$`)
+	checklistIDRe   = regexp.MustCompile(`\s*<!--\s*id:\s*(\S+)\s*-->\s*$`)
+)
+
+type checklistItem struct {
+	task   Task
+	indent int
+	desc   []string
+}
+
+// ParseChecklist parses tasks from a Markdown checklist.
+//
+// Every checkbox item is a task: the item text is the title, indented lines
+// below it are the description and "[x]" marks the task as done.
+// The ID is kept in the trailing HTML comment "<!-- id: ID -->". Items without it
+// get IDs by their position: "1", "2" for top level items and "1.1", "1.2"
+// for items nested into the item "1". An empty description is replaced by the title.
+func ParseChecklist(r io.Reader) ([]Task, error) {
+	var (
+		items []*checklistItem
+		stack []*checklistItem
+		count = map[string]int{}
+	)
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		m := checklistItemRe.FindStringSubmatch(line)
+		if m == nil {
+			appendDescription(stack, line)
+
+			continue
+		}
+
+		indent := indentWidth(m[1])
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		var parentID string
+		if len(stack) > 0 {
+			parentID = stack[len(stack)-1].task.ID
+		}
+
+		item := &checklistItem{
+			task:   parseChecklistTask(m[3], m[2] != " ", parentID, count),
+			indent: indent,
+			desc:   nil,
+		}
+
+		items = append(items, item)
+		stack = append(stack, item)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan checklist: %w", err)
+	}
+
+	tasks := make([]Task, 0, len(items))
+
+	for _, item := range items {
+		item.task.Description = strings.Trim(strings.Join(item.desc, "\n"), "\n")
+		if item.task.Description == "" {
+			item.task.Description = item.task.Title
+		}
+
+		tasks = append(tasks, item.task)
+	}
+
+	return tasks, nil
+}
+
+func parseChecklistTask(text string, done bool, parentID string, count map[string]int) Task {
+	count[parentID]++
+
+	id := strconv.Itoa(count[parentID])
+	if parentID != "" {
+		id = parentID + "." + id
+	}
+
+	if m := checklistIDRe.FindStringSubmatch(text); m != nil {
+		id = m[1]
+		text = text[:len(text)-len(m[0])]
+	}
+
+	return Task{
+		ID:          id,
+		Title:       strings.TrimSpace(text),
+		Description: "",
+		Done:        done,
+	}
+}
+
+// appendDescription appends the line to the description of the innermost item it is indented into.
+func appendDescription(stack []*checklistItem, line string) {
+	if strings.TrimSpace(line) == "" {
+		if len(stack) > 0 {
+			item := stack[len(stack)-1]
+			item.desc = append(item.desc, "")
+		}
+
+		return
+	}
+
+	indent := indentWidth(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		item := stack[i]
+		if indent > item.indent {
+			item.desc = append(item.desc, dedent(line, item.indent+2)) //nolint:mnd // Width of "- ".
+
+			return
+		}
+	}
+}
+
+// FormatChecklist writes tasks as a Markdown checklist readable by [ParseChecklist].
+// Tasks with IDs like "1.2" are nested into the task "1" if it is present.
+func FormatChecklist(w io.Writer, tasks []Task) error {
+	ids := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		ids[t.ID] = true
+	}
+
+	children := map[string][]Task{}
+
+	for _, t := range tasks {
+		parent := checklistParent(t.ID, ids)
+		children[parent] = append(children[parent], t)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	writeChecklist(bw, children, "", 0)
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write checklist: %w", err)
+	}
+
+	return nil
+}
+
+func writeChecklist(w *bufio.Writer, children map[string][]Task, parent string, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	for _, t := range children[parent] {
+		mark := " "
+		if t.Done {
+			mark = "x"
+		}
+
+		fmt.Fprintf(w, "%s- [%s] %s <!-- id: %s -->\n", indent, mark, t.Title, t.ID)
+
+		if t.Description != t.Title {
+			for _, line := range strings.Split(t.Description, "\n") {
+				if line == "" {
+					w.WriteString("\n")
+				} else {
+					fmt.Fprintf(w, "%s  %s\n", indent, line)
+				}
+			}
+		}
+
+		writeChecklist(w, children, t.ID, depth+1)
+	}
+}
+
+// checklistParent returns the ID of the closest present task whose ID prefixes the id.
+func checklistParent(id string, ids map[string]bool) string {
+	for i := strings.LastIndex(id, "."); i > 0; i = strings.LastIndex(id[:i], ".") {
+		if ids[id[:i]] {
+			return id[:i]
+		}
+	}
+
+	return ""
+}
+
+func indentWidth(s string) int {
+	var width int
+
+	for _, r := range s {
+		if r == '\t' {
+			width += tabWidth
+		} else {
+			width++
+		}
+	}
+
+	return width
+}
+
+// dedent removes up to n columns of leading whitespace.
+func dedent(line string, n int) string {
+	var width int
+
+	for i, r := range line {
+		if width >= n || (r != ' ' && r != '\t') {
+			return line[i:]
+		}
+
+		if r == '\t' {
+			width += tabWidth
+		} else {
+			width++
+		}
+	}
+
+	return ""
+}
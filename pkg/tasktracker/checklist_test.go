@@ -0,0 +1,86 @@
+package tasktracker_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecklist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []tasktracker.Task
+	}{
+		{"empty", "", []tasktracker.Task{}},
+		{
+			"flat",
+			"# Backlog\n\n- [ ] First\n- [x] Second\n* [X] Third\n",
+			[]tasktracker.Task{
+				{ID: "1", Title: "First", Description: "First", Done: false},
+				{ID: "2", Title: "Second", Description: "Second", Done: true},
+				{ID: "3", Title: "Third", Description: "Third", Done: true},
+			},
+		},
+		{
+			"nested with descriptions",
+			"- [ ] Parent\n  Parent description.\n\n  More details.\n  - [x] Child\n    Child description.\n" +
+				"  - [ ] Second child\n- [ ] Next\n",
+			[]tasktracker.Task{
+				{ID: "1", Title: "Parent", Description: "Parent description.\n\nMore details.", Done: false},
+				{ID: "1.1", Title: "Child", Description: "Child description.", Done: true},
+				{ID: "1.2", Title: "Second child", Description: "Second child", Done: false},
+				{ID: "2", Title: "Next", Description: "Next", Done: false},
+			},
+		},
+		{
+			"explicit ids",
+			"- [ ] Parent <!-- id: parent -->\n\t- [ ] Child\n\t- [ ] Named <!--id: named-->\n",
+			[]tasktracker.Task{
+				{ID: "parent", Title: "Parent", Description: "Parent", Done: false},
+				{ID: "parent.1", Title: "Child", Description: "Child", Done: false},
+				{ID: "named", Title: "Named", Description: "Named", Done: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tasktracker.ParseChecklist(strings.NewReader(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatChecklist(t *testing.T) {
+	t.Parallel()
+
+	tasks := []tasktracker.Task{
+		{ID: "1", Title: "Parent", Description: "Parent description.\n\nMore details.", Done: false},
+		{ID: "1.1", Title: "Child", Description: "Child description.", Done: true},
+		{ID: "2", Title: "Next", Description: "Next", Done: false},
+		{ID: "2.5.1", Title: "Grandchild", Description: "Grandchild", Done: false},
+	}
+
+	var sb strings.Builder
+
+	require.NoError(t, tasktracker.FormatChecklist(&sb, tasks))
+
+	want := "- [ ] Parent <!-- id: 1 -->\n  Parent description.\n\n  More details.\n" +
+		"  - [x] Child <!-- id: 1.1 -->\n    Child description.\n" +
+		"- [ ] Next <!-- id: 2 -->\n" +
+		"  - [ ] Grandchild <!-- id: 2.5.1 -->\n"
+	assert.Equal(t, want, sb.String())
+
+	got, err := tasktracker.ParseChecklist(strings.NewReader(sb.String()))
+	require.NoError(t, err)
+	assert.Equal(t, tasks, got, "should round-trip")
+}
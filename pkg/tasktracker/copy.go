@@ -0,0 +1,68 @@
+package tasktracker
+
+import (
+	"context"
+	"fmt"
+)
+
+// Copy copies all tasks and their comments from src to dst preserving task IDs.
+// Comments are appended, so dst is expected to have no comments for the copied tasks.
+// Subtask relations are copied if both trackers implement [SubtaskTracker].
+// It returns the number of copied tasks.
+func Copy(ctx context.Context, dst, src Tracker) (int, error) {
+	tasks, err := src.List(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("list source tasks: %w", err)
+	}
+
+	for i, task := range tasks {
+		if err := dst.Set(ctx, task.ID, task); err != nil {
+			return i, fmt.Errorf("set task %q: %w", task.ID, err)
+		}
+
+		comments, err := src.ListComments(ctx, task.ID)
+		if err != nil {
+			return i, fmt.Errorf("list comments of task %q: %w", task.ID, err)
+		}
+
+		for _, c := range comments {
+			if err := dst.AddComment(ctx, task.ID, c); err != nil {
+				return i, fmt.Errorf("add comment to task %q: %w", task.ID, err)
+			}
+		}
+	}
+
+	if err := copySubtasks(ctx, dst, src, tasks); err != nil {
+		return len(tasks), err
+	}
+
+	return len(tasks), nil
+}
+
+// copySubtasks copies relations of the tasks after all of them are present in dst.
+func copySubtasks(ctx context.Context, dst, src Tracker, tasks []Task) error {
+	dstSub, ok := dst.(SubtaskTracker)
+	if !ok {
+		return nil
+	}
+
+	srcSub, ok := src.(SubtaskTracker)
+	if !ok {
+		return nil
+	}
+
+	for _, task := range tasks {
+		subtasks, err := srcSub.ListSubtasks(ctx, task.ID)
+		if err != nil {
+			return fmt.Errorf("list subtasks of task %q: %w", task.ID, err)
+		}
+
+		for _, sub := range subtasks {
+			if err := dstSub.AddSubtask(ctx, task.ID, sub.ID); err != nil {
+				return fmt.Errorf("add subtask %q to task %q: %w", sub.ID, task.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package tasktracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// labelsPrefix starts the last line of the task description listing labels of the imported issue.
+	labelsPrefix = "Labels: "
+
+	// taskIDPrefix and taskIDSuffix enclose the ID of a task without an issue number in the issue body.
+	// The HTML comment is hidden when GitHub renders the body.
+	taskIDPrefix = "<!-- task-id: "
+	taskIDSuffix = " -->"
+)
+
+type gitHubIssue struct {
+	Number int           `json:"number,omitempty"`
+	Title  string        `json:"title"`
+	Body   string        `json:"body"`
+	Labels []gitHubLabel `json:"labels"`
+	State  string        `json:"state"`
+}
+
+type gitHubLabel struct {
+	Name string `json:"name"`
+}
+
+// ParseGitHubIssues parses tasks from a JSON array of GitHub issues as exported by
+// "gh issue list --json number,title,body,labels,state" or returned by the REST API.
+//
+// The task ID is idPrefix followed by the issue number, closed issues are done.
+// Issues without a number take the original task ID kept by [FormatGitHubIssues] in the body.
+// Labels are kept in the last line of the description: "Labels: bug, ui".
+// An empty body is replaced by the title.
+func ParseGitHubIssues(r io.Reader, idPrefix string) ([]Task, error) {
+	var issues []gitHubIssue
+
+	if err := json.NewDecoder(r).Decode(&issues); err != nil {
+		return nil, fmt.Errorf("decode issues json: %w", err)
+	}
+
+	tasks := make([]Task, 0, len(issues))
+
+	for i, issue := range issues {
+		body, id := cutTaskID(issue.Body)
+
+		switch {
+		case issue.Number > 0:
+			id = idPrefix + strconv.Itoa(issue.Number)
+		case id == "":
+			return nil, fmt.Errorf("issue at index %d: invalid number %d", i, issue.Number)
+		}
+
+		desc := strings.TrimSpace(body)
+		if desc == "" {
+			desc = issue.Title
+		}
+
+		if len(issue.Labels) > 0 {
+			names := make([]string, len(issue.Labels))
+			for j, l := range issue.Labels {
+				names[j] = l.Name
+			}
+
+			desc += "\n\n" + labelsPrefix + strings.Join(names, ", ")
+		}
+
+		tasks = append(tasks, Task{
+			ID:          id,
+			Title:       issue.Title,
+			Description: desc,
+			Done:        strings.EqualFold(issue.State, "closed"),
+		})
+	}
+
+	return tasks, nil
+}
+
+// FormatGitHubIssues writes tasks as a JSON array of GitHub issues readable by [ParseGitHubIssues].
+// The issue number is taken from the task ID without idPrefix. If it isn't a number,
+// the number is omitted and the task ID is kept in a hidden comment at the end of the body.
+func FormatGitHubIssues(w io.Writer, tasks []Task, idPrefix string) error {
+	issues := make([]gitHubIssue, 0, len(tasks))
+
+	for _, t := range tasks {
+		body, labels := splitLabels(t.Description)
+		if body == t.Title {
+			body = ""
+		}
+
+		number, err := strconv.Atoi(strings.TrimPrefix(t.ID, idPrefix))
+		if err != nil || number <= 0 || !strings.HasPrefix(t.ID, idPrefix) {
+			number = 0
+			body = strings.TrimLeft(body+"\n\n", "\n") + taskIDPrefix + t.ID + taskIDSuffix
+		}
+
+		state := "open"
+		if t.Done {
+			state = "closed"
+		}
+
+		issues = append(issues, gitHubIssue{
+			Number: number,
+			Title:  t.Title,
+			Body:   body,
+			Labels: labels,
+			State:  state,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(issues); err != nil {
+		return fmt.Errorf("encode issues json: %w", err)
+	}
+
+	return nil
+}
+
+// cutTaskID cuts the task ID comment off the end of the issue body.
+func cutTaskID(body string) (string, string) {
+	trimmed := strings.TrimRight(body, " \t\r\n")
+
+	if !strings.HasSuffix(trimmed, taskIDSuffix) {
+		return body, ""
+	}
+
+	i := strings.LastIndex(trimmed, taskIDPrefix)
+	if i < 0 {
+		return body, ""
+	}
+
+	return trimmed[:i], trimmed[i+len(taskIDPrefix) : len(trimmed)-len(taskIDSuffix)]
+}
+
+// splitLabels cuts the labels line off the description.
+func splitLabels(desc string) (string, []gitHubLabel) {
+	labels := []gitHubLabel{}
+
+	i := strings.LastIndex(desc, "\n")
+	last := desc[i+1:]
+
+	if !strings.HasPrefix(last, labelsPrefix) {
+		return desc, labels
+	}
+
+	for _, name := range strings.Split(strings.TrimPrefix(last, labelsPrefix), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			labels = append(labels, gitHubLabel{Name: name})
+		}
+	}
+
+	if i < 0 {
+		return "", labels
+	}
+
+	return strings.TrimRight(desc[:i], "\n"), labels
+}
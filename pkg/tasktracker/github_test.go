@@ -0,0 +1,95 @@
+package tasktracker_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitHubIssues(t *testing.T) {
+	t.Parallel()
+
+	in := `[
+		{"number": 1, "title": "Fix bug", "body": "Steps to reproduce.", "labels": [{"name": "bug"}, {"name": "ui"}], "state": "OPEN"},
+		{"number": 2, "title": "Add feature", "body": "", "labels": [], "state": "closed"}
+	]`
+
+	got, err := tasktracker.ParseGitHubIssues(strings.NewReader(in), "gh-")
+	require.NoError(t, err)
+
+	want := []tasktracker.Task{
+		{ID: "gh-1", Title: "Fix bug", Description: "Steps to reproduce.\n\nLabels: bug, ui", Done: false},
+		{ID: "gh-2", Title: "Add feature", Description: "Add feature", Done: true},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestParseGitHubIssues_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"not json", "- [ ] task", "decode issues json"},
+		{"no number", `[{"title": "Task"}]`, "invalid number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tasktracker.ParseGitHubIssues(strings.NewReader(tt.in), "")
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestFormatGitHubIssues(t *testing.T) {
+	t.Parallel()
+
+	tasks := []tasktracker.Task{
+		{ID: "gh-1", Title: "Fix bug", Description: "Steps to reproduce.\n\nLabels: bug, ui", Done: false},
+		{ID: "gh-2", Title: "Add feature", Description: "Add feature", Done: true},
+	}
+
+	var sb strings.Builder
+
+	require.NoError(t, tasktracker.FormatGitHubIssues(&sb, tasks, "gh-"))
+
+	got, err := tasktracker.ParseGitHubIssues(strings.NewReader(sb.String()), "gh-")
+	require.NoError(t, err)
+	assert.Equal(t, tasks, got, "should round-trip")
+
+	sb.Reset()
+
+	require.NoError(t, tasktracker.FormatGitHubIssues(&sb, []tasktracker.Task{
+		{ID: "local", Title: "Local task", Description: "Description.", Done: false},
+	}, ""))
+	assert.JSONEq(t,
+		`[{"title": "Local task", "body": "Description.\n\n<!-- task-id: local -->", "labels": [], "state": "open"}]`,
+		sb.String())
+}
+
+func TestFormatGitHubIssues_NonNumericIDs(t *testing.T) {
+	t.Parallel()
+
+	tasks := []tasktracker.Task{
+		{ID: "1.1", Title: "Subtask", Description: "Subtask", Done: false},
+		{ID: "apple", Title: "Fruit", Description: "Buy apples.\n\nLabels: food", Done: true},
+		{ID: "gh-0", Title: "Zero", Description: "Not an issue number.", Done: false},
+		{ID: "gh-3", Title: "Issue", Description: "Issue", Done: false},
+	}
+
+	var sb strings.Builder
+
+	require.NoError(t, tasktracker.FormatGitHubIssues(&sb, tasks, "gh-"))
+
+	got, err := tasktracker.ParseGitHubIssues(strings.NewReader(sb.String()), "gh-")
+	require.NoError(t, err)
+	assert.Equal(t, tasks, got, "should round-trip")
+}
@@ -0,0 +1,170 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const pageSize = 50
+
+type issue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+type issueComment struct {
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type issueRequest struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	State string `json:"state,omitempty"`
+}
+
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+// client calls the issues REST API shared by GitHub and Gitea.
+type client struct {
+	http    *http.Client
+	baseURL string
+	owner   string
+	repo    string
+	token   string
+}
+
+func (c *client) listIssues(ctx context.Context) ([]issue, error) {
+	query := url.Values{
+		"state": []string{"all"},
+		"type":  []string{"issues"},
+	}
+
+	issues, err := listPages[issue](ctx, c, c.repoPath("issues"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	// GitHub lists pull requests as issues.
+	return slices.DeleteFunc(issues, func(iss issue) bool {
+		return len(iss.PullRequest) != 0 && string(iss.PullRequest) != "null"
+	}), nil
+}
+
+// listPages fetches all pages of the collection at the path.
+func listPages[T any](ctx context.Context, c *client, path string, query url.Values) ([]T, error) {
+	var all []T
+
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(pageSize))
+		query.Set("limit", strconv.Itoa(pageSize))
+
+		var items []T
+
+		if err := c.do(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &items); err != nil {
+			return nil, err
+		}
+
+		all = append(all, items...)
+
+		if len(items) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+func (c *client) createIssue(ctx context.Context, req issueRequest) (issue, error) {
+	var iss issue
+
+	err := c.do(ctx, http.MethodPost, c.repoPath("issues"), req, &iss)
+
+	return iss, err
+}
+
+func (c *client) editIssue(ctx context.Context, number int, req issueRequest) (issue, error) {
+	var iss issue
+
+	err := c.do(ctx, http.MethodPatch, c.repoPath("issues", strconv.Itoa(number)), req, &iss)
+
+	return iss, err
+}
+
+func (c *client) listComments(ctx context.Context, number int) ([]issueComment, error) {
+	return listPages[issueComment](ctx, c, c.repoPath("issues", strconv.Itoa(number), "comments"), url.Values{})
+}
+
+func (c *client) createComment(ctx context.Context, number int, body string) error {
+	return c.do(ctx, http.MethodPost, c.repoPath("issues", strconv.Itoa(number), "comments"),
+		commentRequest{Body: body}, nil)
+}
+
+func (c *client) repoPath(elem ...string) string {
+	p, _ := url.JoinPath(c.baseURL, append([]string{"repos", c.owner, c.repo}, elem...)...)
+
+	return p
+}
+
+func (c *client) do(ctx context.Context, method, url string, in, out any) error {
+	var body io.Reader
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("new %s request: %w", method, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) //nolint:mnd // Enough for an error message.
+
+		return fmt.Errorf("%s %q: unexpected status %q: %s", method, url, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response of %s %q: %w", method, url, err)
+	}
+
+	return nil
+}
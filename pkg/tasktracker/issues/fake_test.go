@@ -0,0 +1,295 @@
+package issues_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	fakeOwner = "owner"
+	fakeRepo  = "repo"
+	fakeToken = "secret"
+)
+
+type fakeIssue struct {
+	Number      int              `json:"number"`
+	Title       string           `json:"title"`
+	Body        string           `json:"body"`
+	State       string           `json:"state"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	PullRequest *json.RawMessage `json:"pull_request,omitempty"`
+
+	comments []fakeComment
+}
+
+type fakeComment struct {
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// fakeIssues is an in-memory fake of the GitHub issues API.
+// Every change advances its clock by a second.
+type fakeIssues struct {
+	mu     sync.Mutex
+	now    time.Time
+	issues []*fakeIssue
+	down   bool
+}
+
+func newFakeIssues(t *testing.T) (*fakeIssues, *httptest.Server) {
+	t.Helper()
+
+	f := &fakeIssues{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	mux := http.NewServeMux()
+	prefix := "/repos/" + fakeOwner + "/" + fakeRepo
+
+	mux.HandleFunc("GET "+prefix+"/issues", f.listIssues)
+	mux.HandleFunc("POST "+prefix+"/issues", f.createIssue)
+	mux.HandleFunc("PATCH "+prefix+"/issues/{number}", f.editIssue)
+	mux.HandleFunc("GET "+prefix+"/issues/{number}/comments", f.listComments)
+	mux.HandleFunc("POST "+prefix+"/issues/{number}/comments", f.createComment)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		down := f.down
+		f.mu.Unlock()
+
+		if down {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer "+fakeToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return f, srv
+}
+
+func (f *fakeIssues) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.down = down
+}
+
+// add creates the issue as if it was created by someone else.
+func (f *fakeIssues) add(title, body string, comments ...string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	iss := f.newIssue(title, body)
+
+	for _, c := range comments {
+		iss.comments = append(iss.comments, fakeComment{Body: c, CreatedAt: f.tick()})
+	}
+
+	return iss.Number
+}
+
+// edit changes the issue as if it was changed by someone else.
+func (f *fakeIssues) edit(number int, title, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	iss := f.issue(number)
+	iss.Title = title
+	iss.Body = body
+	iss.UpdatedAt = f.tick()
+}
+
+// setClock sets the time of the next change.
+func (f *fakeIssues) setClock(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now.Add(-time.Second)
+}
+
+func (f *fakeIssues) get(number int) fakeIssue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return *f.issue(number)
+}
+
+func (f *fakeIssues) tick() time.Time {
+	f.now = f.now.Add(time.Second)
+
+	return f.now
+}
+
+func (f *fakeIssues) newIssue(title, body string) *fakeIssue {
+	iss := &fakeIssue{
+		Number:    len(f.issues) + 1,
+		Title:     title,
+		Body:      body,
+		State:     "open",
+		UpdatedAt: f.tick(),
+	}
+
+	f.issues = append(f.issues, iss)
+
+	return iss
+}
+
+func (f *fakeIssues) issue(number int) *fakeIssue {
+	if number < 1 || number > len(f.issues) {
+		return nil
+	}
+
+	return f.issues[number-1]
+}
+
+func (f *fakeIssues) listIssues(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON(w, paginate(r, f.issues))
+}
+
+// paginate returns the requested page of the items. Like GitHub, it returns the first 30 items by default.
+func paginate[T any](r *http.Request, items []T) []T {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil {
+		perPage = 30
+	}
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	return slices.Clone(items[start:end])
+}
+
+func (f *fakeIssues) createIssue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, f.newIssue(req.Title, req.Body))
+}
+
+func (f *fakeIssues) editIssue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		State string `json:"state"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	iss := f.lookup(w, r)
+	if iss == nil {
+		return
+	}
+
+	if req.Title != "" {
+		iss.Title = req.Title
+	}
+
+	if req.Body != "" {
+		iss.Body = req.Body
+	}
+
+	if req.State != "" {
+		iss.State = req.State
+	}
+
+	iss.UpdatedAt = f.tick()
+
+	writeJSON(w, iss)
+}
+
+func (f *fakeIssues) listComments(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	iss := f.lookup(w, r)
+	if iss == nil {
+		return
+	}
+
+	writeJSON(w, paginate(r, iss.comments))
+}
+
+func (f *fakeIssues) createComment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Body string `json:"body"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	iss := f.lookup(w, r)
+	if iss == nil {
+		return
+	}
+
+	c := fakeComment{Body: req.Body, CreatedAt: f.tick()}
+	iss.comments = append(iss.comments, c)
+	iss.UpdatedAt = c.CreatedAt
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, c)
+}
+
+func (f *fakeIssues) lookup(w http.ResponseWriter, r *http.Request) *fakeIssue {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+
+	iss := f.issue(number)
+	if iss == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+
+	return iss
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,394 @@
+// Package issues implements [tasktracker.Tracker] on top of a GitHub or Gitea compatible issues REST API.
+//
+// Tasks are kept in a local cache tracker which serves reads and search.
+// Changes are pushed to the issues immediately, remote changes are pulled by [Tracker.Sync].
+// When both sides changed, the most recently updated one wins.
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+)
+
+const (
+	stateOpen   = "open"
+	stateClosed = "closed"
+)
+
+// metaRe matches the hidden metadata appended to issue and comment bodies.
+var metaRe = regexp.MustCompile(`(?:\n\n)?<!-- coder: (\{.*\}) -->\s*$`)
+
+type meta struct {
+	ID         string `json:"id,omitempty"`
+	Author     string `json:"author,omitempty"`
+	Attachment string `json:"attachment,omitempty"`
+}
+
+type Config struct {
+	// BaseURL is the root of the API, e.g. "https://api.github.com" or "https://gitea.example.com/api/v1".
+	BaseURL string
+	Owner   string
+	Repo    string
+	Token   string
+	// StateFile keeps links between tasks and issues and sync times.
+	StateFile string
+}
+
+type Tracker struct {
+	cfg    Config
+	client *client
+	cache  tasktracker.Tracker
+	now    func() time.Time
+
+	mu    sync.Mutex
+	state syncState
+}
+
+// NewTracker returns the tracker of the repository issues cached by the cache tracker.
+func NewTracker(cfg Config, cache tasktracker.Tracker, opts ...Option) (*Tracker, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.httpClient == nil {
+		o.httpClient = http.DefaultClient
+	}
+
+	if o.now == nil {
+		o.now = time.Now
+	}
+
+	state, err := loadState(cfg.StateFile)
+	if err != nil {
+		return nil, fmt.Errorf("load sync state: %w", err)
+	}
+
+	return &Tracker{
+		cfg: cfg,
+		client: &client{
+			http:    o.httpClient,
+			baseURL: cfg.BaseURL,
+			owner:   cfg.Owner,
+			repo:    cfg.Repo,
+			token:   cfg.Token,
+		},
+		cache: cache,
+		now:   o.now,
+		mu:    sync.Mutex{},
+		state: state,
+	}, nil
+}
+
+// Set stores the task locally and pushes it to the issue.
+// If the push fails, the task is kept locally and pushed by the next [Tracker.Sync].
+func (t *Tracker) Set(ctx context.Context, id string, task tasktracker.Task) error {
+	if id == "" {
+		return errors.New("empty task id")
+	}
+
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.cache.Set(ctx, id, task); err != nil {
+		return fmt.Errorf("set cached task: %w", err)
+	}
+
+	e := t.state.entry(id)
+	e.Dirty = true
+	e.Deleted = false
+	e.LocalUpdatedAt = t.now()
+
+	pushErr := t.push(ctx, id, e)
+
+	if err := saveState(t.cfg.StateFile, t.state); err != nil {
+		return err
+	}
+
+	if pushErr != nil {
+		return fmt.Errorf("push task %q, it is kept locally until the next sync: %w", id, pushErr)
+	}
+
+	return nil
+}
+
+func (t *Tracker) Get(ctx context.Context, id string) (tasktracker.Task, error) {
+	return t.cache.Get(ctx, id) //nolint:wrapcheck // Transparent cache.
+}
+
+// Del deletes the task locally and closes its issue.
+// Closed issues of deleted tasks are not pulled anymore.
+func (t *Tracker) Del(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("empty id")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.cache.Del(ctx, id); err != nil {
+		return fmt.Errorf("delete cached task: %w", err)
+	}
+
+	e, ok := t.state.Tasks[id]
+	if !ok {
+		return nil
+	}
+
+	e.Deleted = true
+	e.Dirty = false
+
+	if e.Number > 0 {
+		iss, err := t.client.editIssue(ctx, e.Number, issueRequest{Title: "", Body: "", State: stateClosed})
+		if err != nil {
+			return fmt.Errorf("close issue #%d: %w", e.Number, err)
+		}
+
+		e.RemoteUpdatedAt = iss.UpdatedAt
+	}
+
+	return saveState(t.cfg.StateFile, t.state)
+}
+
+func (t *Tracker) List(ctx context.Context, done *bool) ([]tasktracker.Task, error) {
+	return t.cache.List(ctx, done) //nolint:wrapcheck // Transparent cache.
+}
+
+func (t *Tracker) Search(ctx context.Context, query string) ([]tasktracker.SearchResult, error) {
+	return t.cache.Search(ctx, query) //nolint:wrapcheck // Transparent cache.
+}
+
+// AddComment posts the comment to the issue of the task. The task is pushed first if needed.
+func (t *Tracker) AddComment(ctx context.Context, id string, comment tasktracker.Comment) error {
+	author, err := comment.Author.ToString()
+	if err != nil {
+		return fmt.Errorf("author to string: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.cache.Get(ctx, id); err != nil {
+		return fmt.Errorf("get cached task: %w", err)
+	}
+
+	e := t.state.entry(id)
+
+	if e.Number == 0 || e.Dirty {
+		if err := t.push(ctx, id, e); err != nil {
+			return fmt.Errorf("push task %q: %w", id, err)
+		}
+	}
+
+	body := formatBody(comment.Body, meta{ID: "", Author: author, Attachment: comment.Attachment})
+
+	if err := t.client.createComment(ctx, e.Number, body); err != nil {
+		return fmt.Errorf("create comment of issue #%d: %w", e.Number, err)
+	}
+
+	if err := t.cache.AddComment(ctx, id, comment); err != nil {
+		return fmt.Errorf("add cached comment: %w", err)
+	}
+
+	return saveState(t.cfg.StateFile, t.state)
+}
+
+func (t *Tracker) ListComments(ctx context.Context, id string) ([]tasktracker.Comment, error) {
+	return t.cache.ListComments(ctx, id) //nolint:wrapcheck // Transparent cache.
+}
+
+// Sync pulls changed issues into the cache and pushes local changes which weren't pushed yet.
+func (t *Tracker) Sync(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	issues, err := t.client.listIssues(ctx)
+	if err != nil {
+		return fmt.Errorf("list issues: %w", err)
+	}
+
+	for _, iss := range issues {
+		if err := t.pull(ctx, iss); err != nil {
+			return err
+		}
+	}
+
+	ids := make([]string, 0, len(t.state.Tasks))
+
+	for id, e := range t.state.Tasks {
+		if e.Dirty && !e.Deleted {
+			ids = append(ids, id)
+		}
+	}
+
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		if err := t.push(ctx, id, t.state.Tasks[id]); err != nil {
+			return fmt.Errorf("push task %q: %w", id, err)
+		}
+	}
+
+	return saveState(t.cfg.StateFile, t.state)
+}
+
+// pull updates the cached task by the issue if the issue changed since the last sync
+// and its change is newer than the local one.
+func (t *Tracker) pull(ctx context.Context, iss issue) error {
+	desc, m := parseBody(iss.Body)
+
+	id := m.ID
+	if id == "" {
+		id = strconv.Itoa(iss.Number)
+	}
+
+	e := t.state.entry(id)
+
+	if e.Deleted || !iss.UpdatedAt.After(e.RemoteUpdatedAt) {
+		return nil
+	}
+
+	if e.Dirty && e.LocalUpdatedAt.After(iss.UpdatedAt) {
+		// Local change wins, it will be pushed.
+		return nil
+	}
+
+	if desc == "" {
+		desc = iss.Title
+	}
+
+	if err := t.cache.Set(ctx, id, tasktracker.Task{
+		ID:          id,
+		Title:       iss.Title,
+		Description: desc,
+		Done:        iss.State == stateClosed,
+	}); err != nil {
+		return fmt.Errorf("set cached task %q: %w", id, err)
+	}
+
+	e.Number = iss.Number
+	e.RemoteUpdatedAt = iss.UpdatedAt
+	e.Dirty = false
+
+	if err := t.pullComments(ctx, id, iss.Number); err != nil {
+		return fmt.Errorf("pull comments of issue #%d: %w", iss.Number, err)
+	}
+
+	return nil
+}
+
+// pullComments appends remote comments missing in the cache. Comments are never edited locally,
+// so the cache keeps a prefix of the remote comments.
+func (t *Tracker) pullComments(ctx context.Context, id string, number int) error {
+	remote, err := t.client.listComments(ctx, number)
+	if err != nil {
+		return fmt.Errorf("list comments: %w", err)
+	}
+
+	local, err := t.cache.ListComments(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list cached comments: %w", err)
+	}
+
+	for _, rc := range remote[min(len(local), len(remote)):] {
+		body, m := parseBody(rc.Body)
+
+		author := tasktracker.AuthorHuman
+
+		if m.Author != "" {
+			if author, err = tasktracker.AuthorFromString(m.Author); err != nil {
+				return fmt.Errorf("parse comment author: %w", err)
+			}
+		}
+
+		if err := t.cache.AddComment(ctx, id, tasktracker.Comment{
+			Author:     author,
+			CreatedAt:  rc.CreatedAt,
+			Body:       body,
+			Attachment: m.Attachment,
+		}); err != nil {
+			return fmt.Errorf("add cached comment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// push creates or edits the issue of the task.
+func (t *Tracker) push(ctx context.Context, id string, e *syncEntry) error {
+	task, err := t.cache.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get cached task: %w", err)
+	}
+
+	state := stateOpen
+	if task.Done {
+		state = stateClosed
+	}
+
+	req := issueRequest{
+		Title: task.Title,
+		Body:  formatBody(task.Description, meta{ID: id, Author: "", Attachment: ""}),
+		State: state,
+	}
+
+	var iss issue
+
+	if e.Number == 0 {
+		if iss, err = t.client.createIssue(ctx, issueRequest{Title: req.Title, Body: req.Body, State: ""}); err != nil {
+			return fmt.Errorf("create issue: %w", err)
+		}
+
+		e.Number = iss.Number
+	}
+
+	// New issues are open, so only closed ones are edited after creation.
+	if iss.Number == 0 || task.Done {
+		if iss, err = t.client.editIssue(ctx, e.Number, req); err != nil {
+			return fmt.Errorf("edit issue #%d: %w", e.Number, err)
+		}
+	}
+
+	e.RemoteUpdatedAt = iss.UpdatedAt
+	e.Dirty = false
+
+	return nil
+}
+
+// formatBody appends the metadata to the body as a hidden HTML comment.
+func formatBody(body string, m meta) string {
+	b, _ := json.Marshal(m) //nolint:errchkjson // Plain strings only.
+
+	return body + "\n\n<!-- coder: " + string(b) + " -->"
+}
+
+// parseBody cuts the metadata off the body.
+func parseBody(body string) (string, meta) {
+	var m meta
+
+	loc := metaRe.FindStringSubmatchIndex(body)
+	if loc == nil {
+		return strings.TrimSpace(body), m
+	}
+
+	if err := json.Unmarshal([]byte(body[loc[2]:loc[3]]), &m); err != nil {
+		return strings.TrimSpace(body), m
+	}
+
+	return body[:loc[0]], m
+}
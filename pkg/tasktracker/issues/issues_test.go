@@ -0,0 +1,227 @@
+package issues_test
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/issues"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/justfiles"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/trackertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTracker(t *testing.T, baseURL, dir string, embed llm.Embedder, opts ...issues.Option) *issues.Tracker {
+	t.Helper()
+
+	cache, err := justfiles.NewTaskTracker(filepath.Join(dir, "cache"), embed)
+	require.NoError(t, err)
+
+	tracker, err := issues.NewTracker(issues.Config{
+		BaseURL:   baseURL,
+		Owner:     "owner",
+		Repo:      "repo",
+		Token:     "secret",
+		StateFile: filepath.Join(dir, "state.json"),
+	}, cache, opts...)
+	require.NoError(t, err)
+
+	return tracker
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	trackertest.Run(t, func(t *testing.T) trackertest.Factory {
+		t.Helper()
+
+		_, srv := newFakeIssues(t)
+		dir := t.TempDir()
+
+		return func(embed llm.Embedder) tasktracker.Tracker {
+			return newTracker(t, srv.URL, dir, embed)
+		}
+	})
+}
+
+func TestTracker_Push(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fake, srv := newFakeIssues(t)
+	tracker := newTracker(t, srv.URL, t.TempDir(), nil)
+
+	require.NoError(t, tracker.Set(ctx, "task", tasktracker.Task{
+		ID: "task", Title: "Title", Description: "Description.", Done: false,
+	}))
+
+	iss := fake.get(1)
+	assert.Equal(t, "Title", iss.Title)
+	assert.Equal(t, "Description.\n\n<!-- coder: {\"id\":\"task\"} -->", iss.Body)
+	assert.Equal(t, "open", iss.State)
+
+	require.NoError(t, tracker.Set(ctx, "task", tasktracker.Task{
+		ID: "task", Title: "Title", Description: "Description.", Done: true,
+	}))
+	assert.Equal(t, "closed", fake.get(1).State, "done task must close the issue")
+
+	require.NoError(t, tracker.AddComment(ctx, "task", tasktracker.Comment{
+		Author: tasktracker.AuthorTester, CreatedAt: time.Now(), Body: "Passed.", Attachment: "out.txt",
+	}))
+	require.Len(t, fake.get(1).comments, 1)
+	assert.Equal(t, "Passed.\n\n<!-- coder: {\"author\":\"tester\",\"attachment\":\"out.txt\"} -->",
+		fake.get(1).comments[0].Body)
+
+	require.NoError(t, tracker.Del(ctx, "task"))
+	assert.Equal(t, "closed", fake.get(1).State)
+
+	require.NoError(t, tracker.Sync(ctx))
+
+	_, err := tracker.Get(ctx, "task")
+	require.ErrorIs(t, err, tasktracker.ErrNotFound, "deleted task must not be pulled back")
+}
+
+func TestTracker_SyncPullsIssues(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fake, srv := newFakeIssues(t)
+	tracker := newTracker(t, srv.URL, t.TempDir(), nil)
+
+	number := fake.add("External issue", "Created in the browser.", "First!", "Second.")
+
+	require.NoError(t, tracker.Sync(ctx))
+
+	id := strconv.Itoa(number)
+
+	got, err := tracker.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, tasktracker.Task{ID: id, Title: "External issue", Description: "Created in the browser.", Done: false}, got)
+
+	comments, err := tracker.ListComments(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, tasktracker.AuthorHuman, comments[0].Author)
+	assert.Equal(t, "First!", comments[0].Body)
+	assert.Equal(t, "Second.", comments[1].Body)
+
+	fake.edit(number, "Renamed issue", "New body.")
+
+	require.NoError(t, tracker.Sync(ctx))
+
+	got, err = tracker.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed issue", got.Title)
+	assert.Equal(t, "New body.", got.Description)
+
+	comments, err = tracker.ListComments(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, comments, 2, "comments must not be duplicated")
+}
+
+func TestTracker_SyncPaginates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fake, srv := newFakeIssues(t)
+	tracker := newTracker(t, srv.URL, t.TempDir(), nil)
+
+	comments := make([]string, 120)
+	for i := range comments {
+		comments[i] = "Comment " + strconv.Itoa(i)
+		fake.add("Issue "+strconv.Itoa(i), "")
+	}
+
+	number := fake.add("Discussed issue", "", comments...)
+
+	require.NoError(t, tracker.Sync(ctx))
+
+	tasks, err := tracker.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 121)
+
+	got, err := tracker.ListComments(ctx, strconv.Itoa(number))
+	require.NoError(t, err)
+	require.Len(t, got, len(comments))
+	assert.Equal(t, comments[len(comments)-1], got[len(got)-1].Body)
+}
+
+func TestTracker_SyncConflicts(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		localAt   time.Time
+		remoteAt  time.Time
+		wantTitle string
+	}{
+		{"remote is newer", base, base.Add(time.Hour), "Remote title"},
+		{"local is newer", base.Add(time.Hour), base, "Local title"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			fake, srv := newFakeIssues(t)
+
+			localAt := tt.localAt
+			tracker := newTracker(t, srv.URL, t.TempDir(), nil, issues.WithClock(func() time.Time { return localAt }))
+
+			task := tasktracker.Task{ID: "task", Title: "Title", Description: "Description.", Done: false}
+
+			fake.setClock(base.Add(-time.Hour))
+			require.NoError(t, tracker.Set(ctx, task.ID, task))
+
+			// Local change made offline.
+			fake.setDown(true)
+
+			task.Title = "Local title"
+			require.Error(t, tracker.Set(ctx, task.ID, task))
+
+			fake.setDown(false)
+
+			// Concurrent remote change.
+			fake.setClock(tt.remoteAt)
+			fake.edit(1, "Remote title", fake.get(1).Body)
+
+			require.NoError(t, tracker.Sync(ctx))
+
+			got, err := tracker.Get(ctx, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTitle, got.Title)
+			assert.Equal(t, tt.wantTitle, fake.get(1).Title)
+		})
+	}
+}
+
+func TestTracker_Unauthorized(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, srv := newFakeIssues(t)
+
+	dir := t.TempDir()
+
+	cache, err := justfiles.NewTaskTracker(filepath.Join(dir, "cache"), nil)
+	require.NoError(t, err)
+
+	tracker, err := issues.NewTracker(issues.Config{
+		BaseURL:   srv.URL,
+		Owner:     "owner",
+		Repo:      "repo",
+		Token:     "wrong",
+		StateFile: filepath.Join(dir, "state.json"),
+	}, cache)
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, tracker.Sync(ctx), "401")
+}
@@ -0,0 +1,27 @@
+package issues
+
+import (
+	"net/http"
+	"time"
+)
+
+type options struct {
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+type Option func(*options)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(opts *options) {
+		opts.httpClient = client
+	}
+}
+
+// WithClock sets the source of local modification times used to resolve conflicts.
+// Default: [time.Now].
+func WithClock(now func() time.Time) Option {
+	return func(opts *options) {
+		opts.now = now
+	}
+}
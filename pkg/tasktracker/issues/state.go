@@ -0,0 +1,83 @@
+package issues
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/WinPooh32/go-coder/internal/fsutil"
+)
+
+// syncEntry links a task to its issue.
+type syncEntry struct {
+	Number int `json:"number"`
+	// RemoteUpdatedAt is the update time of the issue when it was synced last time.
+	RemoteUpdatedAt time.Time `json:"remote_updated_at"`
+	// LocalUpdatedAt is the time of the last local change.
+	LocalUpdatedAt time.Time `json:"local_updated_at"`
+	// Dirty is set when the local change is not pushed yet.
+	Dirty bool `json:"dirty"`
+	// Deleted is set when the task is deleted locally, its issue is closed and not pulled anymore.
+	Deleted bool `json:"deleted"`
+}
+
+type syncState struct {
+	Tasks map[string]*syncEntry `json:"tasks"`
+}
+
+func loadState(filename string) (syncState, error) {
+	state := syncState{Tasks: map[string]*syncEntry{}}
+
+	b, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return state, fmt.Errorf("read state file %q: %w", filename, err)
+	}
+
+	if err := json.Unmarshal(b, &state); err != nil {
+		return state, fmt.Errorf("unmarshal state file %q: %w", filename, err)
+	}
+
+	if state.Tasks == nil {
+		state.Tasks = map[string]*syncEntry{}
+	}
+
+	return state, nil
+}
+
+func saveState(filename string, state syncState) error {
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return fmt.Errorf("make state directory: %w", err)
+	}
+
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(filename, b); err != nil {
+		return fmt.Errorf("write state file %q: %w", filename, err)
+	}
+
+	return nil
+}
+
+// entry returns the entry of the task creating it if needed.
+func (s syncState) entry(id string) *syncEntry {
+	e, ok := s.Tasks[id]
+	if !ok {
+		e = &syncEntry{
+			Number:          0,
+			RemoteUpdatedAt: time.Time{},
+			LocalUpdatedAt:  time.Time{},
+			Dirty:           false,
+			Deleted:         false,
+		}
+		s.Tasks[id] = e
+	}
+
+	return e
+}
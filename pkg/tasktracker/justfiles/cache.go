@@ -0,0 +1,129 @@
+package justfiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/internal/fsutil"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// cacheDirName is the name of the directory inside the tasks directory where
+	// embedding vectors of tasks are kept.
+	cacheDirName = ".embeddings"
+	// cacheIndexName is the name of the file describing vectors of the cache.
+	cacheIndexName = "index.yaml"
+)
+
+type vectorData struct {
+	Vector []float32 `yaml:"vector,flow"`
+}
+
+// cacheIndex describes the embedding model which produced the cached vectors.
+type cacheIndex struct {
+	Model     string `yaml:"model"`
+	Dimension int    `yaml:"dimension"`
+}
+
+// vectorCache stores embedding vectors keyed by a hash of the embedded content and the model name,
+// so unchanged tasks are never embedded twice and vectors of different models never mix.
+type vectorCache struct {
+	dir string
+}
+
+func (c vectorCache) filename(key string) string {
+	return filepath.Join(c.dir, key+".yaml")
+}
+
+// get returns the cached vector or nil if the vector is not cached.
+func (c vectorCache) get(key string) ([]float32, error) {
+	var data vectorData
+
+	ok, err := readYAML(c.filename(key), &data)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return data.Vector, nil
+}
+
+func (c vectorCache) set(key string, vec []float32) error {
+	return c.write(c.filename(key), &vectorData{Vector: vec})
+}
+
+// index returns the description of the cached vectors. Zero value is returned for the empty cache.
+func (c vectorCache) index() (idx cacheIndex, err error) {
+	if _, err := readYAML(filepath.Join(c.dir, cacheIndexName), &idx); err != nil {
+		return idx, err
+	}
+
+	return idx, nil
+}
+
+func (c vectorCache) setIndex(idx cacheIndex) error {
+	return c.write(filepath.Join(c.dir, cacheIndexName), &idx)
+}
+
+// prune removes all cached vectors except the ones with the given keys.
+func (c vectorCache) prune(keep map[string]struct{}) error {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read vectors cache directory: %w", err)
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == cacheIndexName || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		if _, ok := keep[strings.TrimSuffix(e.Name(), ".yaml")]; ok {
+			continue
+		}
+
+		filename := filepath.Join(c.dir, e.Name())
+
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove cached vector %q: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+func (c vectorCache) write(filename string, v any) error {
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("make vectors cache directory: %w", err)
+	}
+
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal yaml: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(filename, b); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// readYAML reads the yaml file into v. It reports false if the file does not exist.
+func readYAML(filename string, v any) (bool, error) {
+	b, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("read file %q: %w", filename, err)
+	}
+
+	if err := yaml.Unmarshal(b, v); err != nil {
+		return false, fmt.Errorf("unmarshal yaml %q: %w", filename, err)
+	}
+
+	return true, nil
+}
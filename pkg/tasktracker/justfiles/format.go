@@ -0,0 +1,107 @@
+package justfiles
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelim = "---\n"
+
+type frontMatter struct {
+	ID    string `yaml:"id"`
+	Title string `yaml:"title"`
+}
+
+// encodeTask encodes the task in the given format.
+func encodeTask(tsk taskData, format Format) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		b, err := yaml.Marshal(&tsk)
+		if err != nil {
+			return nil, fmt.Errorf("marshal task yaml: %w", err)
+		}
+
+		return b, nil
+	case FormatMarkdown:
+		return encodeMarkdown(tsk)
+	default:
+		return nil, fmt.Errorf("unknown format %d", format)
+	}
+}
+
+// decodeTask decodes the task from the given format.
+func decodeTask(b []byte, format Format) (tsk taskData, err error) {
+	switch format {
+	case FormatYAML:
+		if err := yaml.Unmarshal(b, &tsk); err != nil {
+			return tsk, fmt.Errorf("unmarshal task yaml: %w", err)
+		}
+
+		return tsk, nil
+	case FormatMarkdown:
+		return decodeMarkdown(b)
+	default:
+		return tsk, fmt.Errorf("unknown format %d", format)
+	}
+}
+
+// encodeMarkdown renders the task as the YAML front matter followed by the description.
+func encodeMarkdown(tsk taskData) ([]byte, error) {
+	meta, err := yaml.Marshal(&frontMatter{
+		ID:    tsk.ID,
+		Title: tsk.Title,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal front matter yaml: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(frontMatterDelim)
+	buf.Write(meta)
+	buf.WriteString(frontMatterDelim)
+	buf.WriteString("\n")
+	buf.WriteString(tsk.Description)
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+// decodeMarkdown parses the task from the YAML front matter and the Markdown body.
+func decodeMarkdown(b []byte) (tsk taskData, err error) {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+
+	rest, ok := bytes.CutPrefix(b, []byte(frontMatterDelim))
+	if !ok {
+		return tsk, errors.New("front matter is not found")
+	}
+
+	var meta, body []byte
+
+	if m, ok := bytes.CutPrefix(rest, []byte(frontMatterDelim)); ok {
+		body = m
+	} else {
+		meta, body, ok = bytes.Cut(rest, []byte("\n"+frontMatterDelim))
+		if !ok {
+			return tsk, errors.New("front matter is not closed")
+		}
+	}
+
+	var fm frontMatter
+
+	if err := yaml.Unmarshal(meta, &fm); err != nil {
+		return tsk, fmt.Errorf("unmarshal front matter yaml: %w", err)
+	}
+
+	body = bytes.TrimPrefix(body, []byte("\n"))
+	body = bytes.TrimSuffix(body, []byte("\n"))
+
+	tsk.ID = fm.ID
+	tsk.Title = fm.Title
+	tsk.Description = string(body)
+
+	return tsk, nil
+}
@@ -1,132 +1,229 @@
 package justfiles
 
 import (
-	"cmp"
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"math"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/WinPooh32/go-coder/internal/fsutil"
 	"github.com/WinPooh32/go-coder/pkg/llm"
 	"github.com/WinPooh32/go-coder/pkg/tasktracker"
 	"gopkg.in/yaml.v3"
 )
 
-const (
-	scoreThreshold = 0.01
-	searchLimit    = 10
-)
+const commentsSuffix = ".comments.yaml"
 
 type taskData struct {
-	ID          string    `yaml:"id"`
-	Title       string    `yaml:"title"`
-	Description string    `yaml:"description"`
-	Vector      []float32 `yaml:"vector,flow"`
+	ID          string `yaml:"id"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+
+	done   bool   `yaml:"-"`
+	format Format `yaml:"-"`
+}
 
-	done bool `yaml:"-"`
+type commentData struct {
+	Author     string    `yaml:"author"`
+	CreatedAt  time.Time `yaml:"created_at"`
+	Body       string    `yaml:"body"`
+	Attachment string    `yaml:"attachment,omitempty"`
 }
 
 type TaskTracker struct {
-	dir   string
-	embed llm.Embedder
+	dir     string
+	embed   llm.Embedder
+	options options
+	vectors vectorCache
+
+	// mu guards task and comment files.
+	mu sync.RWMutex
 }
 
-func NewTaskTracker(dir string, embed llm.Embedder) (*TaskTracker, error) {
+func NewTaskTracker(dir string, embed llm.Embedder, opts ...Option) (*TaskTracker, error) {
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 		return nil, fmt.Errorf("make tasks directory: %w", err)
 	}
 
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &TaskTracker{
-		dir:   dir,
-		embed: embed,
+		dir:     dir,
+		embed:   embed,
+		options: o,
+		vectors: vectorCache{dir: filepath.Join(dir, cacheDirName)},
+		mu:      sync.RWMutex{},
 	}, nil
 }
 
 func (t *TaskTracker) Set(ctx context.Context, id string, task tasktracker.Task) error {
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+
+	newTask, err := t.set(id, task)
+	if err != nil {
+		return err
+	}
+
+	if t.embed == nil {
+		return nil
+	}
+
+	// The task is stored even if the embedder is unavailable,
+	// the missing vector is computed on the next search.
+	if _, err := t.vector(ctx, newTask); err != nil {
+		if !errors.Is(err, tasktracker.ErrEmbedderUnavailable) {
+			return err
+		}
+
+		slog.Warn("embed task",
+			slog.String("id", id),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	return nil
+}
+
+func (t *TaskTracker) set(id string, task tasktracker.Task) (taskData, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	if err := os.MkdirAll(filepath.Join(t.dir, "done"), os.ModePerm); err != nil {
-		return fmt.Errorf("make done folder: %w", err)
+		return taskData{}, fmt.Errorf("make done folder: %w", err)
 	}
 
 	tsk, err := t.get(id)
 	if err != nil && !errors.Is(err, tasktracker.ErrNotFound) {
-		return fmt.Errorf("get task: %w", err)
+		return taskData{}, fmt.Errorf("get task: %w", err)
 	}
 
-	if err := t.removeOldTasks(id, task, tsk); err != nil {
-		return err
+	exists := err == nil
+
+	if err := t.removeOldTasks(id, task.Done, t.options.format); err != nil {
+		return taskData{}, err
 	}
 
-	vec, err := t.embed.Embed(ctx, formatMdText(task))
-	if err != nil {
-		return fmt.Errorf("get task embedding: %w", err)
+	if exists && task.Done != tsk.done {
+		if err := t.moveComments(id, tsk.done, task.Done); err != nil {
+			return taskData{}, err
+		}
 	}
 
 	newTask := taskData{
 		ID:          id,
 		Title:       task.Title,
 		Description: task.Description,
-		Vector:      vec,
 		done:        task.Done,
+		format:      t.options.format,
 	}
 
-	if err := t.writeTaskToFile(id, newTask); err != nil {
-		return err
+	if err := t.writeTaskToFile(id, newTask, t.options.format); err != nil {
+		return taskData{}, err
 	}
 
-	return nil
+	return newTask, nil
 }
 
-func (t *TaskTracker) removeOldTasks(id string, newTask tasktracker.Task, oldTask taskData) error {
-	basename := formatBasename(id)
+// removeOldTasks removes files of the task except the one the task will be written to.
+// It handles moves between active and done tasks and conversions between formats.
+func (t *TaskTracker) removeOldTasks(id string, done bool, format Format) error {
+	keep := t.taskFilename(id, done, format)
 
-	var filename string
+	for _, filename := range t.taskFilenames(id) {
+		if filename == keep {
+			continue
+		}
 
-	if !oldTask.done && newTask.Done != oldTask.done {
-		filename = filepath.Join(t.dir, basename)
-	} else if oldTask.done && newTask.Done != oldTask.done {
-		filename = filepath.Join(t.dir, "done", basename)
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove file %q: %w", filename, err)
+		}
 	}
 
-	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("remove file %q: %w", filename, err)
+	return nil
+}
+
+func (t *TaskTracker) moveComments(id string, oldDone, newDone bool) error {
+	oldname := t.commentsFilename(id, oldDone)
+	newname := t.commentsFilename(id, newDone)
+
+	if err := os.Rename(oldname, newname); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("move comments file %q: %w", oldname, err)
 	}
 
 	return nil
 }
 
-func (t *TaskTracker) writeTaskToFile(id string, newTask taskData) error {
-	var filename string
+func (t *TaskTracker) writeTaskToFile(id string, newTask taskData, format Format) error {
+	filename := t.taskFilename(id, newTask.done, format)
 
-	if newTask.done {
-		filename = filepath.Join(t.dir, "done", formatBasename(id))
-	} else {
-		filename = filepath.Join(t.dir, formatBasename(id))
+	b, err := encodeTask(newTask, format)
+	if err != nil {
+		return err
 	}
 
-	f, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("create task file %q: %w", filename, err)
+	if err := fsutil.WriteFileAtomic(filename, b); err != nil {
+		return fmt.Errorf("write task to file: %w", err)
+	}
+
+	return nil
+}
+
+// Migrate rewrites all tasks stored in other formats to the format of the tracker.
+// Vectors inlined into legacy YAML files are dropped, they are recomputed on the next search.
+// It returns the number of migrated tasks.
+func (t *TaskTracker) Migrate(ctx context.Context) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(t.dir, "done"), os.ModePerm); err != nil {
+		return 0, fmt.Errorf("make done folder: %w", err)
 	}
-	defer f.Close()
 
-	b, err := yaml.Marshal(&newTask)
+	tsks, err := t.getAll()
 	if err != nil {
-		return fmt.Errorf("marshal task yaml: %w", err)
+		return 0, fmt.Errorf("get all tasks: %w", err)
 	}
 
-	if _, err := f.Write(b); err != nil {
-		return fmt.Errorf("write task to file %q: %w", filename, err)
+	var migrated int
+
+	for _, tsk := range tsks {
+		if err := ctx.Err(); err != nil {
+			return migrated, fmt.Errorf("migrate tasks: %w", err)
+		}
+
+		if tsk.format == t.options.format {
+			continue
+		}
+
+		if err := t.writeTaskToFile(tsk.ID, tsk, t.options.format); err != nil {
+			return migrated, fmt.Errorf("migrate task %q: %w", tsk.ID, err)
+		}
+
+		if err := t.removeOldTasks(tsk.ID, tsk.done, t.options.format); err != nil {
+			return migrated, fmt.Errorf("migrate task %q: %w", tsk.ID, err)
+		}
+
+		migrated++
 	}
 
-	return nil
+	return migrated, nil
 }
 
 func (t *TaskTracker) Get(_ context.Context, id string) (task tasktracker.Task, err error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
 	tsk, err := t.get(id)
 	if err != nil {
 		return task, fmt.Errorf("get task: %w", err)
@@ -139,7 +236,10 @@ func (t *TaskTracker) List(_ context.Context, done *bool) ([]tasktracker.Task, e
 	showDoneTasks := done != nil && *done
 	showUndoneTasks := done != nil && !*done
 
+	t.mu.RLock()
 	all, err := t.getAll()
+	t.mu.RUnlock()
+
 	if err != nil {
 		return nil, fmt.Errorf("get all tasks: %w", err)
 	}
@@ -161,61 +261,28 @@ func (t *TaskTracker) List(_ context.Context, done *bool) ([]tasktracker.Task, e
 	return slices.Clip(tasks), nil
 }
 
-func (t *TaskTracker) Search(ctx context.Context, query string) ([]tasktracker.SearchResult, error) {
-	vec, err := t.embed.Embed(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("get query embedding: %w", err)
-	}
-
-	tsks, err := t.getAll()
-	if err != nil {
-		return nil, fmt.Errorf("get all tasks: %w", err)
-	}
-
-	results, err := t.rankSearchResults(vec, tsks, scoreThreshold, searchLimit)
-	if err != nil {
-		return nil, fmt.Errorf("rank search results: %w", err)
-	}
-
-	return results, nil
-}
-
 func (t *TaskTracker) get(id string) (tsk taskData, err error) {
 	if len(id) == 0 {
 		return tsk, errors.New("empty task id")
 	}
 
-	name := formatBasename(id)
-
-	done := false
-
-	f, err := os.Open(filepath.Join(t.dir, name))
-	if os.IsNotExist(err) {
-		if f, err = os.Open(filepath.Join(t.dir, "done", name)); err != nil {
-			if os.IsNotExist(err) {
-				return tsk, tasktracker.ErrNotFound
-			}
-
-			return tsk, fmt.Errorf("open task file: %w", err)
-		}
-
-		done = true
-	} else if err != nil {
-		return tsk, fmt.Errorf("open task file: %w", err)
+	filename, done, format, err := t.findTaskFile(id)
+	if err != nil {
+		return tsk, err
 	}
 
-	defer f.Close()
-
-	b, err := io.ReadAll(f)
+	b, err := os.ReadFile(filename)
 	if err != nil {
 		return tsk, fmt.Errorf("read task file: %w", err)
 	}
 
-	if err := yaml.Unmarshal(b, &tsk); err != nil {
-		return tsk, fmt.Errorf("unmarshal task yaml: %w", err)
+	tsk, err = decodeTask(b, format)
+	if err != nil {
+		return tsk, fmt.Errorf("decode task file %q: %w", filename, err)
 	}
 
 	tsk.done = done
+	tsk.format = format
 
 	var errs []error
 
@@ -230,13 +297,57 @@ func (t *TaskTracker) get(id string) (tsk taskData, err error) {
 	return tsk, errors.Join(errs...)
 }
 
+// findTaskFile looks for the file of the task in all known formats.
+func (t *TaskTracker) findTaskFile(id string) (filename string, done bool, format Format, err error) {
+	for _, done := range []bool{false, true} {
+		for _, format := range []Format{FormatYAML, FormatMarkdown} {
+			filename := t.taskFilename(id, done, format)
+
+			_, err := os.Stat(filename)
+			if err == nil {
+				return filename, done, format, nil
+			} else if !os.IsNotExist(err) {
+				return "", false, 0, fmt.Errorf("stat task file: %w", err)
+			}
+		}
+	}
+
+	return "", false, 0, tasktracker.ErrNotFound
+}
+
+func (t *TaskTracker) taskFilename(id string, done bool, format Format) string {
+	if done {
+		return filepath.Join(t.dir, "done", id+format.ext())
+	}
+
+	return filepath.Join(t.dir, id+format.ext())
+}
+
+// taskFilenames returns all possible file names of the task.
+func (t *TaskTracker) taskFilenames(id string) []string {
+	return []string{
+		t.taskFilename(id, false, FormatYAML),
+		t.taskFilename(id, false, FormatMarkdown),
+		t.taskFilename(id, true, FormatYAML),
+		t.taskFilename(id, true, FormatMarkdown),
+	}
+}
+
 func (t *TaskTracker) getAll() (tsks []taskData, err error) {
-	err = filepath.WalkDir(t.dir, func(_ string, info os.DirEntry, err error) error {
+	err = filepath.WalkDir(t.dir, func(path string, info os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
 		if info.IsDir() {
+			if path != t.dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if isCommentsFile(info.Name()) || !isTaskFile(info.Name()) {
 			return nil
 		}
 
@@ -259,98 +370,142 @@ func (t *TaskTracker) getAll() (tsks []taskData, err error) {
 	return tsks, nil
 }
 
-func (t *TaskTracker) rankSearchResults(
-	q []float32, tsks []taskData, threshold float32, limit int,
-) ([]tasktracker.SearchResult, error) {
-	results := make([]tasktracker.SearchResult, len(tsks))
+func (t *TaskTracker) Del(_ context.Context, id string) error {
+	if id == "" {
+		return errors.New("empty id")
+	}
 
-	var maxDst float32
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	for i, t := range tsks {
-		dst, err := distance(q, t.Vector)
-		if err != nil {
-			return nil, fmt.Errorf("calc vectors distance %q: %w", t.ID, err)
-		}
-
-		if dst > maxDst {
-			maxDst = dst
-		}
+	paths := t.taskFilenames(id)
+	paths = append(paths, t.commentsFilename(id, false), t.commentsFilename(id, true))
 
-		results[i] = tasktracker.SearchResult{
-			Task:  convertToTrackerTask(t),
-			Score: dst,
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("remove file %q: %w", p, err)
+			}
 		}
 	}
 
-	// Normalize and invert.
+	return nil
+}
 
-	var filteredResults []tasktracker.SearchResult
+func (t *TaskTracker) AddComment(_ context.Context, id string, comment tasktracker.Comment) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	if maxDst > 0 {
-		for _, res := range results {
-			res.Score = 1 - (res.Score / maxDst)
+	tsk, err := t.get(id)
+	if err != nil {
+		return fmt.Errorf("get task: %w", err)
+	}
 
-			if res.Score > threshold {
-				filteredResults = append(filteredResults, res)
-			}
-		}
+	author, err := comment.Author.ToString()
+	if err != nil {
+		return fmt.Errorf("author to string: %w", err)
+	}
+
+	filename := t.commentsFilename(id, tsk.done)
+
+	comments, err := readComments(filename)
+	if err != nil {
+		return err
 	}
 
-	slices.SortFunc(filteredResults, func(a, b tasktracker.SearchResult) int {
-		return cmp.Compare(b.Score, a.Score) // DESC order
+	comments = append(comments, commentData{
+		Author:     author,
+		CreatedAt:  comment.CreatedAt,
+		Body:       comment.Body,
+		Attachment: comment.Attachment,
 	})
 
-	if len(filteredResults) > limit {
-		filteredResults = slices.Clip(filteredResults[:limit])
+	b, err := yaml.Marshal(comments)
+	if err != nil {
+		return fmt.Errorf("marshal comments yaml: %w", err)
 	}
 
-	return filteredResults, nil
+	if err := fsutil.WriteFileAtomic(filename, b); err != nil {
+		return fmt.Errorf("write comments to file %q: %w", filename, err)
+	}
+
+	return nil
 }
 
-func (t *TaskTracker) Del(_ context.Context, id string) error {
-	if id == "" {
-		return errors.New("empty id")
+func (t *TaskTracker) ListComments(_ context.Context, id string) ([]tasktracker.Comment, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tsk, err := t.get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get task: %w", err)
 	}
 
-	p := filepath.Join(t.dir, formatBasename(id))
-	if err := os.Remove(p); err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("remove file %q: %w", p, err)
-		}
+	comments, err := readComments(t.commentsFilename(id, tsk.done))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(comments) == 0 {
+		return nil, nil
 	}
 
-	p = filepath.Join(t.dir, "done", formatBasename(id))
-	if err := os.Remove(p); err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("remove file %q: %w", p, err)
+	result := make([]tasktracker.Comment, 0, len(comments))
+
+	for _, c := range comments {
+		author, err := tasktracker.AuthorFromString(c.Author)
+		if err != nil {
+			return nil, fmt.Errorf("parse comment author: %w", err)
 		}
+
+		result = append(result, tasktracker.Comment{
+			Author:     author,
+			CreatedAt:  c.CreatedAt,
+			Body:       c.Body,
+			Attachment: c.Attachment,
+		})
 	}
 
-	return nil
+	return result, nil
 }
 
-// distance calculates the Euclidean distance between two vectors.
-func distance(a, b []float32) (float32, error) {
-	if len(a) != len(b) {
-		return 0, errors.New("vectors must be of the same length")
+func (t *TaskTracker) commentsFilename(id string, done bool) string {
+	if done {
+		return filepath.Join(t.dir, "done", formatCommentsBasename(id))
 	}
 
-	var sum float32
+	return filepath.Join(t.dir, formatCommentsBasename(id))
+}
 
-	for i := range a {
-		diff := a[i] - b[i]
-		sum += diff * diff
+func readComments(filename string) ([]commentData, error) {
+	b, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read comments file %q: %w", filename, err)
 	}
 
-	return float32(math.Sqrt(float64(sum))), nil
+	var comments []commentData
+
+	if err := yaml.Unmarshal(b, &comments); err != nil {
+		return nil, fmt.Errorf("unmarshal comments yaml %q: %w", filename, err)
+	}
+
+	return comments, nil
+}
+
+func formatCommentsBasename(id string) string {
+	return id + commentsSuffix
 }
 
-func formatBasename(id string) string {
-	return id + ".yaml"
+func isCommentsFile(name string) bool {
+	return strings.HasSuffix(name, commentsSuffix)
 }
 
-func formatMdText(task tasktracker.Task) string {
-	return fmt.Sprintf("# %s\n\n%s", task.Title, task.Description)
+func isTaskFile(name string) bool {
+	ext := filepath.Ext(name)
+
+	return ext == FormatYAML.ext() || ext == FormatMarkdown.ext()
 }
 
 func convertToTrackerTask(tsk taskData) tasktracker.Task {
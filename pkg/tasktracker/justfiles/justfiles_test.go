@@ -0,0 +1,97 @@
+package justfiles_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/justfiles"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/trackertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskTracker(t *testing.T) {
+	t.Parallel()
+
+	formats := []justfiles.Format{justfiles.FormatYAML, justfiles.FormatMarkdown}
+
+	for _, format := range formats {
+		t.Run(format.String(), func(t *testing.T) {
+			t.Parallel()
+
+			trackertest.Run(t, func(t *testing.T) trackertest.Factory {
+				t.Helper()
+
+				dir := t.TempDir()
+
+				return func(embed llm.Embedder) tasktracker.Tracker {
+					tracker, err := justfiles.NewTaskTracker(dir, embed, justfiles.WithFormat(format))
+					require.NoError(t, err)
+
+					return tracker
+				}
+			})
+		})
+	}
+}
+
+func TestTaskTracker_Files(t *testing.T) {
+	t.Parallel()
+
+	task := tasktracker.Task{ID: "task", Title: "Title", Description: "Description.", Done: false}
+
+	tests := []struct {
+		name     string
+		format   justfiles.Format
+		done     bool
+		want     string
+		notWants []string
+	}{
+		{"yaml active", justfiles.FormatYAML, false, "task.yaml", []string{"done/task.yaml", "task.md", "done/task.md"}},
+		{"yaml done", justfiles.FormatYAML, true, "done/task.yaml", []string{"task.yaml", "task.md", "done/task.md"}},
+		{"md active", justfiles.FormatMarkdown, false, "task.md", []string{"done/task.md", "task.yaml", "done/task.yaml"}},
+		{"md done", justfiles.FormatMarkdown, true, "done/task.md", []string{"task.md", "task.yaml", "done/task.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			dir := t.TempDir()
+
+			// Start from the opposite state in the other format to exercise moves and conversions.
+			other := justfiles.FormatMarkdown
+			if tt.format == justfiles.FormatMarkdown {
+				other = justfiles.FormatYAML
+			}
+
+			tracker, err := justfiles.NewTaskTracker(dir, nil, justfiles.WithFormat(other))
+			require.NoError(t, err)
+
+			old := task
+			old.Done = !tt.done
+			require.NoError(t, tracker.Set(ctx, task.ID, old))
+
+			tracker, err = justfiles.NewTaskTracker(dir, nil, justfiles.WithFormat(tt.format))
+			require.NoError(t, err)
+
+			want := task
+			want.Done = tt.done
+			require.NoError(t, tracker.Set(ctx, task.ID, want))
+
+			assert.FileExists(t, filepath.Join(dir, tt.want))
+
+			for _, name := range tt.notWants {
+				assert.NoFileExists(t, filepath.Join(dir, name))
+			}
+
+			got, err := tracker.Get(ctx, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
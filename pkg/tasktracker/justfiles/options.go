@@ -0,0 +1,56 @@
+package justfiles
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is a storage format of task files.
+type Format int
+
+const (
+	// FormatYAML stores a task as a YAML file.
+	FormatYAML Format = iota
+	// FormatMarkdown stores a task as a Markdown file with YAML front matter.
+	FormatMarkdown
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatYAML:
+		return "yaml"
+	case FormatMarkdown:
+		return "md"
+	default:
+		return "unknown"
+	}
+}
+
+func (f Format) ext() string {
+	return "." + f.String()
+}
+
+func FormatFromString(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	default:
+		return 0, fmt.Errorf("unknown format %q", s)
+	}
+}
+
+type options struct {
+	format Format
+}
+
+type Option func(*options)
+
+// WithFormat sets the format of written task files. Tasks are read in any known format.
+// Default: [FormatYAML].
+func WithFormat(format Format) Option {
+	return func(opts *options) {
+		opts.format = format
+	}
+}
@@ -0,0 +1,208 @@
+package justfiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+)
+
+// Search looks for tasks semantically similar to the query.
+// It degrades to keyword search when the embedder is unavailable.
+func (t *TaskTracker) Search(ctx context.Context, query string) ([]tasktracker.SearchResult, error) {
+	t.mu.RLock()
+	tsks, err := t.getAll()
+	t.mu.RUnlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("get all tasks: %w", err)
+	}
+
+	results, err := t.semanticSearch(ctx, query, tsks)
+	if errors.Is(err, tasktracker.ErrEmbedderUnavailable) {
+		slog.Warn("fallback to keyword search",
+			slog.String("error", err.Error()),
+		)
+
+		return rankKeywordResults(query, tsks, tasktracker.SearchScoreThreshold, tasktracker.SearchLimit), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Reindex re-embeds all tasks by the current model and drops vectors of other models.
+// It runs automatically on search when the model or the dimension of vectors changes.
+func (t *TaskTracker) Reindex(ctx context.Context) error {
+	t.mu.RLock()
+	tsks, err := t.getAll()
+	t.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("get all tasks: %w", err)
+	}
+
+	idx := cacheIndex{
+		Model:     tasktracker.EmbeddingModel(t.embed),
+		Dimension: 0,
+	}
+
+	keep := make(map[string]struct{}, len(tsks))
+
+	for _, tsk := range tsks {
+		key, vec, err := t.embedTask(ctx, tsk)
+		if err != nil {
+			return err
+		}
+
+		if idx.Dimension == 0 {
+			idx.Dimension = len(vec)
+		} else if idx.Dimension != len(vec) {
+			return fmt.Errorf("task %q: got vector of dimension %d, want %d", tsk.ID, len(vec), idx.Dimension)
+		}
+
+		keep[key] = struct{}{}
+	}
+
+	if err := t.vectors.prune(keep); err != nil {
+		return fmt.Errorf("prune vectors cache: %w", err)
+	}
+
+	if err := t.vectors.setIndex(idx); err != nil {
+		return fmt.Errorf("write vectors cache index: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TaskTracker) semanticSearch(
+	ctx context.Context, query string, tsks []taskData,
+) ([]tasktracker.SearchResult, error) {
+	q, err := tasktracker.EmbedText(ctx, t.embed, query)
+	if err != nil {
+		return nil, fmt.Errorf("get query embedding: %w", err)
+	}
+
+	if err := t.checkIndex(ctx, len(q)); err != nil {
+		return nil, err
+	}
+
+	vecs := make([][]float32, len(tsks))
+
+	for i, tsk := range tsks {
+		if vecs[i], err = t.vector(ctx, tsk); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks := make([]tasktracker.Task, len(tsks))
+	for i, tsk := range tsks {
+		tasks[i] = convertToTrackerTask(tsk)
+	}
+
+	results, err := tasktracker.RankByDistance(q, tasks, vecs, tasktracker.SearchScoreThreshold, tasktracker.SearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("rank search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// checkIndex reindexes tasks if cached vectors were produced by another model
+// or their dimension differs from the dimension of the query vector.
+func (t *TaskTracker) checkIndex(ctx context.Context, dim int) error {
+	idx, err := t.vectors.index()
+	if err != nil {
+		return fmt.Errorf("read vectors cache index: %w", err)
+	}
+
+	model := tasktracker.EmbeddingModel(t.embed)
+
+	if idx.Model == model && idx.Dimension == dim {
+		return nil
+	}
+
+	slog.Info("embedding model changed, reindex tasks",
+		slog.String("old_model", idx.Model),
+		slog.Int("old_dimension", idx.Dimension),
+		slog.String("model", model),
+		slog.Int("dimension", dim),
+	)
+
+	if err := t.Reindex(ctx); err != nil {
+		return fmt.Errorf("reindex tasks: %w", err)
+	}
+
+	return nil
+}
+
+// vector returns the cached vector of the task or embeds the task if the vector is not cached.
+func (t *TaskTracker) vector(ctx context.Context, tsk taskData) ([]float32, error) {
+	key := llm.EmbeddingKey(tasktracker.EmbeddingModel(t.embed), tasktracker.EmbeddingText(convertToTrackerTask(tsk)))
+
+	vec, err := t.vectors.get(key)
+	if err != nil {
+		return nil, fmt.Errorf("get cached vector of task %q: %w", tsk.ID, err)
+	}
+
+	if vec != nil {
+		return vec, nil
+	}
+
+	_, vec, err = t.embedTask(ctx, tsk)
+
+	return vec, err
+}
+
+// embedTask embeds the task and caches its vector.
+func (t *TaskTracker) embedTask(ctx context.Context, tsk taskData) (key string, vec []float32, err error) {
+	text := tasktracker.EmbeddingText(convertToTrackerTask(tsk))
+	key = llm.EmbeddingKey(tasktracker.EmbeddingModel(t.embed), text)
+
+	vec, err = tasktracker.EmbedText(ctx, t.embed, text)
+	if err != nil {
+		return "", nil, fmt.Errorf("get embedding of task %q: %w", tsk.ID, err)
+	}
+
+	if err := t.vectors.set(key, vec); err != nil {
+		return "", nil, fmt.Errorf("cache vector of task %q: %w", tsk.ID, err)
+	}
+
+	return key, vec, nil
+}
+
+// rankKeywordResults scores tasks by the share of query words found in their title and description.
+func rankKeywordResults(
+	query string, tsks []taskData, threshold float32, limit int,
+) []tasktracker.SearchResult {
+	terms := slices.Compact(slices.Sorted(slices.Values(tasktracker.Tokenize(query))))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	results := make([]tasktracker.SearchResult, len(tsks))
+
+	for i, t := range tsks {
+		words := tasktracker.Tokenize(t.Title + "\n" + t.Description)
+
+		var matched int
+
+		for _, term := range terms {
+			if slices.Contains(words, term) {
+				matched++
+			}
+		}
+
+		results[i] = tasktracker.SearchResult{
+			Task:  convertToTrackerTask(t),
+			Score: float32(matched) / float32(len(terms)),
+		}
+	}
+
+	return tasktracker.TopResults(results, threshold, limit)
+}
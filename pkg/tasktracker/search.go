@@ -0,0 +1,139 @@
+package tasktracker
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+)
+
+const (
+	// SearchScoreThreshold is the score search results must exceed.
+	SearchScoreThreshold = 0.01
+	// SearchLimit is the maximum number of search results.
+	SearchLimit = 10
+)
+
+// ErrEmbedderUnavailable is returned when texts can't be embedded.
+// Trackers degrade to keyword search on it.
+var ErrEmbedderUnavailable = errors.New("embedder is unavailable")
+
+var errVectorsLength = errors.New("vectors must be of the same length")
+
+// EmbeddingText returns the text of the task embedded for semantic search.
+func EmbeddingText(task Task) string {
+	return fmt.Sprintf("# %s\n\n%s", task.Title, task.Description)
+}
+
+// EmbedText embeds the text. Failures of a nil or failing embedder are reported as [ErrEmbedderUnavailable],
+// except the cancellation of the context.
+func EmbedText(ctx context.Context, embedder llm.Embedder, text string) ([]float32, error) {
+	if embedder == nil {
+		return nil, ErrEmbedderUnavailable
+	}
+
+	vec, err := embedder.Embed(ctx, text)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrEmbedderUnavailable, err)
+	}
+
+	return vec, nil
+}
+
+// EmbeddingModel returns the name of the embedding model if the embedder reports it.
+func EmbeddingModel(embedder llm.Embedder) string {
+	if m, ok := embedder.(llm.ModelNamer); ok {
+		return m.Model()
+	}
+
+	return ""
+}
+
+// RankByDistance scores the tasks by the Euclidean distance between the query vector and their vectors.
+// The nearest task gets the score close to 1, the farthest one gets 0.
+// At most limit results with the score above the threshold are returned in DESC order.
+func RankByDistance(q []float32, tasks []Task, vecs [][]float32, threshold float32, limit int) ([]SearchResult, error) {
+	results := make([]SearchResult, len(tasks))
+
+	var maxDst float32
+
+	for i, task := range tasks {
+		dst, err := distance(q, vecs[i])
+		if err != nil {
+			return nil, fmt.Errorf("calc vectors distance %q: %w", task.ID, err)
+		}
+
+		maxDst = max(maxDst, dst)
+
+		results[i] = SearchResult{
+			Task:  task,
+			Score: dst,
+		}
+	}
+
+	// Normalize and invert.
+
+	if maxDst == 0 {
+		return nil, nil
+	}
+
+	for i := range results {
+		results[i].Score = 1 - (results[i].Score / maxDst)
+	}
+
+	return TopResults(results, threshold, limit), nil
+}
+
+// TopResults returns at most limit results with the score above the threshold in DESC order.
+func TopResults(results []SearchResult, threshold float32, limit int) []SearchResult {
+	var filteredResults []SearchResult
+
+	for _, res := range results {
+		if res.Score > threshold {
+			filteredResults = append(filteredResults, res)
+		}
+	}
+
+	slices.SortStableFunc(filteredResults, func(a, b SearchResult) int {
+		return cmp.Compare(b.Score, a.Score) // DESC order
+	})
+
+	if len(filteredResults) > limit {
+		filteredResults = slices.Clip(filteredResults[:limit])
+	}
+
+	return filteredResults
+}
+
+// Tokenize splits the text into lower-case words of letters and digits.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// distance calculates the Euclidean distance between two vectors.
+func distance(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, errVectorsLength
+	}
+
+	var sum float32
+
+	for i := range a {
+		diff := a[i] - b[i]
+		sum += diff * diff
+	}
+
+	return float32(math.Sqrt(float64(sum))), nil
+}
@@ -0,0 +1,57 @@
+package tasktracker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/llm/llmtest"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankByDistance(t *testing.T) {
+	t.Parallel()
+
+	tasks := []tasktracker.Task{{ID: "far"}, {ID: "near"}, {ID: "middle"}}
+	vecs := [][]float32{{4, 0}, {0, 1}, {0, 2}}
+
+	got, err := tasktracker.RankByDistance([]float32{0, 0}, tasks, vecs, 0.01, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []tasktracker.SearchResult{
+		{Task: tasktracker.Task{ID: "near"}, Score: 0.75},
+		{Task: tasktracker.Task{ID: "middle"}, Score: 0.5},
+	}, got)
+
+	_, err = tasktracker.RankByDistance([]float32{0}, tasks, vecs, 0, 10)
+	require.Error(t, err)
+}
+
+func TestEmbedText(t *testing.T) {
+	t.Parallel()
+
+	embedder := llmtest.NewEmbedder("test", 8)
+
+	vec, err := tasktracker.EmbedText(context.Background(), embedder, "text")
+	require.NoError(t, err)
+	assert.Len(t, vec, 8)
+	assert.Equal(t, "test", tasktracker.EmbeddingModel(embedder))
+
+	_, err = tasktracker.EmbedText(context.Background(), nil, "text")
+	require.ErrorIs(t, err, tasktracker.ErrEmbedderUnavailable)
+
+	embedder.Err = errors.New("connection refused")
+
+	_, err = tasktracker.EmbedText(context.Background(), embedder, "text")
+	require.ErrorIs(t, err, tasktracker.ErrEmbedderUnavailable)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	embedder.Err = context.Canceled
+
+	_, err = tasktracker.EmbedText(ctx, embedder, "text")
+	require.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, tasktracker.ErrEmbedderUnavailable)
+}
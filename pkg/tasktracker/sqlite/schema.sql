@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id              TEXT PRIMARY KEY,
+    title           TEXT NOT NULL,
+    description     TEXT NOT NULL,
+    done            INTEGER NOT NULL DEFAULT 0,
+    content_hash    TEXT NOT NULL DEFAULT '',
+    embedding       BLOB,
+    embedding_model TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id    TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    author     TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    body       TEXT NOT NULL,
+    attachment TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS comments_task_id ON comments (task_id, id);
+
+CREATE TABLE IF NOT EXISTS relations (
+    child_id  TEXT PRIMARY KEY REFERENCES tasks (id) ON DELETE CASCADE,
+    parent_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    CHECK (child_id <> parent_id)
+);
+
+CREATE INDEX IF NOT EXISTS relations_parent_id ON relations (parent_id);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5 (
+    title,
+    description,
+    content = 'tasks',
+    content_rowid = 'rowid'
+);
+
+CREATE TRIGGER IF NOT EXISTS tasks_ai AFTER INSERT ON tasks BEGIN
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (new.rowid, new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_ad AFTER DELETE ON tasks BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', old.rowid, old.title, old.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_au AFTER UPDATE OF title, description ON tasks BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', old.rowid, old.title, old.description);
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (new.rowid, new.title, new.description);
+END;
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+)
+
+type taskVector struct {
+	task  tasktracker.Task
+	model string
+	vec   []float32
+}
+
+// Search looks for tasks semantically similar to the query.
+// It degrades to full-text search when the embedder is unavailable.
+func (t *TaskTracker) Search(ctx context.Context, query string) ([]tasktracker.SearchResult, error) {
+	results, err := t.semanticSearch(ctx, query)
+	if errors.Is(err, tasktracker.ErrEmbedderUnavailable) {
+		slog.Warn("fallback to full-text search",
+			slog.String("error", err.Error()),
+		)
+
+		return t.fullTextSearch(ctx, query, tasktracker.SearchLimit)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Reindex re-embeds all tasks by the current model.
+// Vectors produced by another model are re-embedded on search automatically.
+func (t *TaskTracker) Reindex(ctx context.Context) error {
+	tvs, err := t.taskVectors(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tv := range tvs {
+		if _, err := t.reembed(ctx, tv.task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *TaskTracker) semanticSearch(ctx context.Context, query string) ([]tasktracker.SearchResult, error) {
+	q, err := tasktracker.EmbedText(ctx, t.embed, query)
+	if err != nil {
+		return nil, fmt.Errorf("get query embedding: %w", err)
+	}
+
+	tvs, err := t.taskVectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	model := tasktracker.EmbeddingModel(t.embed)
+
+	for i, tv := range tvs {
+		if tv.model == model && len(tv.vec) == len(q) {
+			continue
+		}
+
+		if tvs[i].vec, err = t.reembed(ctx, tv.task); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks := make([]tasktracker.Task, len(tvs))
+	vecs := make([][]float32, len(tvs))
+
+	for i, tv := range tvs {
+		tasks[i], vecs[i] = tv.task, tv.vec
+	}
+
+	results, err := tasktracker.RankByDistance(q, tasks, vecs, tasktracker.SearchScoreThreshold, tasktracker.SearchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("rank search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// fullTextSearch ranks tasks containing any of the query words by BM25.
+func (t *TaskTracker) fullTextSearch(ctx context.Context, query string, limit int) ([]tasktracker.SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := t.db.QueryContext(ctx, `
+		SELECT tasks.id, tasks.title, tasks.description, tasks.done, bm25(tasks_fts, 2.0, 1.0) AS rank
+		FROM tasks_fts JOIN tasks ON tasks.rowid = tasks_fts.rowid
+		WHERE tasks_fts MATCH ?
+		ORDER BY rank, tasks.id
+		LIMIT ?`,
+		match, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("full-text search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []tasktracker.SearchResult
+
+	var best float64
+
+	for rows.Next() {
+		var (
+			res  tasktracker.SearchResult
+			rank float64
+		)
+
+		if err := rows.Scan(&res.ID, &res.Title, &res.Description, &res.Done, &rank); err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+
+		// BM25 ranks are negative, the lower the better.
+		if best == 0 {
+			best = rank
+		}
+
+		res.Score = 1
+		if best != 0 {
+			res.Score = float32(rank / best)
+		}
+
+		results = append(results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tasks: %w", err)
+	}
+
+	return results, nil
+}
+
+func (t *TaskTracker) taskVectors(ctx context.Context) ([]taskVector, error) {
+	rows, err := t.db.QueryContext(ctx,
+		`SELECT id, title, description, done, embedding, embedding_model FROM tasks ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tvs []taskVector
+
+	for rows.Next() {
+		var (
+			tv   taskVector
+			blob []byte
+		)
+
+		if err := rows.Scan(&tv.task.ID, &tv.task.Title, &tv.task.Description, &tv.task.Done, &blob, &tv.model); err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+
+		tv.vec = decodeVector(blob)
+
+		tvs = append(tvs, tv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tasks: %w", err)
+	}
+
+	return tvs, nil
+}
+
+// reembed embeds the task by the current model and stores the vector.
+func (t *TaskTracker) reembed(ctx context.Context, task tasktracker.Task) ([]float32, error) {
+	vec, err := tasktracker.EmbedText(ctx, t.embed, tasktracker.EmbeddingText(task))
+	if err != nil {
+		return nil, fmt.Errorf("get embedding of task %q: %w", task.ID, err)
+	}
+
+	if _, err := t.db.ExecContext(ctx,
+		`UPDATE tasks SET content_hash = ?, embedding = ?, embedding_model = ? WHERE id = ?`,
+		contentHash(task), encodeVector(vec), tasktracker.EmbeddingModel(t.embed), task.ID,
+	); err != nil {
+		return nil, fmt.Errorf("update embedding of task %q: %w", task.ID, err)
+	}
+
+	return vec, nil
+}
+
+// ftsQuery converts the free text query to the FTS5 query matching any of its words.
+func ftsQuery(query string) string {
+	words := tasktracker.Tokenize(query)
+
+	slices.Sort(words)
+	words = slices.Compact(words)
+
+	for i, w := range words {
+		words[i] = `"` + w + `"`
+	}
+
+	return strings.Join(words, " OR ")
+}
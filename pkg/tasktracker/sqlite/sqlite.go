@@ -0,0 +1,366 @@
+// Package sqlite implements [tasktracker.Tracker] on an embedded SQLite database.
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	_ "embed"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+
+	// Pure Go SQLite driver.
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var schema string
+
+type TaskTracker struct {
+	db    *sql.DB
+	embed llm.Embedder
+}
+
+// NewTaskTracker opens the database file and creates the schema if needed.
+func NewTaskTracker(ctx context.Context, filename string, embed llm.Embedder) (*TaskTracker, error) {
+	dsn := "file:" + filename + "?" + url.Values{
+		"_pragma": []string{"foreign_keys(1)", "busy_timeout(10000)", "journal_mode(WAL)"},
+		"_txlock": []string{"immediate"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database %q: %w", filename, err)
+	}
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+
+	return &TaskTracker{
+		db:    db,
+		embed: embed,
+	}, nil
+}
+
+// Close closes the database.
+func (t *TaskTracker) Close() error {
+	if err := t.db.Close(); err != nil {
+		return fmt.Errorf("close database: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TaskTracker) Set(ctx context.Context, id string, task tasktracker.Task) error {
+	if id == "" {
+		return errors.New("empty task id")
+	}
+
+	if err := task.Validate(); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+
+	task.ID = id
+
+	hash := contentHash(task)
+	model := tasktracker.EmbeddingModel(t.embed)
+
+	vec, err := t.storedVector(ctx, id, hash, model)
+	if err != nil {
+		return err
+	}
+
+	if vec == nil && t.embed != nil {
+		// The task is stored even if the embedder is unavailable,
+		// the missing vector is computed on the next search.
+		if vec, err = tasktracker.EmbedText(ctx, t.embed, tasktracker.EmbeddingText(task)); err != nil {
+			if !errors.Is(err, tasktracker.ErrEmbedderUnavailable) {
+				return fmt.Errorf("get task embedding: %w", err)
+			}
+
+			slog.Warn("embed task",
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if _, err := t.db.ExecContext(ctx, `
+		INSERT INTO tasks (id, title, description, done, content_hash, embedding, embedding_model)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			done = excluded.done,
+			content_hash = excluded.content_hash,
+			embedding = excluded.embedding,
+			embedding_model = excluded.embedding_model`,
+		id, task.Title, task.Description, task.Done, hash, encodeVector(vec), model,
+	); err != nil {
+		return fmt.Errorf("upsert task: %w", err)
+	}
+
+	return nil
+}
+
+// storedVector returns the stored vector of the task if it was produced from the same content by the same model.
+func (t *TaskTracker) storedVector(ctx context.Context, id, hash, model string) ([]float32, error) {
+	var blob []byte
+
+	err := t.db.QueryRowContext(ctx,
+		`SELECT embedding FROM tasks WHERE id = ? AND content_hash = ? AND embedding_model = ?`,
+		id, hash, model,
+	).Scan(&blob)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("select task embedding: %w", err)
+	}
+
+	return decodeVector(blob), nil
+}
+
+func (t *TaskTracker) Get(ctx context.Context, id string) (task tasktracker.Task, err error) {
+	if id == "" {
+		return task, errors.New("empty task id")
+	}
+
+	err = t.db.QueryRowContext(ctx,
+		`SELECT id, title, description, done FROM tasks WHERE id = ?`, id,
+	).Scan(&task.ID, &task.Title, &task.Description, &task.Done)
+	if errors.Is(err, sql.ErrNoRows) {
+		return task, fmt.Errorf("get task: %w", tasktracker.ErrNotFound)
+	} else if err != nil {
+		return task, fmt.Errorf("get task: %w", err)
+	}
+
+	return task, nil
+}
+
+func (t *TaskTracker) Del(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("empty id")
+	}
+
+	if _, err := t.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TaskTracker) List(ctx context.Context, done *bool) ([]tasktracker.Task, error) {
+	rows, err := t.db.QueryContext(ctx,
+		`SELECT id, title, description, done FROM tasks WHERE ? IS NULL OR done = ? ORDER BY id`,
+		done, done,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []tasktracker.Task
+
+	for rows.Next() {
+		var task tasktracker.Task
+
+		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Done); err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tasks: %w", err)
+	}
+
+	return slices.Clip(tasks), nil
+}
+
+func (t *TaskTracker) AddComment(ctx context.Context, id string, comment tasktracker.Comment) error {
+	author, err := comment.Author.ToString()
+	if err != nil {
+		return fmt.Errorf("author to string: %w", err)
+	}
+
+	if _, err := t.Get(ctx, id); err != nil {
+		return err
+	}
+
+	if _, err := t.db.ExecContext(ctx,
+		`INSERT INTO comments (task_id, author, created_at, body, attachment) VALUES (?, ?, ?, ?, ?)`,
+		id, author, comment.CreatedAt.Format(time.RFC3339Nano), comment.Body, comment.Attachment,
+	); err != nil {
+		return fmt.Errorf("insert comment: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TaskTracker) ListComments(ctx context.Context, id string) ([]tasktracker.Comment, error) {
+	if _, err := t.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := t.db.QueryContext(ctx,
+		`SELECT author, created_at, body, attachment FROM comments WHERE task_id = ? ORDER BY id`, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []tasktracker.Comment
+
+	for rows.Next() {
+		var author, createdAt string
+
+		var c tasktracker.Comment
+
+		if err := rows.Scan(&author, &createdAt, &c.Body, &c.Attachment); err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+
+		if c.Author, err = tasktracker.AuthorFromString(author); err != nil {
+			return nil, fmt.Errorf("parse comment author: %w", err)
+		}
+
+		if c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parse comment time: %w", err)
+		}
+
+		comments = append(comments, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+var errSubtaskCycle = errors.New("subtask relation makes a cycle")
+
+func (t *TaskTracker) AddSubtask(ctx context.Context, parentID, childID string) error {
+	if _, err := t.Get(ctx, parentID); err != nil {
+		return fmt.Errorf("parent: %w", err)
+	}
+
+	if _, err := t.Get(ctx, childID); err != nil {
+		return fmt.Errorf("child: %w", err)
+	}
+
+	// The child must not be the parent itself or one of its ancestors.
+	var cycle bool
+
+	if err := t.db.QueryRowContext(ctx, `
+		WITH RECURSIVE ancestors (id) AS (
+			SELECT ?
+			UNION
+			SELECT relations.parent_id FROM relations JOIN ancestors ON relations.child_id = ancestors.id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = ?)`,
+		parentID, childID,
+	).Scan(&cycle); err != nil {
+		return fmt.Errorf("select task ancestors: %w", err)
+	}
+
+	if cycle {
+		return fmt.Errorf("%q to %q: %w", childID, parentID, errSubtaskCycle)
+	}
+
+	if _, err := t.db.ExecContext(ctx, `
+		INSERT INTO relations (child_id, parent_id) VALUES (?, ?)
+		ON CONFLICT (child_id) DO UPDATE SET parent_id = excluded.parent_id`,
+		childID, parentID,
+	); err != nil {
+		return fmt.Errorf("upsert relation: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TaskTracker) ListSubtasks(ctx context.Context, parentID string) ([]tasktracker.Task, error) {
+	if _, err := t.Get(ctx, parentID); err != nil {
+		return nil, err
+	}
+
+	rows, err := t.db.QueryContext(ctx, `
+		SELECT tasks.id, tasks.title, tasks.description, tasks.done
+		FROM tasks JOIN relations ON relations.child_id = tasks.id
+		WHERE relations.parent_id = ?
+		ORDER BY tasks.id`, parentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select subtasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []tasktracker.Task
+
+	for rows.Next() {
+		var task tasktracker.Task
+
+		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Done); err != nil {
+			return nil, fmt.Errorf("scan subtask: %w", err)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate subtasks: %w", err)
+	}
+
+	return slices.Clip(tasks), nil
+}
+
+// contentHash returns the hash of the embedded text of the task.
+func contentHash(task tasktracker.Task) string {
+	sum := sha256.Sum256([]byte(tasktracker.EmbeddingText(task)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeVector encodes the vector as a little-endian float32 array.
+func encodeVector(vec []float32) []byte {
+	if vec == nil {
+		return nil
+	}
+
+	b := make([]byte, 0, len(vec)*4) //nolint:mnd // float32 size.
+
+	for _, v := range vec {
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+	}
+
+	return b
+}
+
+func decodeVector(b []byte) []float32 {
+	if b == nil {
+		return nil
+	}
+
+	vec := make([]float32, len(b)/4) //nolint:mnd // float32 size.
+
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+
+	return vec
+}
@@ -0,0 +1,137 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/sqlite"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker/trackertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskTracker(t *testing.T) {
+	t.Parallel()
+
+	trackertest.Run(t, func(t *testing.T) trackertest.Factory {
+		t.Helper()
+
+		filename := filepath.Join(t.TempDir(), "tasks.db")
+
+		return func(embed llm.Embedder) tasktracker.Tracker {
+			tracker, err := sqlite.NewTaskTracker(context.Background(), filename, embed)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { tracker.Close() })
+
+			return tracker
+		}
+	})
+}
+
+func TestTaskTracker_Subtasks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newTracker := func(t *testing.T) *sqlite.TaskTracker {
+		t.Helper()
+
+		tracker, err := sqlite.NewTaskTracker(ctx, filepath.Join(t.TempDir(), "tasks.db"), nil)
+		require.NoError(t, err)
+
+		t.Cleanup(func() { tracker.Close() })
+
+		return tracker
+	}
+
+	tracker := newTracker(t)
+
+	for _, id := range []string{"1", "1.1", "1.2", "2"} {
+		require.NoError(t, tracker.Set(ctx, id, tasktracker.Task{ID: id, Title: "Task " + id, Description: "Do " + id}))
+	}
+
+	require.NoError(t, tracker.AddSubtask(ctx, "1", "1.2"))
+	require.NoError(t, tracker.AddSubtask(ctx, "1", "1.1"))
+	require.NoError(t, tracker.AddSubtask(ctx, "2", "1"))
+
+	subtasks, err := tracker.ListSubtasks(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.1", "1.2"}, taskIDs(subtasks))
+
+	t.Run("cycle", func(t *testing.T) {
+		t.Parallel()
+
+		require.Error(t, tracker.AddSubtask(ctx, "1.1", "1.1"))
+		require.Error(t, tracker.AddSubtask(ctx, "1.1", "2"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, tracker.AddSubtask(ctx, "1", "3"), tasktracker.ErrNotFound)
+
+		_, err := tracker.ListSubtasks(ctx, "3")
+		require.ErrorIs(t, err, tasktracker.ErrNotFound)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+
+		dst := newTracker(t)
+
+		n, err := tasktracker.Copy(ctx, dst, tracker)
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+
+		subtasks, err := dst.ListSubtasks(ctx, "2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, taskIDs(subtasks))
+	})
+}
+
+func TestTaskTracker_SubtasksMoveAndDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tracker, err := sqlite.NewTaskTracker(ctx, filepath.Join(t.TempDir(), "tasks.db"), nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { tracker.Close() })
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, tracker.Set(ctx, id, tasktracker.Task{ID: id, Title: "Task " + id, Description: "Do " + id}))
+	}
+
+	require.NoError(t, tracker.AddSubtask(ctx, "1", "3"))
+	require.NoError(t, tracker.AddSubtask(ctx, "2", "3"))
+
+	subtasks, err := tracker.ListSubtasks(ctx, "1")
+	require.NoError(t, err)
+	assert.Empty(t, subtasks, "the subtask is moved to another parent")
+
+	require.NoError(t, tracker.Set(ctx, "3", tasktracker.Task{ID: "3", Title: "Task 3", Description: "Updated", Done: true}))
+
+	subtasks, err = tracker.ListSubtasks(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, taskIDs(subtasks), "updates keep the relation")
+
+	require.NoError(t, tracker.Del(ctx, "3"))
+
+	subtasks, err = tracker.ListSubtasks(ctx, "2")
+	require.NoError(t, err)
+	assert.Empty(t, subtasks, "deleting the subtask deletes the relation")
+}
+
+func taskIDs(tasks []tasktracker.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	return ids
+}
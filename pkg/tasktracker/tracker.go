@@ -3,6 +3,9 @@ package tasktracker
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
 var ErrNotFound = errors.New("task not found")
@@ -13,6 +16,20 @@ type Tracker interface {
 	Del(ctx context.Context, id string) error
 	List(ctx context.Context, done *bool) ([]Task, error)
 	Search(ctx context.Context, query string) ([]SearchResult, error)
+
+	// AddComment appends the comment to the task with the given id.
+	AddComment(ctx context.Context, id string, comment Comment) error
+	// ListComments returns comments of the task in chronological order.
+	ListComments(ctx context.Context, id string) ([]Comment, error)
+}
+
+// SubtaskTracker is implemented by trackers storing parent/child relations of tasks.
+type SubtaskTracker interface {
+	// AddSubtask makes the task with the child id a subtask of the task with the parent id.
+	// A task has at most one parent, adding it to another parent moves it.
+	AddSubtask(ctx context.Context, parentID, childID string) error
+	// ListSubtasks returns subtasks of the task ordered by ID.
+	ListSubtasks(ctx context.Context, parentID string) ([]Task, error)
 }
 
 type Task struct {
@@ -22,7 +39,91 @@ type Task struct {
 	Done        bool
 }
 
+// Validate reports an error if the task misses required fields.
+func (task Task) Validate() error {
+	var errs []error
+
+	if len(task.Title) == 0 {
+		errs = append(errs, errors.New("empty task title"))
+	}
+
+	if len(task.Description) == 0 {
+		errs = append(errs, errors.New("empty task description"))
+	}
+
+	return errors.Join(errs...)
+}
+
 type SearchResult struct {
 	Task
 	Score float32
 }
+
+// Comment is a note attached to a task: progress notes and test output left by agents,
+// clarification questions and answers to them.
+type Comment struct {
+	Author    Author
+	CreatedAt time.Time
+	Body      string
+	// Attachment is an optional path to a file related to the comment.
+	Attachment string
+}
+
+// Author is a role of the comment author.
+type Author int
+
+const (
+	AuthorHuman Author = iota
+	AuthorArchitector
+	AuthorCoder
+	AuthorDebugger
+	AuthorFixer
+	AuthorTester
+)
+
+func (a Author) String() string {
+	s, err := a.ToString()
+	if err != nil {
+		return "unknown"
+	}
+
+	return s
+}
+
+func (a Author) ToString() (string, error) {
+	switch a {
+	case AuthorHuman:
+		return "human", nil
+	case AuthorArchitector:
+		return "architector", nil
+	case AuthorCoder:
+		return "coder", nil
+	case AuthorDebugger:
+		return "debugger", nil
+	case AuthorFixer:
+		return "fixer", nil
+	case AuthorTester:
+		return "tester", nil
+	default:
+		return "", fmt.Errorf("unknown author %d", a)
+	}
+}
+
+func AuthorFromString(s string) (Author, error) {
+	switch strings.ToLower(s) {
+	case "human":
+		return AuthorHuman, nil
+	case "architector":
+		return AuthorArchitector, nil
+	case "coder":
+		return AuthorCoder, nil
+	case "debugger":
+		return AuthorDebugger, nil
+	case "fixer":
+		return AuthorFixer, nil
+	case "tester":
+		return AuthorTester, nil
+	default:
+		return 0, fmt.Errorf("unknown author %q", s)
+	}
+}
@@ -0,0 +1,466 @@
+// Package trackertest implements the conformance test suite for [tasktracker.Tracker] implementations.
+package trackertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WinPooh32/go-coder/pkg/llm"
+	"github.com/WinPooh32/go-coder/pkg/llm/llmtest"
+	"github.com/WinPooh32/go-coder/pkg/tasktracker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const embedDimension = 64
+
+// Factory opens a tracker using the given embedder.
+// Trackers opened by the same factory must share the storage.
+type Factory func(embed llm.Embedder) tasktracker.Tracker
+
+// Run runs the conformance suite. newFactory is called for every test case
+// and must return a factory bound to a new empty storage.
+func Run(t *testing.T, newFactory func(t *testing.T) Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		test func(t *testing.T, f Factory)
+	}{
+		{"SetGet", testSetGet},
+		{"SetOverwrites", testSetOverwrites},
+		{"GetNotFound", testGetNotFound},
+		{"Del", testDel},
+		{"List", testList},
+		{"DoneToggle", testDoneToggle},
+		{"EmptyID", testEmptyID},
+		{"InvalidTask", testInvalidTask},
+		{"Comments", testComments},
+		{"CommentsNotFound", testCommentsNotFound},
+		{"SearchOrder", testSearchOrder},
+		{"SearchWithoutEmbedder", testSearchWithoutEmbedder},
+		{"SearchAfterModelChange", testSearchAfterModelChange},
+		{"SetDoneDoesNotReembed", testSetDoneDoesNotReembed},
+		{"ConcurrentWriters", testConcurrentWriters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.test(t, newFactory(t))
+		})
+	}
+}
+
+func sampleTasks() []tasktracker.Task {
+	return []tasktracker.Task{
+		{ID: "apple", Title: "Apple pie", Description: "Bake an apple pie with cinnamon.", Done: false},
+		{ID: "car", Title: "Car engine", Description: "Repair the engine of the car.", Done: false},
+		{ID: "garden", Title: "Garden", Description: "Water the plants in the garden.", Done: true},
+	}
+}
+
+func setTasks(ctx context.Context, t *testing.T, tracker tasktracker.Tracker, tasks []tasktracker.Task) {
+	t.Helper()
+
+	for _, task := range tasks {
+		require.NoError(t, tracker.Set(ctx, task.ID, task), "set task %q", task.ID)
+	}
+}
+
+func sortByID(tasks []tasktracker.Task) []tasktracker.Task {
+	slices.SortFunc(tasks, func(a, b tasktracker.Task) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return tasks
+}
+
+func testSetGet(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	tasks := sampleTasks()
+	setTasks(ctx, t, tracker, tasks)
+
+	for _, want := range tasks {
+		got, err := tracker.Get(ctx, want.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func testSetOverwrites(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	setTasks(ctx, t, tracker, sampleTasks())
+
+	want := tasktracker.Task{ID: "apple", Title: "Apple tart", Description: "Bake an apple tart.", Done: false}
+	require.NoError(t, tracker.Set(ctx, want.ID, want))
+
+	got, err := tracker.Get(ctx, want.ID)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	all, err := tracker.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, len(sampleTasks()), "overwrite must not duplicate the task")
+}
+
+func testGetNotFound(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	_, err := tracker.Get(ctx, "missing")
+	require.ErrorIs(t, err, tasktracker.ErrNotFound)
+
+	setTasks(ctx, t, tracker, sampleTasks())
+
+	_, err = tracker.Get(ctx, "missing")
+	require.ErrorIs(t, err, tasktracker.ErrNotFound)
+}
+
+func testDel(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	setTasks(ctx, t, tracker, sampleTasks())
+
+	for _, id := range []string{"apple", "garden"} {
+		require.NoError(t, tracker.Del(ctx, id))
+
+		_, err := tracker.Get(ctx, id)
+		require.ErrorIs(t, err, tasktracker.ErrNotFound, "task %q must be deleted", id)
+	}
+
+	require.NoError(t, tracker.Del(ctx, "missing"), "deletion of missing task is not an error")
+
+	all, err := tracker.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []tasktracker.Task{sampleTasks()[1]}, all)
+}
+
+func testList(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	all, err := tracker.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	tasks := sampleTasks()
+	setTasks(ctx, t, tracker, tasks)
+
+	all, err = tracker.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sortByID(tasks), sortByID(all))
+
+	done := true
+
+	doneTasks, err := tracker.List(ctx, &done)
+	require.NoError(t, err)
+	assert.Equal(t, []tasktracker.Task{tasks[2]}, doneTasks)
+
+	done = false
+
+	activeTasks, err := tracker.List(ctx, &done)
+	require.NoError(t, err)
+	assert.Equal(t, sortByID(tasks[:2]), sortByID(activeTasks))
+}
+
+func testDoneToggle(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	task := sampleTasks()[0]
+	require.NoError(t, tracker.Set(ctx, task.ID, task))
+
+	comment := tasktracker.Comment{
+		Author:     tasktracker.AuthorCoder,
+		CreatedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Body:       "Work in progress.",
+		Attachment: "",
+	}
+	require.NoError(t, tracker.AddComment(ctx, task.ID, comment))
+
+	for _, done := range []bool{true, false, true} {
+		task.Done = done
+		require.NoError(t, tracker.Set(ctx, task.ID, task))
+
+		got, err := tracker.Get(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, task, got)
+
+		listed, err := tracker.List(ctx, &done)
+		require.NoError(t, err)
+		assert.Equal(t, []tasktracker.Task{task}, listed)
+
+		notDone := !done
+
+		listed, err = tracker.List(ctx, &notDone)
+		require.NoError(t, err)
+		assert.Empty(t, listed, "task must be listed only once")
+
+		comments, err := tracker.ListComments(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Len(t, comments, 1, "comments must be kept")
+	}
+}
+
+func testEmptyID(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	task := sampleTasks()[0]
+
+	require.Error(t, tracker.Set(ctx, "", task))
+
+	_, err := tracker.Get(ctx, "")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, tasktracker.ErrNotFound)
+
+	require.Error(t, tracker.Del(ctx, ""))
+}
+
+func testInvalidTask(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	invalid := []tasktracker.Task{
+		{ID: "no-title", Title: "", Description: "Description.", Done: false},
+		{ID: "no-description", Title: "Title", Description: "", Done: false},
+	}
+
+	for _, task := range invalid {
+		require.Error(t, tracker.Set(ctx, task.ID, task), "task %q is invalid", task.ID)
+
+		_, err := tracker.Get(ctx, task.ID)
+		require.ErrorIs(t, err, tasktracker.ErrNotFound, "invalid task %q must not be stored", task.ID)
+	}
+}
+
+func testComments(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	setTasks(ctx, t, tracker, sampleTasks())
+
+	comments, err := tracker.ListComments(ctx, "apple")
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+
+	want := []tasktracker.Comment{
+		{
+			Author:     tasktracker.AuthorArchitector,
+			CreatedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Body:       "Which kind of apples?",
+			Attachment: "",
+		},
+		{
+			Author:     tasktracker.AuthorHuman,
+			CreatedAt:  time.Date(2025, 1, 2, 4, 0, 0, 123, time.UTC),
+			Body:       "Green ones.\nSour.",
+			Attachment: "",
+		},
+		{
+			Author:     tasktracker.AuthorTester,
+			CreatedAt:  time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+			Body:       "Tests passed.",
+			Attachment: "test_output.txt",
+		},
+	}
+
+	for _, c := range want {
+		require.NoError(t, tracker.AddComment(ctx, "apple", c))
+	}
+
+	got, err := tracker.ListComments(ctx, "apple")
+	require.NoError(t, err)
+	require.Len(t, got, len(want))
+
+	for i := range want {
+		assert.Equal(t, want[i].Author, got[i].Author)
+		assert.True(t, want[i].CreatedAt.Equal(got[i].CreatedAt), "got time %s, want %s", got[i].CreatedAt, want[i].CreatedAt)
+		assert.Equal(t, want[i].Body, got[i].Body)
+		assert.Equal(t, want[i].Attachment, got[i].Attachment)
+	}
+
+	other, err := tracker.ListComments(ctx, "car")
+	require.NoError(t, err)
+	assert.Empty(t, other, "comments belong to a single task")
+
+	require.NoError(t, tracker.Del(ctx, "apple"))
+	require.NoError(t, tracker.Set(ctx, "apple", sampleTasks()[0]))
+
+	got, err = tracker.ListComments(ctx, "apple")
+	require.NoError(t, err)
+	assert.Empty(t, got, "comments must be deleted with the task")
+}
+
+func testCommentsNotFound(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	err := tracker.AddComment(ctx, "missing", tasktracker.Comment{
+		Author:     tasktracker.AuthorHuman,
+		CreatedAt:  time.Now(),
+		Body:       "Hello.",
+		Attachment: "",
+	})
+	require.ErrorIs(t, err, tasktracker.ErrNotFound)
+
+	_, err = tracker.ListComments(ctx, "missing")
+	require.ErrorIs(t, err, tasktracker.ErrNotFound)
+}
+
+func testSearchOrder(t *testing.T, f Factory) {
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	setTasks(ctx, t, tracker, sampleTasks())
+
+	results, err := tracker.Search(ctx, "apple pie with cinnamon")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "apple", results[0].ID)
+	assertScoresDesc(t, results)
+
+	results, err = tracker.Search(ctx, "repair the car engine")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "car", results[0].ID)
+	assertScoresDesc(t, results)
+}
+
+func testSearchWithoutEmbedder(t *testing.T, f Factory) {
+	ctx := context.Background()
+
+	embedder := llmtest.NewEmbedder("fake", embedDimension)
+	embedder.Err = errors.New("embedder is down")
+
+	tracker := f(embedder)
+
+	setTasks(ctx, t, tracker, sampleTasks())
+
+	results, err := tracker.Search(ctx, "garden plants")
+	require.NoError(t, err, "search must degrade to keyword search")
+	require.NotEmpty(t, results)
+	assert.Equal(t, "garden", results[0].ID)
+	assertScoresDesc(t, results)
+}
+
+func testSearchAfterModelChange(t *testing.T, f Factory) {
+	ctx := context.Background()
+
+	setTasks(ctx, t, f(llmtest.NewEmbedder("old", embedDimension)), sampleTasks())
+
+	tracker := f(llmtest.NewEmbedder("new", embedDimension*2))
+
+	results, err := tracker.Search(ctx, "repair the car engine")
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "car", results[0].ID)
+}
+
+func testSetDoneDoesNotReembed(t *testing.T, f Factory) {
+	ctx := context.Background()
+
+	embedder := llmtest.NewEmbedder("fake", embedDimension)
+	tracker := f(embedder)
+
+	task := sampleTasks()[0]
+	require.NoError(t, tracker.Set(ctx, task.ID, task))
+
+	calls := embedder.Calls()
+
+	task.Done = true
+	require.NoError(t, tracker.Set(ctx, task.ID, task))
+	assert.Equal(t, calls, embedder.Calls(), "unchanged content must not be embedded again")
+}
+
+func testConcurrentWriters(t *testing.T, f Factory) {
+	const (
+		writers        = 4
+		tasksPerWriter = 5
+	)
+
+	ctx := context.Background()
+	tracker := f(llmtest.NewEmbedder("fake", embedDimension))
+
+	shared := sampleTasks()[0]
+	require.NoError(t, tracker.Set(ctx, shared.ID, shared))
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, writers*tasksPerWriter*2)
+
+	for w := range writers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range tasksPerWriter {
+				id := fmt.Sprintf("task-%d-%d", w, i)
+
+				errs <- tracker.Set(ctx, id, tasktracker.Task{
+					ID:          id,
+					Title:       fmt.Sprintf("Task %d of writer %d", i, w),
+					Description: "Concurrently written task.",
+					Done:        i%2 == 0,
+				})
+
+				errs <- tracker.AddComment(ctx, shared.ID, tasktracker.Comment{
+					Author:     tasktracker.AuthorCoder,
+					CreatedAt:  time.Now(),
+					Body:       id,
+					Attachment: "",
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	all, err := tracker.List(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, all, writers*tasksPerWriter+1)
+
+	comments, err := tracker.ListComments(ctx, shared.ID)
+	require.NoError(t, err)
+	assert.Len(t, comments, writers*tasksPerWriter, "no comment must be lost")
+}
+
+func assertScoresDesc(t *testing.T, results []tasktracker.SearchResult) {
+	t.Helper()
+
+	assert.True(t, slices.IsSortedFunc(results, func(a, b tasktracker.SearchResult) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	}), "results must be sorted by score in DESC order")
+}